@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"ogp-verification-service/internal/handlers"
 )
 
+// shutdownTimeout bounds how long in-flight requests and background jobs
+// may run after SIGINT or SIGTERM.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -16,8 +26,71 @@ func main() {
 	}
 
 	ogpHandler := handlers.NewOGPHandler()
+	if fontPath := os.Getenv("CARD_FALLBACK_FONT"); fontPath != "" {
+		if err := ogpHandler.LoadCardFont(fontPath); err != nil {
+			log.Printf("Card fallback font not loaded: %v", err)
+		}
+	}
+	if configPath := os.Getenv("RULES_CONFIG"); configPath != "" {
+		if err := ogpHandler.LoadRuleConfig(configPath); err != nil {
+			log.Fatalf("Rule config not loaded: %v", err)
+		}
+	}
+
+	historyPath := os.Getenv("HISTORY_DB")
+	if historyPath == "" {
+		historyPath = "history.db"
+	}
+	retentionDays := 30
+	if days := os.Getenv("HISTORY_RETENTION_DAYS"); days != "" {
+		var err error
+		if retentionDays, err = strconv.Atoi(days); err != nil || retentionDays < 0 {
+			log.Fatalf("HISTORY_RETENTION_DAYS must be a number of days, got %q", days)
+		}
+	}
+	if err := ogpHandler.OpenHistory(historyPath, time.Duration(retentionDays)*24*time.Hour); err != nil {
+		log.Fatalf("History database not opened: %v", err)
+	}
+
+	monitorsPath := os.Getenv("MONITORS_DB")
+	if monitorsPath == "" {
+		monitorsPath = "monitors.db"
+	}
+	if err := ogpHandler.OpenMonitors(monitorsPath); err != nil {
+		log.Fatalf("Monitor database not opened: %v", err)
+	}
+
+	reportsPath := os.Getenv("REPORTS_DB")
+	if reportsPath == "" {
+		reportsPath = "reports.db"
+	}
+	expiryDays := 30
+	if days := os.Getenv("REPORT_EXPIRY_DAYS"); days != "" {
+		var err error
+		if expiryDays, err = strconv.Atoi(days); err != nil || expiryDays < 0 {
+			log.Fatalf("REPORT_EXPIRY_DAYS must be a number of days, got %q", days)
+		}
+	}
+	if err := ogpHandler.OpenReports(reportsPath, time.Duration(expiryDays)*24*time.Hour); err != nil {
+		log.Fatalf("Report database not opened: %v", err)
+	}
 
 	http.HandleFunc("/api/v1/ogp/verify", ogpHandler.VerifyOGP)
+	http.HandleFunc("/api/v1/ogp/verify/stream", ogpHandler.VerifyOGPStream)
+	http.HandleFunc("/api/v1/ogp/verify/batch", ogpHandler.VerifyBatch)
+	http.HandleFunc("/api/v1/ogp/verify-html", ogpHandler.VerifyHTML)
+	http.HandleFunc("/api/v1/ogp/crawl", ogpHandler.Crawl)
+	http.HandleFunc("/api/v1/ogp/diff", ogpHandler.Diff)
+	http.HandleFunc("/api/v1/ogp/render", ogpHandler.RenderCard)
+	http.HandleFunc("/api/v1/jobs", ogpHandler.SubmitJob)
+	http.HandleFunc("/api/v1/jobs/", ogpHandler.Job)
+	http.HandleFunc("/api/v1/webhooks", ogpHandler.Webhooks)
+	http.HandleFunc("/api/v1/webhooks/", ogpHandler.Webhook)
+	http.HandleFunc("/api/v1/monitors", ogpHandler.Monitors)
+	http.HandleFunc("/api/v1/monitors/", ogpHandler.Monitor)
+	http.HandleFunc("/api/v1/history", ogpHandler.History)
+	http.HandleFunc("/api/v1/history/", ogpHandler.HistoryEntry)
+	http.HandleFunc("/r/", ogpHandler.Report)
 	
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -43,8 +116,25 @@ func main() {
 		fmt.Fprintf(w, `{"message": "OGP Verification Service", "version": "1.0"}`)
 	})
 
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal("Server failed to start:", err)
+	server := &http.Server{Addr: ":" + port}
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed to start:", err)
+		}
+	}()
+
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	<-stop.Done()
+
+	log.Printf("Shutting down, draining jobs")
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelTimeout()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
+	}
+	if err := ogpHandler.Shutdown(ctx); err != nil {
+		log.Printf("Jobs cancelled at shutdown: %v", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+// privateURLs fail before any request is sent, so batches of them exercise
+// the handler without network access.
+func privateURLs(n int) []string {
+	urls := make([]string, n)
+	for i := range urls {
+		urls[i] = "http://192.168.1.1/"
+	}
+	return urls
+}
+
+func postBatch(handler *handlers.OGPHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/verify/batch", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "127.0.0.1:12345"
+
+	rr := httptest.NewRecorder()
+	handler.VerifyBatch(rr, req)
+	return rr
+}
+
+func TestOGPHandlerVerifyBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"private URLs", mustJSON(t, models.BatchRequest{URLs: privateURLs(3)}), http.StatusOK, ""},
+		{"no URLs", mustJSON(t, models.BatchRequest{}), http.StatusBadRequest, "URLs are required\n"},
+		{"too many URLs", mustJSON(t, models.BatchRequest{URLs: privateURLs(101)}), http.StatusBadRequest, "At most 100 URLs are allowed per batch\n"},
+		{"invalid options", mustJSON(t, models.BatchRequest{URLs: privateURLs(1), VerifyOptions: models.VerifyOptions{MinScore: 101}}), http.StatusBadRequest, "min_score must be between 0 and 100\n"},
+		{"invalid JSON", "invalid json", http.StatusBadRequest, "Invalid JSON\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postBatch(handlers.NewOGPHandler(), tt.body)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if rr.Body.String() != tt.expectedBody {
+					t.Errorf("Expected %q, got %q", tt.expectedBody, rr.Body.String())
+				}
+				return
+			}
+
+			var resp models.BatchResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if len(resp.Results) != 3 || resp.Summary.Total != 3 || resp.Summary.Failed != 3 {
+				t.Errorf("Expected three failed results, got %+v", resp.Summary)
+			}
+			if resp.Results[0].Error != "private IP addresses are not allowed" {
+				t.Errorf("Expected per-URL error, got %q", resp.Results[0].Error)
+			}
+		})
+	}
+}
+
+func TestOGPHandlerVerifyBatchRateLimit(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	body := mustJSON(t, models.BatchRequest{URLs: privateURLs(80)})
+
+	// Each URL counts, so two batches of 80 fit in the 200 URLs a minute
+	for i := 0; i < 2; i++ {
+		if rr := postBatch(handler, body); rr.Code != http.StatusOK {
+			t.Fatalf("Batch %d: expected status %d, got %d", i+1, http.StatusOK, rr.Code)
+		}
+	}
+	if rr := postBatch(handler, body); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d for the third batch, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	// The remaining budget still admits a small batch
+	if rr := postBatch(handler, mustJSON(t, models.BatchRequest{URLs: privateURLs(40)})); rr.Code != http.StatusOK {
+		t.Errorf("Expected a 40 URL batch to fit the remaining budget, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func postCrawl(handler *handlers.OGPHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/crawl", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "127.0.0.1:12345"
+
+	rr := httptest.NewRecorder()
+	handler.Crawl(rr, req)
+	return rr
+}
+
+func TestOGPHandlerCrawl(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"private start URL", mustJSON(t, models.CrawlRequest{StartURL: "http://192.168.1.1/"}), http.StatusOK, ""},
+		{"no source", mustJSON(t, models.CrawlRequest{}), http.StatusBadRequest, "exactly one of sitemap_url and start_url is required\n"},
+		{"too many pages", mustJSON(t, models.CrawlRequest{StartURL: "https://example.com/", MaxPages: 201}), http.StatusBadRequest, "max_pages must be between 1 and 200\n"},
+		{"private sitemap", mustJSON(t, models.CrawlRequest{SitemapURL: "http://192.168.1.1/sitemap.xml"}), http.StatusInternalServerError, "Error crawling site: private IP addresses are not allowed\n"},
+		{"invalid JSON", "invalid json", http.StatusBadRequest, "Invalid JSON\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postCrawl(handlers.NewOGPHandler(), tt.body)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if rr.Body.String() != tt.expectedBody {
+					t.Errorf("Expected %q, got %q", tt.expectedBody, rr.Body.String())
+				}
+				return
+			}
+
+			var report models.CrawlReport
+			if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if report.Source != "links" || len(report.Pages) != 1 || report.Pages[0].Error == "" {
+				t.Errorf("Expected one failed page, got %+v", report.Pages)
+			}
+		})
+	}
+}
+
+func TestOGPHandlerCrawlRateLimit(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	body := mustJSON(t, models.CrawlRequest{StartURL: "http://192.168.1.1/", MaxPages: 200})
+
+	// A 200 page budget uses all 200 URLs a minute
+	if rr := postCrawl(handler, body); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if rr := postCrawl(handler, body); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d for the second crawl, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	// Batches draw on the same URL budget
+	if rr := postBatch(handler, mustJSON(t, models.BatchRequest{URLs: privateURLs(1)})); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d for a batch after the crawl, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/storage"
+)
+
+func TestOGPHandlerDiff(t *testing.T) {
+	// Seed the history database the handler then opens
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := storage.OpenSQLiteHistoryStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, title := range []string{"Before deploy", "After deploy"} {
+		response := &models.OGPResponse{URL: "https://example.com/", OGPData: models.OGPData{Title: title}, Timestamp: time.Now()}
+		if _, err := store.Save(models.HistoryEntry{URL: response.URL, CreatedAt: response.Timestamp, Response: response}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	store.Close()
+
+	handler := handlers.NewOGPHandler()
+	if err := handler.OpenHistory(path, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{"json", "", `{"before": {"history_id": 1}, "after": {"history_id": 2}}`, http.StatusOK, "application/json", `"field":"ogp_data.title","before":"Before deploy","after":"After deploy"`},
+		{"text", "?format=text", `{"before": {"history_id": 1}, "after": {"history_id": 2}}`, http.StatusOK, "text/plain; charset=utf-8", `ogp_data.title: "Before deploy" -> "After deploy"`},
+		{"unknown entry", "", `{"before": {"history_id": 1}, "after": {"history_id": 9}}`, http.StatusNotFound, "text/plain; charset=utf-8", "History entry 9 not found"},
+		{"missing target", "", `{"before": {"history_id": 1}}`, http.StatusBadRequest, "text/plain; charset=utf-8", "after needs exactly one of history_id and url"},
+		{"unknown format", "?format=html", `{}`, http.StatusBadRequest, "text/plain; charset=utf-8", "format must be json or text"},
+		{"invalid json", "", `{`, http.StatusBadRequest, "text/plain; charset=utf-8", "Invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/diff"+tt.query, bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			handler.Diff(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != tt.expectedType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.expectedType, contentType)
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/diff", bytes.NewReader([]byte(`{"before": {"history_id": 2}, "after": {"history_id": 1}}`)))
+	rr := httptest.NewRecorder()
+	handler.Diff(rr, req)
+	var diff models.VerificationDiff
+	if err := json.Unmarshal(rr.Body.Bytes(), &diff); err != nil {
+		t.Fatalf("Failed to unmarshal diff: %v", err)
+	}
+	if diff.Before.HistoryID != 2 || diff.After.HistoryID != 1 || len(diff.Changes) != 1 {
+		t.Errorf("Expected the history IDs and a single change, got %+v", diff)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/handlers"
+)
+
+func TestOGPHandlerHistory(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	if err := handler.OpenHistory(filepath.Join(t.TempDir(), "history.db"), time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		serve          http.HandlerFunc
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"list", http.MethodGet, "/api/v1/history?url=https://example.com/", handler.History, http.StatusOK, "[]\n"},
+		{"missing url", http.MethodGet, "/api/v1/history", handler.History, http.StatusBadRequest, "url is required\n"},
+		{"invalid limit", http.MethodGet, "/api/v1/history?url=https://example.com/&limit=all", handler.History, http.StatusBadRequest, "limit must be a number\n"},
+		{"limit too large", http.MethodGet, "/api/v1/history?url=https://example.com/&limit=500", handler.History, http.StatusBadRequest, "limit must be between 1 and 100\n"},
+		{"wrong method", http.MethodPost, "/api/v1/history?url=https://example.com/", handler.History, http.StatusMethodNotAllowed, "Method not allowed\n"},
+		{"unknown entry", http.MethodGet, "/api/v1/history/42", handler.HistoryEntry, http.StatusNotFound, "History entry not found\n"},
+		{"invalid id", http.MethodGet, "/api/v1/history/latest", handler.HistoryEntry, http.StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			tt.serve(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if rr.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
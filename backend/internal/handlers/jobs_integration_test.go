@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func postJob(handler *handlers.OGPHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "127.0.0.1:12345"
+
+	rr := httptest.NewRecorder()
+	handler.SubmitJob(rr, req)
+	return rr
+}
+
+func jobRequest(handler *handlers.OGPHandler, method, id string) (*httptest.ResponseRecorder, models.Job) {
+	req := httptest.NewRequest(method, "/api/v1/jobs/"+id, nil)
+	rr := httptest.NewRecorder()
+	handler.Job(rr, req)
+
+	var job models.Job
+	json.Unmarshal(rr.Body.Bytes(), &job)
+	return rr, job
+}
+
+func TestOGPHandlerJobs(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+
+	rr := postJob(handler, mustJSON(t, models.JobRequest{Type: models.JobTypeBatch, Batch: &models.BatchRequest{URLs: privateURLs(3)}}))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var submitted models.Job
+	if err := json.Unmarshal(rr.Body.Bytes(), &submitted); err != nil {
+		t.Fatalf("Failed to unmarshal job: %v", err)
+	}
+	if location := rr.Header().Get("Location"); location != "/api/v1/jobs/"+submitted.ID {
+		t.Errorf("Expected Location of the job, got %q", location)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var job models.Job
+	for {
+		rr, job = jobRequest(handler, http.MethodGet, submitted.ID)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if job.Status == models.JobStatusSucceeded || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != models.JobStatusSucceeded || job.Batch == nil || job.Batch.Summary.Failed != 3 {
+		t.Fatalf("Expected a finished batch of 3 failed URLs, got %+v", job)
+	}
+
+	if rr, _ := jobRequest(handler, http.MethodDelete, submitted.ID); rr.Code != http.StatusConflict || rr.Body.String() != "Job is already succeeded\n" {
+		t.Errorf("Expected 409 cancelling a finished job, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr, _ := jobRequest(handler, http.MethodGet, "unknown"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown job, got %d", rr.Code)
+	}
+	if rr, _ := jobRequest(handler, http.MethodPut, submitted.ID); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rr.Code)
+	}
+}
+
+func TestOGPHandlerSubmitJobValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"unknown type", `{"type":"render"}`, http.StatusBadRequest, "invalid job type \"render\"\n"},
+		{"missing crawl", `{"type":"crawl"}`, http.StatusBadRequest, "crawl is required for crawl jobs\n"},
+		{"too many URLs", mustJSON(t, models.JobRequest{Type: models.JobTypeBatch, Batch: &models.BatchRequest{URLs: privateURLs(101)}}), http.StatusBadRequest, "at most 100 URLs are allowed per batch\n"},
+		{"invalid JSON", "invalid json", http.StatusBadRequest, "Invalid JSON\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postJob(handlers.NewOGPHandler(), tt.body)
+			if rr.Code != tt.expectedStatus || rr.Body.String() != tt.expectedBody {
+				t.Errorf("Expected %d %q, got %d %q", tt.expectedStatus, tt.expectedBody, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestOGPHandlerSubmitJobRateLimit(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	body := mustJSON(t, models.JobRequest{Type: models.JobTypeCrawl, Crawl: &models.CrawlRequest{StartURL: "http://192.168.1.1/", MaxPages: 200}})
+
+	// 200 pages cost all 200 URLs a minute
+	if rr := postJob(handler, body); rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := postJob(handler, body); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", rr.Code)
+	}
+}
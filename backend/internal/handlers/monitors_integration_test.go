@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func monitorRequest(handler *handlers.OGPHandler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.RemoteAddr = "127.0.0.1:12345"
+
+	rr := httptest.NewRecorder()
+	if path == "/api/v1/monitors" {
+		handler.Monitors(rr, req)
+	} else {
+		handler.Monitor(rr, req)
+	}
+	return rr
+}
+
+func TestOGPHandlerMonitors(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+
+	rr := monitorRequest(handler, http.MethodPost, "/api/v1/monitors", "", mustJSON(t, models.MonitorRequest{URL: "https://example.invalid/", IntervalMinutes: 1}))
+	if rr.Code != http.StatusBadRequest || rr.Body.String() != "interval_minutes must be between 5 and 1440\n" {
+		t.Errorf("Expected 400 for a short interval, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = monitorRequest(handler, http.MethodPost, "/api/v1/monitors", "", mustJSON(t, models.MonitorRequest{URL: "https://example.invalid/", IntervalMinutes: 30}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.Monitor
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal monitor: %v", err)
+	}
+	if created.IntervalMinutes != 30 || created.Token == "" || rr.Header().Get("Location") != "/api/v1/monitors/"+created.ID {
+		t.Errorf("Expected the monitor, its token and its Location, got %+v and %q", created, rr.Header().Get("Location"))
+	}
+
+	// Registered monitors are not listed
+	rr = monitorRequest(handler, http.MethodGet, "/api/v1/monitors", "", "")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for listing monitors, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"get", http.MethodGet, "/api/v1/monitors/" + created.ID, created.Token, http.StatusOK, ""},
+		{"get without token", http.MethodGet, "/api/v1/monitors/" + created.ID, "", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"checks", http.MethodGet, "/api/v1/monitors/" + created.ID + "/checks", created.Token, http.StatusOK, ""},
+		{"checks with wrong token", http.MethodGet, "/api/v1/monitors/" + created.ID + "/checks", "wrong", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"delete with wrong token", http.MethodDelete, "/api/v1/monitors/" + created.ID, "wrong", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"unknown monitor", http.MethodGet, "/api/v1/monitors/unknown", created.Token, http.StatusNotFound, "Monitor not found\n"},
+		{"unknown action", http.MethodGet, "/api/v1/monitors/" + created.ID + "/run", created.Token, http.StatusNotFound, "404 page not found\n"},
+		{"wrong method", http.MethodPost, "/api/v1/monitors/" + created.ID, created.Token, http.StatusMethodNotAllowed, "Method not allowed\n"},
+		{"delete", http.MethodDelete, "/api/v1/monitors/" + created.ID, created.Token, http.StatusNoContent, ""},
+		{"deleted", http.MethodGet, "/api/v1/monitors/" + created.ID + "/checks", created.Token, http.StatusNotFound, "Monitor not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := monitorRequest(handler, tt.method, tt.path, tt.token, "")
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedBody != "" && rr.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestOGPHandlerOpenMonitors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitors.db")
+
+	handler := handlers.NewOGPHandler()
+	if err := handler.OpenMonitors(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rr := monitorRequest(handler, http.MethodPost, "/api/v1/monitors", "", mustJSON(t, models.MonitorRequest{URL: "https://example.invalid/", IntervalMinutes: 30}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.Monitor
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal monitor: %v", err)
+	}
+	if err := handler.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The monitor is still registered after a restart
+	handler = handlers.NewOGPHandler()
+	if err := handler.OpenMonitors(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer handler.Shutdown(context.Background())
+
+	rr = monitorRequest(handler, http.MethodGet, "/api/v1/monitors/"+created.ID, created.Token, "")
+	var restored models.Monitor
+	if err := json.Unmarshal(rr.Body.Bytes(), &restored); err != nil {
+		t.Fatalf("Failed to unmarshal monitor: %v", err)
+	}
+	if restored.URL != created.URL || restored.IntervalMinutes != 30 {
+		t.Errorf("Expected the monitor to be restored, got %+v", restored)
+	}
+}
@@ -1,47 +1,798 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/render"
 	"ogp-verification-service/internal/services"
+	"ogp-verification-service/internal/storage"
+)
+
+const (
+	// requestsPerMinute is each client's rate limit.
+	requestsPerMinute = 10
+	// urlsPerMinute is each client's budget of verified URLs, shared by
+	// single verifications, batches and crawls.
+	urlsPerMinute = 200
 )
 
 type OGPHandler struct {
-	service *services.OGPService
-	limiter *RateLimiter
+	service  *services.OGPService
+	limiter  *RateLimiter
+	renderer *render.CardRenderer
+	jobs     *services.JobManager
+	webhooks *services.WebhookManager
+	monitors *services.MonitorManager
+	// monitorsDB is closed on Shutdown once OpenMonitors has replaced the
+	// in-memory monitors.
+	monitorsDB *storage.SQLiteMonitorStore
+	history    *services.History
+	// historyDB is closed on Shutdown once OpenHistory has replaced the
+	// in-memory history.
+	historyDB *storage.SQLiteHistoryStore
+	reports   *services.Reports
+	// reportsDB is closed on Shutdown once OpenReports has replaced the
+	// in-memory reports.
+	reportsDB *storage.SQLiteReportStore
+}
+
+type RateLimiter struct {
+	clients map[string]*ClientInfo
+	mu      sync.RWMutex
+}
+
+type ClientInfo struct {
+	requests  int
+	urls      int
+	lastReset time.Time
+}
+
+func NewOGPHandler() *OGPHandler {
+	service := services.NewOGPService()
+	jobs := services.NewJobManager(service, services.NewMemoryJobStore(services.DefaultJobRetention), services.DefaultJobWorkers, services.DefaultJobQueueSize)
+	webhooks := services.NewWebhookManager(service)
+	monitors := services.NewMonitorManager(service, services.NewMemoryMonitorStore())
+	jobs.OnFinish(webhooks.JobFinished)
+	monitors.OnRegression(webhooks.MonitorRegressed)
+
+	h := &OGPHandler{
+		service: service,
+		limiter: &RateLimiter{
+			clients: make(map[string]*ClientInfo),
+		},
+		renderer: render.NewCardRenderer(),
+		jobs:     jobs,
+		webhooks: webhooks,
+		monitors: monitors,
+		history:  services.NewHistory(service, services.NewMemoryHistoryStore(), services.DefaultHistoryRetention),
+		reports:  services.NewReports(services.NewMemoryReportStore(), services.DefaultReportExpiry),
+	}
+	service.OnVerified(func(response *models.OGPResponse) {
+		h.share(response)
+		webhooks.Verified(response)
+		h.history.Record(response)
+	})
+	return h
+}
+
+// Shutdown stops the monitors and accepting jobs and waits for the running
+// jobs, then for the webhook deliveries they trigger, until ctx ends.
+func (h *OGPHandler) Shutdown(ctx context.Context) error {
+	monitorsErr := h.monitors.Shutdown(ctx)
+	jobsErr := h.jobs.Shutdown(ctx)
+	err := errors.Join(monitorsErr, jobsErr, h.webhooks.Shutdown(ctx))
+	if h.monitorsDB != nil {
+		err = errors.Join(err, h.monitorsDB.Close())
+	}
+	if h.historyDB != nil {
+		err = errors.Join(err, h.historyDB.Close())
+	}
+	if h.reportsDB != nil {
+		err = errors.Join(err, h.reportsDB.Close())
+	}
+	return err
+}
+
+func (h *OGPHandler) VerifyOGP(w http.ResponseWriter, r *http.Request) {
+	// Handle CORS preflight requests
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.OGPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	if err := h.service.ValidateOptions(req.VerifyOptions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.AllowURLs(clientIP, verifyCost(req.VerifyOptions)) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	response, err := h.service.FetchOGPDataWithOptions(r.Context(), req.URL, req.VerifyOptions)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching OGP data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// VerifyOGPStream is VerifyOGP sent as Server-Sent Events, one event per
+// stage as it completes, so clients can show results before the image and
+// icons have been downloaded. The last event is done, with the full
+// response, or error. Request errors are reported before the stream starts,
+// with the same status codes as VerifyOGP.
+func (h *OGPHandler) VerifyOGPStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var req models.OGPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	if err := h.service.ValidateOptions(req.VerifyOptions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.AllowURLs(clientIP, verifyCost(req.VerifyOptions)) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep reverse proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	setCORSHeaders(w)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	emit := func(event models.VerifyEvent) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			event = models.VerifyEvent{Stage: models.VerifyStageError, Error: "Error encoding event"}
+			data, _ = json.Marshal(event)
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Stage, data)
+		flusher.Flush()
+	}
+
+	if _, err := h.service.FetchOGPDataWithEvents(r.Context(), req.URL, req.VerifyOptions, emit); err != nil {
+		emit(models.VerifyEvent{Stage: models.VerifyStageError, Error: fmt.Sprintf("Error fetching OGP data: %v", err)})
+	}
+}
+
+// VerifyBatch verifies a list of URLs in one request. Each URL counts
+// against the client's urlsPerMinute budget.
+func (h *OGPHandler) VerifyBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.URLs) == 0 {
+		http.Error(w, "URLs are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.URLs) > services.MaxBatchURLs {
+		http.Error(w, fmt.Sprintf("At most %d URLs are allowed per batch", services.MaxBatchURLs), http.StatusBadRequest)
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	if err := h.service.ValidateOptions(req.VerifyOptions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.AllowURLs(clientIP, len(req.URLs)) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	response, err := h.service.VerifyBatch(r.Context(), req.URLs, req.VerifyOptions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Crawl audits a whole site from its sitemap or by following links. The
+// page budget is charged against the rate limit like a batch of that size.
+func (h *OGPHandler) Crawl(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CrawlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	if err := h.service.ValidateCrawl(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.AllowURLs(clientIP, crawlCost(req)) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	report, err := h.service.Crawl(r.Context(), req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error crawling site: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
 }
 
-type RateLimiter struct {
-	clients map[string]*ClientInfo
-	mu      sync.RWMutex
+// SubmitJob queues a batch or crawl to run in the background and returns the
+// job with 202 Accepted. It is charged against the rate limit like the
+// synchronous request; polling the job is not.
+func (h *OGPHandler) SubmitJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	lang := services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	if req.Batch != nil && req.Batch.Lang == "" {
+		req.Batch.Lang = lang
+	}
+	if req.Crawl != nil && req.Crawl.Lang == "" {
+		req.Crawl.Lang = lang
+	}
+
+	if err := h.service.ValidateJob(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var cost int
+	if req.Type == models.JobTypeCrawl {
+		cost = crawlCost(*req.Crawl)
+	} else {
+		cost = len(req.Batch.URLs)
+	}
+	clientIP := h.getClientIP(r)
+	if !h.limiter.AllowURLs(clientIP, cost) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	job, err := h.jobs.Submit(req)
+	if errors.Is(err, services.ErrJobQueueFull) || errors.Is(err, services.ErrJobsClosed) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	setCORSHeaders(w)
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Job returns a job's progress and results on GET and cancels it on DELETE.
+// It serves /api/v1/jobs/{id}.
+func (h *OGPHandler) Job(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var job models.Job
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		job, err = h.jobs.Get(id)
+	case http.MethodDelete:
+		job, err = h.jobs.Cancel(id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if errors.Is(err, services.ErrJobNotFound) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrJobFinished) {
+		http.Error(w, fmt.Sprintf("Job is already %s", job.Status), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Webhooks registers a webhook on POST. The secret used to sign payloads
+// and the token needed to manage the webhook are only returned on
+// registration. Webhooks are not listed, since their URLs often carry
+// credentials of their own.
+func (h *OGPHandler) Webhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.Allow(clientIP) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.webhooks.Register(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/webhooks/"+webhook.ID)
+	setCORSHeaders(w)
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(webhook); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Webhook serves /api/v1/webhooks/{id} (GET, DELETE),
+// /api/v1/webhooks/{id}/deliveries (GET) and /api/v1/webhooks/{id}/test
+// (POST), which sends a webhook.test event once and returns its delivery.
+// Every request needs the webhook's token as a bearer token.
+func (h *OGPHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/webhooks/"), "/")
+	id, action := parts[0], ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	if id == "" || len(parts) > 2 || (len(parts) == 2 && action != "deliveries" && action != "test") {
+		http.NotFound(w, r)
+		return
+	}
+
+	err := h.webhooks.Authorize(id, bearerToken(r))
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+
+	var response interface{}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		response, err = h.webhooks.Get(id)
+	case action == "" && r.Method == http.MethodDelete:
+		if err = h.webhooks.Delete(id); err == nil {
+			setCORSHeaders(w)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	case action == "deliveries" && r.Method == http.MethodGet:
+		response, err = h.webhooks.Deliveries(id)
+	case action == "test" && r.Method == http.MethodPost:
+		clientIP := h.getClientIP(r)
+		if !h.limiter.Allow(clientIP) {
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		response, err = h.webhooks.Test(r.Context(), id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Monitors registers a monitor on POST. The token needed to manage the
+// monitor is only returned on registration, and monitors are not listed. The
+// first check runs in the background; the response does not wait for it.
+func (h *OGPHandler) Monitors(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.Allow(clientIP) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	var req models.MonitorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.ValidateMonitor(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	monitor, err := h.monitors.Register(req)
+	switch {
+	case errors.Is(err, services.ErrTooManyMonitors):
+		http.Error(w, fmt.Sprintf("At most %d monitors can be registered", services.MaxMonitors), http.StatusConflict)
+		return
+	case errors.Is(err, services.ErrMonitorsClosed):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/monitors/"+monitor.ID)
+	setCORSHeaders(w)
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(monitor); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Monitor serves /api/v1/monitors/{id} (GET, DELETE) and
+// /api/v1/monitors/{id}/checks (GET), the monitor's recent checks, newest
+// first. Every request needs the monitor's token as a bearer token.
+func (h *OGPHandler) Monitor(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/monitors/"), "/")
+	id, action := parts[0], ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	if id == "" || len(parts) > 2 || (len(parts) == 2 && action != "checks") {
+		http.NotFound(w, r)
+		return
+	}
+
+	err := h.monitors.Authorize(id, bearerToken(r))
+	if errors.Is(err, services.ErrMonitorNotFound) {
+		http.Error(w, "Monitor not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+
+	var response interface{}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		response, err = h.monitors.Get(id)
+	case action == "" && r.Method == http.MethodDelete:
+		if err = h.monitors.Delete(id); err == nil {
+			setCORSHeaders(w)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	case action == "checks" && r.Method == http.MethodGet:
+		response, err = h.monitors.Checks(id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if errors.Is(err, services.ErrMonitorNotFound) {
+		http.Error(w, "Monitor not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE, OPTIONS")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
 }
 
-type ClientInfo struct {
-	requests  int
-	lastReset time.Time
+// History lists the latest verifications of the url query parameter,
+// newest first and without their responses. Reading history is not rate
+// limited.
+func (h *OGPHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			http.Error(w, "limit must be a number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := h.history.List(query.Get("url"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
 }
 
-func NewOGPHandler() *OGPHandler {
-	return &OGPHandler{
-		service: services.NewOGPService(),
-		limiter: &RateLimiter{
-			clients: make(map[string]*ClientInfo),
-		},
+// HistoryEntry serves /api/v1/history/{id}, a stored verification with its
+// full response.
+func (h *OGPHandler) HistoryEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/v1/history/"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entry, err := h.history.Get(id)
+	if errors.Is(err, services.ErrHistoryNotFound) {
+		http.Error(w, "History entry not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
 	}
 }
 
-func (h *OGPHandler) VerifyOGP(w http.ResponseWriter, r *http.Request) {
-	// Handle CORS preflight requests
+// Diff compares two verifications, each a stored history entry or a URL
+// verified now, such as staging against production. Only the live URLs
+// count against the rate limit. With ?format=text the diff is returned as
+// plain text instead of JSON.
+func (h *OGPHandler) Diff(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		setCORSHeaders(w)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -51,33 +802,218 @@ func (h *OGPHandler) VerifyOGP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "text" {
+		http.Error(w, "format must be json or text", http.StatusBadRequest)
+		return
+	}
+
+	var req models.DiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	if err := h.service.ValidateDiff(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var cost int
+	for _, target := range []models.DiffTarget{req.Before, req.After} {
+		if target.URL != "" {
+			cost++
+		}
+	}
 	clientIP := h.getClientIP(r)
-	if !h.limiter.Allow(clientIP) {
+	if cost > 0 && !h.limiter.AllowURLs(clientIP, cost) {
 		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 		return
 	}
 
-	var req models.OGPRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var sides [2]*models.OGPResponse
+	var historyIDs [2]int64
+	for i, target := range []models.DiffTarget{req.Before, req.After} {
+		if target.URL != "" {
+			response, err := h.service.FetchOGPDataWithOptions(r.Context(), target.URL, req.VerifyOptions)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error fetching OGP data: %v", err), http.StatusInternalServerError)
+				return
+			}
+			sides[i] = response
+			continue
+		}
+
+		entry, err := h.history.Get(target.HistoryID)
+		if errors.Is(err, services.ErrHistoryNotFound) {
+			http.Error(w, fmt.Sprintf("History entry %d not found", target.HistoryID), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sides[i], historyIDs[i] = entry.Response, entry.ID
+	}
+
+	diff, err := h.service.DiffResponses(sides[0], sides[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	diff.Before.HistoryID, diff.After.HistoryID = historyIDs[0], historyIDs[1]
+
+	setCORSHeaders(w)
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, h.service.FormatDiff(diff))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(diff); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Report serves /r/{id}, a shared verification, as a static HTML page or,
+// with ?format=json or an Accept header preferring JSON, as JSON. Reading
+// reports is not rate limited.
+func (h *OGPHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+		if accept := r.Header.Get("Accept"); strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html") {
+			format = "json"
+		}
+	}
+	if format != "html" && format != "json" {
+		http.Error(w, "format must be html or json", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.reports.Get(strings.TrimPrefix(r.URL.Path, "/r/"))
+	if errors.Is(err, services.ErrReportNotFound) {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Vary", "Accept")
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		setCORSHeaders(w)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			http.Error(w, "Error encoding response", http.StatusInternalServerError)
+			return
+		}
+		return
+	}
+
+	var page bytes.Buffer
+	if err := h.writeReportHTML(&page, report); err != nil {
+		http.Error(w, "Error rendering report", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", reportPageCSP)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(page.Bytes())
+}
+
+// crawlCost charges a crawl for its page budget.
+func crawlCost(req models.CrawlRequest) int {
+	if req.MaxPages == 0 {
+		return services.DefaultCrawlPages
+	}
+	return req.MaxPages
+}
+
+// verifyCost charges a single page verification for the page and, when
+// alternates are checked, for every alternate locale it may fetch.
+func verifyCost(opts models.VerifyOptions) int {
+	if opts.CheckAlternates {
+		return 1 + services.MaxAlternateFetches
+	}
+	return 1
+}
+
+// maxHTMLBytes caps the document accepted by VerifyHTML.
+const maxHTMLBytes = 5 << 20
+
+// VerifyHTML checks an HTML document posted in the request body instead of
+// fetching a URL, so cards can be verified before a page is deployed.
+func (h *OGPHandler) VerifyHTML(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.VerifyHTMLRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHTMLBytes)).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	if req.URL == "" {
-		http.Error(w, "URL is required", http.StatusBadRequest)
+	if strings.TrimSpace(req.HTML) == "" {
+		http.Error(w, "HTML is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Lang == "" {
+		req.Lang = services.NegotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.AllowURLs(clientIP, verifyCost(req.VerifyOptions)) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 		return
 	}
 
-	response, err := h.service.FetchOGPData(req.URL)
+	response, err := h.service.VerifyHTML(r.Context(), req.HTML, req.BaseURL, req.ProbeImages, req.VerifyOptions)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error fetching OGP data: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	h.share(response)
 
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	setCORSHeaders(w)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "Error encoding response", http.StatusInternalServerError)
@@ -85,6 +1021,181 @@ func (h *OGPHandler) VerifyOGP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// LoadCardFont adds a fallback font for rendering glyphs, such as Japanese,
+// that the embedded card fonts do not cover.
+func (h *OGPHandler) LoadCardFont(path string) error {
+	return h.renderer.LoadFallbackFont(path)
+}
+
+// RegisterRule adds a custom validation rule, such as a house style check,
+// that runs on every verification.
+func (h *OGPHandler) RegisterRule(rule services.Rule) error {
+	return h.service.RegisterRule(rule)
+}
+
+// LoadRuleConfig reads a JSON models.RuleConfig from path and applies it as
+// the default for every request.
+func (h *OGPHandler) LoadRuleConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule config: %w", err)
+	}
+
+	var config models.RuleConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse rule config: %w", err)
+	}
+	return h.service.SetRuleConfig(config)
+}
+
+// OpenHistory keeps the verification history in the SQLite database at
+// path instead of in memory, deleting entries older than retention (zero
+// keeps them forever). It must be called before the handler serves
+// requests.
+func (h *OGPHandler) OpenHistory(path string, retention time.Duration) error {
+	store, err := storage.OpenSQLiteHistoryStore(path)
+	if err != nil {
+		return err
+	}
+	h.history = services.NewHistory(h.service, store, retention)
+	h.historyDB = store
+	return nil
+}
+
+// OpenMonitors keeps monitors and their checks in the SQLite database at
+// path instead of in memory, and resumes the monitors stored there. It must
+// be called before the handler serves requests.
+func (h *OGPHandler) OpenMonitors(path string) error {
+	store, err := storage.OpenSQLiteMonitorStore(path)
+	if err != nil {
+		return err
+	}
+	monitors := services.NewMonitorManager(h.service, store)
+	monitors.OnRegression(h.webhooks.MonitorRegressed)
+	if err := monitors.Restore(); err != nil {
+		monitors.Shutdown(context.Background())
+		store.Close()
+		return err
+	}
+	// Nothing has been registered with the in-memory monitors yet
+	h.monitors.Shutdown(context.Background())
+	h.monitors = monitors
+	h.monitorsDB = store
+	return nil
+}
+
+// OpenReports keeps shared reports in the SQLite database at path instead
+// of in memory, expiring them after expiry (zero keeps them forever). It
+// must be called before the handler serves requests.
+func (h *OGPHandler) OpenReports(path string, expiry time.Duration) error {
+	store, err := storage.OpenSQLiteReportStore(path)
+	if err != nil {
+		return err
+	}
+	h.reports = services.NewReports(store, expiry)
+	h.reportsDB = store
+	return nil
+}
+
+// share stores a verification as a report and sets its permalink. It is
+// called for every fetched page and for VerifyHTML. A failure is logged; the
+// verification is still returned.
+func (h *OGPHandler) share(response *models.OGPResponse) {
+	if _, err := h.reports.Create(response); err != nil {
+		log.Printf("Report of %s not saved: %v", response.URL, err)
+	}
+}
+
+// RenderCard renders one platform's card from a previously returned
+// OGPResponse as a PNG image.
+func (h *OGPHandler) RenderCard(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		setCORSHeaders(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientIP := h.getClientIP(r)
+	if !h.limiter.Allow(clientIP) {
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	platform := r.URL.Query().Get("platform")
+	if platform == "" {
+		http.Error(w, "platform is required", http.StatusBadRequest)
+		return
+	}
+
+	var response models.OGPResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	preview, ok := h.service.PlatformPreview(response.Previews, platform)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown platform: %s", platform), http.StatusBadRequest)
+		return
+	}
+
+	setCORSHeaders(w)
+
+	var img image.Image
+	if preview.Image != "" {
+		fetched, err := h.service.FetchImage(r.Context(), preview.Image)
+		if err != nil {
+			// Render the card without its image rather than failing outright
+			w.Header().Set("X-Card-Image-Error", err.Error())
+		} else {
+			img = fetched
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.RenderPNG(&buf, preview, h.cardMetaLine(response, preview), img); err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering card: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// cardMetaLine is the domain or site-name line shown on a card.
+func (h *OGPHandler) cardMetaLine(response models.OGPResponse, preview models.PlatformPreview) string {
+	if preview.DisplayURL != "" {
+		return preview.DisplayURL
+	}
+	if preview.Platform == "discord" && response.OGPData.SiteName != "" {
+		return response.OGPData.SiteName
+	}
+	if parsed, err := url.Parse(response.URL); err == nil && parsed.Host != "" {
+		return strings.ToUpper(parsed.Hostname())
+	}
+	return response.OGPData.SiteName
+}
+
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
 func (h *OGPHandler) getClientIP(r *http.Request) string {
 	xff := r.Header.Get("X-Forwarded-For")
 	if xff != "" {
@@ -98,6 +1209,12 @@ func (h *OGPHandler) getClientIP(r *http.Request) string {
 }
 
 func (rl *RateLimiter) Allow(clientIP string) bool {
+	return rl.AllowURLs(clientIP, 1)
+}
+
+// AllowURLs reports whether a request verifying n URLs fits in the client's
+// budgets for the current minute and, if so, counts it.
+func (rl *RateLimiter) AllowURLs(clientIP string, n int) bool {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -105,23 +1222,32 @@ func (rl *RateLimiter) Allow(clientIP string) bool {
 	client, exists := rl.clients[clientIP]
 	
 	if !exists {
+		if n > urlsPerMinute {
+			return false
+		}
 		rl.clients[clientIP] = &ClientInfo{
 			requests:  1,
+			urls:      n,
 			lastReset: now,
 		}
 		return true
 	}
 
 	if now.Sub(client.lastReset) >= time.Minute {
+		if n > urlsPerMinute {
+			return false
+		}
 		client.requests = 1
+		client.urls = n
 		client.lastReset = now
 		return true
 	}
 
-	if client.requests >= 10 {
+	if client.requests+1 > requestsPerMinute || client.urls+n > urlsPerMinute {
 		return false
 	}
 
 	client.requests++
+	client.urls += n
 	return true
 }
\ No newline at end of file
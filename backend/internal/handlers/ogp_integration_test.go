@@ -60,6 +60,42 @@ func TestOGPHandlerIntegration(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Invalid truncation mode",
+			requestBody:    models.OGPRequest{URL: "https://example.com", VerifyOptions: models.VerifyOptions{TruncationMode: "pixels"}},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				expected := "invalid truncation_mode \"pixels\"\n"
+				response := string(body)
+				if response != expected {
+					t.Errorf("Expected %q, got %q", expected, response)
+				}
+			},
+		},
+		{
+			name:           "Unknown validation rule",
+			requestBody:    models.OGPRequest{URL: "https://example.com", VerifyOptions: models.VerifyOptions{Rules: models.RuleConfig{Disabled: []string{"OG_TITLE_TOO_LONG"}}}},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				expected := "unknown rule \"OG_TITLE_TOO_LONG\"\n"
+				response := string(body)
+				if response != expected {
+					t.Errorf("Expected %q, got %q", expected, response)
+				}
+			},
+		},
+		{
+			name:           "Unsupported language",
+			requestBody:    models.OGPRequest{URL: "https://example.com", VerifyOptions: models.VerifyOptions{Lang: "fr"}},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, body []byte) {
+				expected := "unsupported lang \"fr\"\n"
+				response := string(body)
+				if response != expected {
+					t.Errorf("Expected %q, got %q", expected, response)
+				}
+			},
+		},
 		{
 			name:           "Private IP request",
 			requestBody:    models.OGPRequest{URL: "http://192.168.1.1"},
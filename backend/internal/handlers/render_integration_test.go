@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPHandlerRenderCard(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+
+	response := models.OGPResponse{
+		URL: "https://example.com/page",
+		OGPData: models.OGPData{
+			Title:    "Example title",
+			SiteName: "Example",
+		},
+		Previews: models.PlatformPreviews{
+			Twitter: models.PlatformPreview{Platform: "twitter", Title: "Example title", Description: "Example description"},
+			Discord: models.PlatformPreview{Title: "Example title"},
+		},
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		body           []byte
+		expectedStatus int
+	}{
+		{"twitter card", "?platform=twitter", body, http.StatusOK},
+		{"platform field missing in body", "?platform=discord", body, http.StatusOK},
+		{"missing platform", "", body, http.StatusBadRequest},
+		{"unknown platform", "?platform=myspace", body, http.StatusBadRequest},
+		{"invalid JSON", "?platform=twitter", []byte("invalid json"), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/render"+tt.query, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.RenderCard(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if contentType := rr.Header().Get("Content-Type"); contentType != "image/png" {
+				t.Errorf("Expected image/png, got %s", contentType)
+			}
+			if _, err := png.Decode(rr.Body); err != nil {
+				t.Errorf("Expected valid PNG: %v", err)
+			}
+		})
+	}
+}
+
+func TestOGPHandlerRenderCardMethodNotAllowed(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ogp/render?platform=twitter", nil)
+	rr := httptest.NewRecorder()
+	handler.RenderCard(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/services"
+)
+
+// reportPageCSP allows the page's inline styles and the og:image, nothing
+// else; the report shows values taken from arbitrary pages.
+const reportPageCSP = "default-src 'none'; img-src http: https: data:; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'"
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="{{.Response.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>OGP report: {{.Response.URL}}</title>
+<meta property="og:type" content="website">
+<meta property="og:title" content="OGP report: {{or .Response.OGPData.Title .Response.URL}}">
+<meta property="og:description" content="{{.Summary}}">
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; color: #1f2937; background: #f9fafb; }
+main { max-width: 960px; margin: 0 auto; padding: 24px; }
+h1 { font-size: 1.5rem; margin-bottom: 4px; }
+h2 { font-size: 1.125rem; margin-top: 32px; }
+a { color: #2563eb; word-break: break-all; }
+table { width: 100%; border-collapse: collapse; background: #fff; }
+th, td { text-align: left; vertical-align: top; padding: 6px 10px; border-bottom: 1px solid #e5e7eb; }
+th { width: 180px; font-weight: 600; }
+.meta { color: #6b7280; font-size: 0.875rem; }
+.summary { display: flex; gap: 16px; flex-wrap: wrap; }
+.summary div { background: #fff; border: 1px solid #e5e7eb; border-radius: 8px; padding: 12px 16px; }
+.summary strong { display: block; font-size: 1.5rem; }
+.ok { color: #15803d; }
+.error { color: #b91c1c; }
+.warning { color: #b45309; }
+.info { color: #1d4ed8; }
+.image { max-width: 100%; max-height: 320px; border: 1px solid #e5e7eb; border-radius: 8px; }
+ul.issues { list-style: none; padding: 0; }
+ul.issues li { background: #fff; border: 1px solid #e5e7eb; border-radius: 8px; padding: 8px 12px; margin-bottom: 8px; }
+code { font-size: 0.875em; }
+</style>
+</head>
+<body>
+<main>
+<h1>OGP report</h1>
+{{with .Response.URL}}<p><a href="{{.}}" rel="nofollow noopener noreferrer">{{.}}</a></p>{{end}}
+<p class="meta">Verified {{.Response.Timestamp.UTC.Format "2006-01-02 15:04:05 UTC"}}{{with .Report.ExpiresAt}} · Link expires {{.UTC.Format "2006-01-02 15:04 UTC"}}{{end}} · <a href="?format=json">JSON</a></p>
+
+<section class="summary">
+<div>Score<strong>{{.Response.Score.Score}}/100</strong></div>
+<div>Validation<strong class="{{if .Response.Validation.IsValid}}ok{{else}}error{{end}}">{{if .Response.Validation.IsValid}}Valid{{else}}Invalid{{end}}</strong></div>
+{{if .Response.Score.Threshold}}<div>Threshold {{.Response.Score.Threshold}}<strong class="{{if .Response.Score.Passed}}ok{{else}}error{{end}}">{{if .Response.Score.Passed}}Passed{{else}}Failed{{end}}</strong></div>{{end}}
+<div>Issues<strong>{{len .Issues}}</strong></div>
+</section>
+
+<h2>Open Graph</h2>
+{{with .Response.ImageInfo.URL}}<p><img class="image" src="{{.}}" alt="{{$.Response.OGPData.ImageAlt}}"></p>{{end}}
+<table>
+{{range .Fields}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+
+<h2>Issues ({{len .Issues}})</h2>
+{{if .Issues}}<ul class="issues">
+{{range .Issues}}<li><span class="{{.Severity}}">{{.Severity}}</span> <code>{{.Code}}</code>{{with .Property}} <code>{{.}}</code>{{end}}<br>{{.Message}}{{with .DocsURL}} <a href="{{.}}" rel="noopener noreferrer">Docs</a>{{end}}</li>
+{{end}}</ul>{{else}}<p class="ok">No issues found.</p>{{end}}
+
+<h2>Score</h2>
+<table>
+{{range .Response.Score.Categories}}<tr><th>{{.Name}}</th><td>{{.Score}}/100 (weight {{.Weight}}%)</td></tr>
+{{end}}</table>
+
+<h2>Previews</h2>
+<table>
+{{range .Previews}}<tr><th>{{.Platform}}</th><td><strong>{{.DisplayTitle}}</strong><br>{{.DisplayDescription}}{{range .Warnings}}<br><span class="warning">{{.}}</span>{{end}}</td></tr>
+{{end}}</table>
+</main>
+</body>
+</html>
+`))
+
+// reportField is a row of the report page's Open Graph table.
+type reportField struct {
+	Name  string
+	Value string
+}
+
+type reportPage struct {
+	Report   models.Report
+	Response *models.OGPResponse
+	Summary  string
+	Fields   []reportField
+	Issues   []models.ValidationIssue
+	Previews []models.PlatformPreview
+}
+
+// writeReportHTML renders a report as a static HTML page.
+func (h *OGPHandler) writeReportHTML(w io.Writer, report models.Report) error {
+	response := report.Response
+	data := response.OGPData
+	page := reportPage{
+		Report:   report,
+		Response: response,
+		Fields: []reportField{
+			{"og:title", data.Title},
+			{"og:description", data.Description},
+			{"og:image", data.Image},
+			{"og:image:alt", data.ImageAlt},
+			{"og:url", data.URL},
+			{"og:type", data.Type},
+			{"og:site_name", data.SiteName},
+			{"og:locale", data.Locale},
+			{"twitter:card", data.TwitterCard},
+		},
+	}
+	for _, issues := range [][]models.ValidationIssue{
+		response.Validation.Issues,
+		response.Validation.Accessibility.Issues,
+		response.SEO.Issues,
+		response.Icons.Issues,
+	} {
+		page.Issues = append(page.Issues, issues...)
+	}
+	for _, platform := range services.PlatformNames {
+		if preview, ok := h.service.PlatformPreview(response.Previews, platform); ok {
+			page.Previews = append(page.Previews, preview)
+		}
+	}
+
+	validity := "valid"
+	if !response.Validation.IsValid {
+		validity = "invalid"
+	}
+	page.Summary = fmt.Sprintf("Score %d/100, %s, %d issues", response.Score.Score, validity, len(page.Issues))
+
+	return reportTemplate.Execute(w, page)
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPHandlerReport(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	if err := handler.OpenReports(filepath.Join(t.TempDir(), "reports.db"), time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Every verification is shared under a permalink
+	body := mustJSON(t, models.VerifyHTMLRequest{
+		HTML:    `<html><head><meta property="og:title" content="<script>alert(1)</script>"></head></html>`,
+		BaseURL: "https://example.com/draft",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/verify-html", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.VerifyHTML(rr, req)
+	var response models.OGPResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !strings.HasPrefix(response.Permalink, "/r/") || len(response.Permalink) != len("/r/")+16 {
+		t.Fatalf("Expected a permalink, got %q", response.Permalink)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		accept         string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{"html", http.MethodGet, response.Permalink, "text/html,application/xhtml+xml,*/*", http.StatusOK, "text/html; charset=utf-8", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{"json by format", http.MethodGet, response.Permalink + "?format=json", "", http.StatusOK, "application/json", `"permalink":"` + response.Permalink + `"`},
+		{"json by accept", http.MethodGet, response.Permalink, "application/json", http.StatusOK, "application/json", `"expires_at":`},
+		{"unknown format", http.MethodGet, response.Permalink + "?format=pdf", "", http.StatusBadRequest, "text/plain; charset=utf-8", "format must be html or json"},
+		{"unknown report", http.MethodGet, "/r/AAAAAAAAAAAAAAAA", "", http.StatusNotFound, "text/plain; charset=utf-8", "Report not found"},
+		{"wrong method", http.MethodDelete, response.Permalink, "", http.StatusMethodNotAllowed, "text/plain; charset=utf-8", "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			handler.Report(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != tt.expectedType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.expectedType, contentType)
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+
+	req = httptest.NewRequest(http.MethodGet, response.Permalink, nil)
+	rr = httptest.NewRecorder()
+	handler.Report(rr, req)
+	if strings.Contains(rr.Body.String(), "<script>") {
+		t.Error("Expected page values to be escaped")
+	}
+	if csp := rr.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Errorf("Expected a restrictive Content-Security-Policy, got %q", csp)
+	}
+
+	for _, path := range []string{response.Permalink, response.Permalink + "?format=json"} {
+		req = httptest.NewRequest(http.MethodHead, path, nil)
+		rr = httptest.NewRecorder()
+		handler.Report(rr, req)
+		if rr.Code != http.StatusOK || rr.Body.Len() != 0 {
+			t.Errorf("Expected HEAD %s to return status %d without a body, got %d with %d bytes", path, http.StatusOK, rr.Code, rr.Body.Len())
+		}
+	}
+	if err := handler.Shutdown(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
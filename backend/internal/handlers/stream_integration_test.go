@@ -0,0 +1,63 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+)
+
+func TestOGPHandlerVerifyOGPStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "fetch error is streamed",
+			body:           `{"url":"http://192.168.1.1"}`,
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/event-stream",
+			expectedBody:   "event: error\ndata: {\"stage\":\"error\",\"error\":\"Error fetching OGP data: private IP addresses are not allowed\"}\n\n",
+		},
+		{
+			name:           "missing URL",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   "URL is required\n",
+		},
+		{
+			name:           "invalid options",
+			body:           `{"url":"https://example.com","lang":"fr"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "text/plain; charset=utf-8",
+			expectedBody:   "unsupported lang \"fr\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/verify/stream", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			req.RemoteAddr = "127.0.0.1:12345"
+
+			rr := httptest.NewRecorder()
+			handlers.NewOGPHandler().VerifyOGPStream(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != tt.expectedType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.expectedType, contentType)
+			}
+			if rr.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
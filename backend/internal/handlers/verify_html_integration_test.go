@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPHandlerVerifyHTML(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	document := `<html><head><meta property="og:title" content="Draft title" /></head></html>`
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"document without base URL", mustJSON(t, models.VerifyHTMLRequest{HTML: document}), http.StatusOK, ""},
+		{"document with base URL", mustJSON(t, models.VerifyHTMLRequest{HTML: document, BaseURL: "https://example.com/draft"}), http.StatusOK, ""},
+		{"missing HTML", mustJSON(t, models.VerifyHTMLRequest{BaseURL: "https://example.com/"}), http.StatusBadRequest, "HTML is required\n"},
+		{"relative base URL", mustJSON(t, models.VerifyHTMLRequest{HTML: document, BaseURL: "/draft"}), http.StatusBadRequest, "base URL must use http or https\n"},
+		{"unsupported language", mustJSON(t, models.VerifyHTMLRequest{HTML: document, VerifyOptions: models.VerifyOptions{Lang: "fr"}}), http.StatusBadRequest, "unsupported lang \"fr\"\n"},
+		{"invalid JSON", "invalid json", http.StatusBadRequest, "Invalid JSON\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/verify-html", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", "ja")
+
+			rr := httptest.NewRecorder()
+			handler.VerifyHTML(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if rr.Body.String() != tt.expectedBody {
+					t.Errorf("Expected %q, got %q", tt.expectedBody, rr.Body.String())
+				}
+				return
+			}
+
+			var resp models.OGPResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.OGPData.Title != "Draft title" {
+				t.Errorf("Expected parsed title, got %q", resp.OGPData.Title)
+			}
+			if resp.Lang != "ja" {
+				t.Errorf("Expected Accept-Language to select ja, got %q", resp.Lang)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal request body: %v", err)
+	}
+	return string(data)
+}
+
+func TestOGPHandlerVerifyHTMLAlternatesRateLimit(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+	body := mustJSON(t, models.VerifyHTMLRequest{
+		HTML:          `<html><head><meta property="og:title" content="Draft title" /></head></html>`,
+		VerifyOptions: models.VerifyOptions{CheckAlternates: true},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ogp/verify-html", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "127.0.0.1:12345"
+	rr := httptest.NewRecorder()
+	handler.VerifyHTML(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	// The alternates took 10 of the 200 URLs a minute on top of the page
+	if rr := postBatch(handler, mustJSON(t, models.BatchRequest{URLs: privateURLs(100)})); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if rr := postBatch(handler, mustJSON(t, models.BatchRequest{URLs: privateURLs(90)})); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d for a batch past the URL budget, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ogp-verification-service/internal/handlers"
+	"ogp-verification-service/internal/models"
+)
+
+func webhookRequest(handler *handlers.OGPHandler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.RemoteAddr = "127.0.0.1:12345"
+
+	rr := httptest.NewRecorder()
+	if path == "/api/v1/webhooks" {
+		handler.Webhooks(rr, req)
+	} else {
+		handler.Webhook(rr, req)
+	}
+	return rr
+}
+
+func TestOGPHandlerWebhooks(t *testing.T) {
+	handler := handlers.NewOGPHandler()
+
+	rr := webhookRequest(handler, http.MethodPost, "/api/v1/webhooks", "", mustJSON(t, models.WebhookRequest{URL: "http://192.168.1.1/hook", Events: []string{models.WebhookEventJobCompleted}}))
+	if rr.Code != http.StatusBadRequest || rr.Body.String() != "private IP addresses are not allowed\n" {
+		t.Errorf("Expected 400 for a private webhook URL, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = webhookRequest(handler, http.MethodPost, "/api/v1/webhooks", "", mustJSON(t, models.WebhookRequest{URL: "https://hooks.example.com/ogp", Events: []string{models.WebhookEventJobCompleted}}))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created models.Webhook
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal webhook: %v", err)
+	}
+	if created.Secret == "" || created.Token == "" || rr.Header().Get("Location") != "/api/v1/webhooks/"+created.ID {
+		t.Errorf("Expected the secret, token and Location on creation, got %+v and %q", created, rr.Header().Get("Location"))
+	}
+
+	// Registered webhooks are not listed
+	rr = webhookRequest(handler, http.MethodGet, "/api/v1/webhooks", "", "")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for listing webhooks, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"get", http.MethodGet, "/api/v1/webhooks/" + created.ID, created.Token, http.StatusOK, ""},
+		{"get without token", http.MethodGet, "/api/v1/webhooks/" + created.ID, "", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"deliveries", http.MethodGet, "/api/v1/webhooks/" + created.ID + "/deliveries", created.Token, http.StatusOK, "[]\n"},
+		{"deliveries with wrong token", http.MethodGet, "/api/v1/webhooks/" + created.ID + "/deliveries", "wrong", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"test with wrong token", http.MethodPost, "/api/v1/webhooks/" + created.ID + "/test", "wrong", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"delete with wrong token", http.MethodDelete, "/api/v1/webhooks/" + created.ID, "wrong", http.StatusUnauthorized, "Invalid or missing token\n"},
+		{"unknown webhook", http.MethodGet, "/api/v1/webhooks/unknown", created.Token, http.StatusNotFound, "Webhook not found\n"},
+		{"test unknown webhook", http.MethodPost, "/api/v1/webhooks/unknown/test", created.Token, http.StatusNotFound, "Webhook not found\n"},
+		{"unknown action", http.MethodGet, "/api/v1/webhooks/" + created.ID + "/retry", created.Token, http.StatusNotFound, "404 page not found\n"},
+		{"wrong method", http.MethodGet, "/api/v1/webhooks/" + created.ID + "/test", created.Token, http.StatusMethodNotAllowed, "Method not allowed\n"},
+		{"delete", http.MethodDelete, "/api/v1/webhooks/" + created.ID, created.Token, http.StatusNoContent, ""},
+		{"deleted", http.MethodGet, "/api/v1/webhooks/" + created.ID, created.Token, http.StatusNotFound, "Webhook not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := webhookRequest(handler, tt.method, tt.path, tt.token, "")
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedBody != "" && rr.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
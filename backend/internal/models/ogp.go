@@ -4,6 +4,53 @@ import "time"
 
 type OGPRequest struct {
 	URL string `json:"url" validate:"required,url"`
+	VerifyOptions
+}
+
+// VerifyHTMLRequest checks a document that is not published yet. BaseURL
+// resolves relative URLs in it; ProbeImages downloads the images and icons
+// it refers to.
+type VerifyHTMLRequest struct {
+	HTML        string `json:"html"`
+	BaseURL     string `json:"base_url,omitempty"`
+	ProbeImages bool   `json:"probe_images,omitempty"`
+	VerifyOptions
+}
+
+const (
+	TruncationModeChars = "chars"
+	TruncationModeWidth = "width"
+)
+
+const (
+	PreviewLayoutLargeImage = "large_image"
+	PreviewLayoutThumbnail  = "thumbnail"
+	PreviewLayoutNone       = "none"
+)
+
+// VerifyOptions are the per-request knobs shared by every verification entry point.
+type VerifyOptions struct {
+	// TruncationMode selects whether preview titles and descriptions are cut
+	// by character count (default) or by predicted rendered width.
+	TruncationMode string `json:"truncation_mode,omitempty"`
+	// Rules disables validation rules or overrides their severity for this request.
+	Rules RuleConfig `json:"rules,omitempty"`
+	// MinScore is the quality score the page must reach for Score.Passed.
+	MinScore int `json:"min_score,omitempty"`
+	// Lang selects the language of issue messages ("en" or "ja"). The HTTP
+	// API falls back to Accept-Language when it is empty.
+	Lang string `json:"lang,omitempty"`
+	// CheckAlternates fetches and verifies the page for each og:locale:alternate.
+	CheckAlternates bool `json:"check_alternates,omitempty"`
+}
+
+// RuleConfig selects which validation rules run and at what severity. Rules
+// are identified by ID, which for built-in rules is the issue code. Enabled
+// turns back on a rule that the service configuration disabled.
+type RuleConfig struct {
+	Enabled  []string          `json:"enabled,omitempty"`
+	Disabled []string          `json:"disabled,omitempty"`
+	Severity map[string]string `json:"severity,omitempty"`
 }
 
 type OGPResponse struct {
@@ -11,7 +58,436 @@ type OGPResponse struct {
 	OGPData    OGPData          `json:"ogp_data"`
 	Validation ValidationResult `json:"validation"`
 	Previews   PlatformPreviews `json:"previews"`
+	ImageInfo  ImageMetadata    `json:"image_info"`
+	SEO        SEOResult        `json:"seo"`
+	Score      QualityScore     `json:"score"`
+	Lang       string           `json:"lang"`
+	Alternates []AlternateLocale `json:"alternates,omitempty"`
+	Icons      SiteIcons        `json:"icons"`
 	Timestamp  time.Time        `json:"timestamp"`
+	// Permalink is the path of the shareable report of this verification,
+	// such as "/r/Xk3v9QmB2pLw7tZa".
+	Permalink  string           `json:"permalink,omitempty"`
+}
+
+const (
+	VerifyStageFetched     = "fetched"
+	VerifyStageParsed      = "parsed"
+	VerifyStageImageProbed = "image_probed"
+	VerifyStageValidated   = "validated"
+	VerifyStageIcons       = "icons"
+	VerifyStagePreview     = "preview"
+	VerifyStageDone        = "done"
+	VerifyStageError       = "error"
+)
+
+// VerifyEvent reports one stage of a verification as it completes. Only the
+// field belonging to Stage is set; a preview event is sent per platform.
+type VerifyEvent struct {
+	Stage      string            `json:"stage"`
+	URL        string            `json:"url,omitempty"`
+	OGPData    *OGPData          `json:"ogp_data,omitempty"`
+	ImageInfo  *ImageMetadata    `json:"image_info,omitempty"`
+	Validation *ValidationResult `json:"validation,omitempty"`
+	SEO        *SEOResult        `json:"seo,omitempty"`
+	Icons      *SiteIcons        `json:"icons,omitempty"`
+	Preview    *PlatformPreview  `json:"preview,omitempty"`
+	Response   *OGPResponse      `json:"response,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// BatchRequest verifies several URLs with the same options.
+type BatchRequest struct {
+	URLs []string `json:"urls"`
+	VerifyOptions
+}
+
+// BatchResponse holds one result per requested URL, in request order.
+type BatchResponse struct {
+	Results   []BatchResult `json:"results"`
+	Summary   BatchSummary  `json:"summary"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// BatchResult is the verification of one URL. Exactly one of Response and
+// Error is set.
+type BatchResult struct {
+	URL      string       `json:"url"`
+	Response *OGPResponse `json:"response,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// BatchSummary aggregates the results of a batch. AverageScore and Passed
+// cover the URLs that could be verified.
+type BatchSummary struct {
+	Total        int            `json:"total"`
+	Succeeded    int            `json:"succeeded"`
+	Failed       int            `json:"failed"`
+	Valid        int            `json:"valid"`
+	Passed       int            `json:"passed"`
+	AverageScore float64        `json:"average_score"`
+	IssueCounts  map[string]int `json:"issue_counts"`
+}
+
+// CrawlRequest audits a site from its sitemap or by following same-host
+// links from StartURL. Exactly one of SitemapURL and StartURL is set.
+type CrawlRequest struct {
+	SitemapURL string `json:"sitemap_url,omitempty"`
+	StartURL   string `json:"start_url,omitempty"`
+	// MaxDepth is how many links away from StartURL pages are followed.
+	MaxDepth int `json:"max_depth,omitempty"`
+	MaxPages int `json:"max_pages,omitempty"`
+	VerifyOptions
+}
+
+// CrawlReport is the site-level result of a crawl.
+type CrawlReport struct {
+	// Source is "sitemap" or "links".
+	Source          string           `json:"source"`
+	Pages           []CrawlPage      `json:"pages"`
+	Summary         BatchSummary     `json:"summary"`
+	WorstPages      []CrawlPage      `json:"worst_pages"`
+	DuplicateTitles []DuplicateValue `json:"duplicate_titles"`
+	DuplicateImages []DuplicateValue `json:"duplicate_images"`
+	// Truncated is set when more pages were found than MaxPages.
+	Truncated bool `json:"truncated"`
+	// Errors lists sitemaps that could not be read.
+	Errors    []string  `json:"errors"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CrawlPage is the outcome of verifying one crawled page.
+type CrawlPage struct {
+	URL        string   `json:"url"`
+	Depth      int      `json:"depth"`
+	Title      string   `json:"title,omitempty"`
+	Image      string   `json:"image,omitempty"`
+	Score      int      `json:"score"`
+	IsValid    bool     `json:"is_valid"`
+	IssueCodes []string `json:"issue_codes"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// DuplicateValue is an og:title or og:image shared by several pages.
+type DuplicateValue struct {
+	Value string   `json:"value"`
+	URLs  []string `json:"urls"`
+}
+
+const (
+	JobTypeBatch = "batch"
+	JobTypeCrawl = "crawl"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// JobRequest queues a batch or crawl to run in the background. Type selects
+// which of Batch and Crawl is used.
+type JobRequest struct {
+	Type  string        `json:"type"`
+	Batch *BatchRequest `json:"batch,omitempty"`
+	Crawl *CrawlRequest `json:"crawl,omitempty"`
+}
+
+// Job is the state of a background verification. Results holds the URLs
+// verified so far, in completion order, until the job succeeds and Batch or
+// Crawl holds the full result.
+type Job struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	Status     string         `json:"status"`
+	Progress   JobProgress    `json:"progress"`
+	Results    []BatchResult  `json:"results,omitempty"`
+	Batch      *BatchResponse `json:"batch,omitempty"`
+	Crawl      *CrawlReport   `json:"crawl,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// JobProgress counts the URLs of a job. Total grows while a crawl discovers
+// pages.
+type JobProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+const (
+	WebhookEventJobCompleted      = "job.completed"
+	WebhookEventValidationChanged = "validation.changed"
+	WebhookEventMonitorRegressed  = "monitor.regressed"
+	WebhookEventTest              = "webhook.test"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookRequest registers a webhook. URLs limits validation.changed and
+// monitor.regressed to pages whose URL starts with one of them. A secret is generated when
+// Secret is empty.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	URLs   []string `json:"urls,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// Webhook is a registered webhook. Secret and Token are only returned when
+// the webhook is created; Token must be sent as a bearer token to read,
+// test or delete it.
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	URLs      []string  `json:"urls,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the body posted to a webhook. Job is sent without its
+// results; Summary aggregates them.
+type WebhookPayload struct {
+	ID        string            `json:"id"`
+	Event     string            `json:"event"`
+	Timestamp time.Time         `json:"timestamp"`
+	Job       *Job              `json:"job,omitempty"`
+	Summary   *BatchSummary     `json:"summary,omitempty"`
+	Change    *ValidationChange `json:"change,omitempty"`
+	Alert     *MonitorAlert     `json:"alert,omitempty"`
+}
+
+// ValidationChange reports a page whose validity or errors differ from the
+// previous time it was verified.
+type ValidationChange struct {
+	URL      string          `json:"url"`
+	Previous ValidationState `json:"previous"`
+	Current  ValidationState `json:"current"`
+	// Regressed is set when the page became invalid or gained an error.
+	Regressed bool `json:"regressed"`
+}
+
+// ValidationState is what is compared between verifications of a page.
+// ErrorCodes are the codes of its error-severity issues, sorted.
+type ValidationState struct {
+	IsValid    bool     `json:"is_valid"`
+	Score      int      `json:"score"`
+	ErrorCodes []string `json:"error_codes"`
+}
+
+// WebhookDelivery is one entry of a webhook's delivery log.
+type WebhookDelivery struct {
+	ID            string     `json:"id"`
+	WebhookID     string     `json:"webhook_id"`
+	Event         string     `json:"event"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+const (
+	RegressionFetchFailed  = "fetch_failed"
+	RegressionScoreDropped = "score_dropped"
+	RegressionErrorsAdded  = "errors_added"
+	RegressionImageChanged = "image_changed"
+	RegressionImageBroken  = "image_broken"
+)
+
+// MonitorRequest registers a URL to be verified on a schedule.
+type MonitorRequest struct {
+	URL string `json:"url"`
+	// IntervalMinutes is how often the page is verified.
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+	VerifyOptions
+}
+
+// Monitor is a URL verified on a schedule. LastCheck is nil until the first
+// check, which runs when the monitor is registered. Token is only returned
+// when the monitor is created; it must be sent as a bearer token to read or
+// delete the monitor.
+type Monitor struct {
+	ID              string        `json:"id"`
+	URL             string        `json:"url"`
+	IntervalMinutes int           `json:"interval_minutes"`
+	Options         VerifyOptions `json:"options"`
+	Token           string        `json:"token,omitempty"`
+	TokenHash       string        `json:"-"`
+	CreatedAt       time.Time     `json:"created_at"`
+	LastCheck       *MonitorCheck `json:"last_check,omitempty"`
+	NextCheckAt     *time.Time    `json:"next_check_at,omitempty"`
+}
+
+// MonitorCheck is the outcome of one scheduled verification. Error is set
+// when the page could not be fetched; the other fields are then empty.
+type MonitorCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	ValidationState
+	Image       string       `json:"image,omitempty"`
+	ImageOK     bool         `json:"image_ok"`
+	Error       string       `json:"error,omitempty"`
+	Regressions []Regression `json:"regressions,omitempty"`
+}
+
+// Regression is one way a check got worse than the check before it.
+type Regression struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// MonitorAlert is raised when a check finds regressions.
+type MonitorAlert struct {
+	MonitorID string       `json:"monitor_id"`
+	URL       string       `json:"url"`
+	Previous  MonitorCheck `json:"previous"`
+	Current   MonitorCheck `json:"current"`
+}
+
+// HistoryEntry is a stored verification of a URL. Response is only set
+// when a single entry is fetched.
+type HistoryEntry struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	ValidationState
+	Response *OGPResponse `json:"response,omitempty"`
+}
+
+// DiffRequest compares two verifications. The verification options apply
+// to targets verified live.
+type DiffRequest struct {
+	Before DiffTarget `json:"before"`
+	After  DiffTarget `json:"after"`
+	VerifyOptions
+}
+
+// DiffTarget is one side of a diff: a stored verification when HistoryID is
+// set, otherwise URL verified now.
+type DiffTarget struct {
+	HistoryID int64  `json:"history_id,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// VerificationDiff lists what changed between two verifications. Changes
+// are the differing response fields in path order; issues are compared by
+// code and property.
+type VerificationDiff struct {
+	Before        DiffSide          `json:"before"`
+	After         DiffSide          `json:"after"`
+	ScoreDelta    int               `json:"score_delta"`
+	Changes       []FieldChange     `json:"changes"`
+	IssuesAdded   []ValidationIssue `json:"issues_added"`
+	IssuesRemoved []ValidationIssue `json:"issues_removed"`
+}
+
+// DiffSide identifies a compared verification.
+type DiffSide struct {
+	URL        string    `json:"url"`
+	HistoryID  int64     `json:"history_id,omitempty"`
+	VerifiedAt time.Time `json:"verified_at"`
+	IsValid    bool      `json:"is_valid"`
+	Score      int       `json:"score"`
+	Permalink  string    `json:"permalink,omitempty"`
+}
+
+// FieldChange is a response field that differs, identified by its JSON
+// path such as "ogp_data.title" or "score.categories.1.score". A value is
+// null on the side where the field is absent.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Report is a verification stored under a short unguessable ID so that it
+// can be shared as a link. ExpiresAt is not set for reports kept forever.
+type Report struct {
+	ID        string       `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+	Response  *OGPResponse `json:"response"`
+}
+
+// SiteIcons describes the page's icons, web app manifest and theme colour.
+// Favicon and AppleTouchIcon are the URLs platforms are expected to use.
+type SiteIcons struct {
+	Icons          []IconMetadata    `json:"icons"`
+	Favicon        string            `json:"favicon"`
+	AppleTouchIcon string            `json:"apple_touch_icon"`
+	Manifest       *ManifestInfo     `json:"manifest,omitempty"`
+	ThemeColor     string            `json:"theme_color"`
+	Issues         []ValidationIssue `json:"issues"`
+}
+
+// IconMetadata is a discovered icon. Sizes and Type are as declared; the
+// remaining fields are measured from the downloaded file.
+type IconMetadata struct {
+	URL     string `json:"url"`
+	Rel     string `json:"rel"`
+	Source  string `json:"source"`
+	Sizes   string `json:"sizes,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Fetched bool   `json:"fetched"`
+	Format  string `json:"format,omitempty"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Size    int64  `json:"size"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ManifestInfo struct {
+	URL        string `json:"url"`
+	Fetched    bool   `json:"fetched"`
+	Name       string `json:"name,omitempty"`
+	ShortName  string `json:"short_name,omitempty"`
+	ThemeColor string `json:"theme_color,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// AlternateLocale is the verification of the page linked by hreflang for an
+// og:locale:alternate value.
+type AlternateLocale struct {
+	Locale        string            `json:"locale"`
+	URL           string            `json:"url,omitempty"`
+	Fetched       bool              `json:"fetched"`
+	OGPLocale     string            `json:"ogp_locale,omitempty"`
+	LocaleMatches bool              `json:"locale_matches"`
+	Title         string            `json:"title,omitempty"`
+	IsValid       bool              `json:"is_valid"`
+	Score         int               `json:"score"`
+	Issues        []ValidationIssue `json:"issues,omitempty"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// QualityScore is a weighted 0-100 rating of the page's sharing metadata,
+// broken down by category.
+type QualityScore struct {
+	Score      int             `json:"score"`
+	Categories []ScoreCategory `json:"categories"`
+	Threshold  int             `json:"threshold,omitempty"`
+	Passed     bool            `json:"passed"`
+}
+
+// ScoreCategory is one part of the quality score. Score is 0-100 and Weight
+// is the percentage it contributes to the total.
+type ScoreCategory struct {
+	Name   string `json:"name"`
+	Score  int    `json:"score"`
+	Weight int    `json:"weight"`
 }
 
 type OGPData struct {
@@ -24,13 +500,92 @@ type OGPData struct {
 	ImageWidth  string `json:"image_width"`
 	ImageHeight string `json:"image_height"`
 	ImageAlt    string `json:"image_alt"`
+	// TwitterImageAlt is twitter:image:alt, which X shows instead of og:image:alt.
+	TwitterImageAlt string `json:"twitter_image_alt"`
+
+	FBAppID          string   `json:"fb_app_id"`
+	Locale           string   `json:"locale"`
+	LocaleAlternates []string `json:"locale_alternates,omitempty"`
+
+	FediverseCreator string `json:"fediverse_creator"`
+	TelegramChannel  string `json:"telegram_channel"`
+	AppleTouchIcon   string `json:"apple_touch_icon"`
+
+	TwitterCard string `json:"twitter_card"`
+	Author      string `json:"author"`
+}
+
+// ImageMetadata describes the og:image as fetched by the service.
+type ImageMetadata struct {
+	URL         string `json:"url"`
+	Fetched     bool   `json:"fetched"`
+	ContentType string `json:"content_type"`
+	Format      string `json:"format"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Error       string `json:"error,omitempty"`
+	// Analysis is set when the image could be decoded for the accessibility checks.
+	Analysis *ImageAnalysis `json:"analysis,omitempty"`
+}
+
+// ImageAnalysis holds the pixel heuristics used by the accessibility checks.
+type ImageAnalysis struct {
+	// Contrast is the WCAG contrast ratio between the dark and light ends of
+	// the image's luminance range.
+	Contrast float64 `json:"contrast"`
+	// EdgeDensity is the share of sampled pixels on a sharp luminance edge.
+	// Text produces many such edges, photographs few.
+	EdgeDensity float64 `json:"edge_density"`
+	LikelyText  bool    `json:"likely_text"`
 }
 
 type ValidationResult struct {
 	IsValid  bool                `json:"is_valid"`
+	Issues   []ValidationIssue  `json:"issues"`
+	// Conflicts lists single-valued properties set more than once.
+	Conflicts []TagConflict     `json:"conflicts"`
+	// Warnings and Errors are the messages of Issues, kept for older clients.
 	Warnings []string           `json:"warnings"`
 	Errors   []string           `json:"errors"`
 	Checks   ValidationChecks   `json:"checks"`
+	// Accessibility holds the image accessibility issues, which are kept out
+	// of Issues.
+	Accessibility AccessibilityResult `json:"accessibility"`
+}
+
+type AccessibilityResult struct {
+	Issues         []ValidationIssue `json:"issues"`
+	HasImageAlt    bool              `json:"has_image_alt"`
+	ImageAltLength int               `json:"image_alt_length"`
+}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// ValidationIssue is a single finding with a stable code that clients can
+// match on instead of the English message.
+type ValidationIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Property string `json:"property,omitempty"`
+	Value    string `json:"value,omitempty"`
+	// Params are the values substituted into Message, for clients that
+	// render their own text from Code.
+	Params   []string `json:"params,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	DocsURL  string `json:"docs_url,omitempty"`
+}
+
+// TagConflict is a single-valued property repeated in the page.
+type TagConflict struct {
+	Property string   `json:"property"`
+	Values   []string `json:"values"`
 }
 
 type ValidationChecks struct {
@@ -45,6 +600,13 @@ type PlatformPreviews struct {
 	Twitter  PlatformPreview `json:"twitter"`
 	Facebook PlatformPreview `json:"facebook"`
 	Discord  PlatformPreview `json:"discord"`
+	Mastodon PlatformPreview `json:"mastodon"`
+	Misskey  PlatformPreview `json:"misskey"`
+	Bluesky  PlatformPreview `json:"bluesky"`
+	Telegram PlatformPreview `json:"telegram"`
+	WhatsApp PlatformPreview `json:"whatsapp"`
+	IMessage PlatformPreview `json:"imessage"`
+	Search   PlatformPreview `json:"search"`
 }
 
 type PlatformPreview struct {
@@ -58,4 +620,53 @@ type PlatformPreview struct {
 	DescLength   int    `json:"desc_length"`
 	MaxTitleLen  int    `json:"max_title_len"`
 	MaxDescLen   int    `json:"max_desc_len"`
+	Author       string `json:"author,omitempty"`
+	ImageSource  string `json:"image_source,omitempty"`
+	Hints        []string `json:"hints,omitempty"`
+	DisplayURL   string `json:"display_url,omitempty"`
+	Icon         string `json:"icon,omitempty"`
+	ThemeColor   string `json:"theme_color,omitempty"`
+	// Layout is how the platform places the image: large_image, thumbnail
+	// or none. Only set on the Discord preview.
+	Layout       string `json:"layout,omitempty"`
+	SiteName     string `json:"site_name,omitempty"`
+	// DebuggerWarnings are the issues the Facebook Sharing Debugger would
+	// also report. Only set on the Facebook preview.
+	DebuggerWarnings []string `json:"debugger_warnings,omitempty"`
+	// Issues are Warnings and Hints with their codes; Warnings are the
+	// messages of warning issues and Hints of info issues.
+	Issues []ValidationIssue `json:"issues,omitempty"`
+
+	TitlePixelWidth    float64 `json:"title_pixel_width,omitempty"`
+	MaxTitlePixelWidth float64 `json:"max_title_pixel_width,omitempty"`
+	DescPixelWidth     float64 `json:"desc_pixel_width,omitempty"`
+	MaxDescPixelWidth  float64 `json:"max_desc_pixel_width,omitempty"`
+
+	TitleWithinCharLimit bool   `json:"title_within_char_limit"`
+	DescWithinCharLimit  bool   `json:"desc_within_char_limit"`
+	TitleFitsWidth       bool   `json:"title_fits_width"`
+	DescFitsWidth        bool   `json:"desc_fits_width"`
+	TitleTruncateAt      int    `json:"title_truncate_at,omitempty"`
+	DescTruncateAt       int    `json:"desc_truncate_at,omitempty"`
+	DisplayTitle         string `json:"display_title"`
+	DisplayDescription   string `json:"display_description"`
+}
+
+// SEOResult holds the search-engine metadata of a page and the checks run on it.
+type SEOResult struct {
+	HTMLLang    string         `json:"html_lang"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Canonical   string         `json:"canonical"`
+	Robots      string         `json:"robots"`
+	Noindex     bool           `json:"noindex"`
+	Hreflangs   []HreflangLink `json:"hreflangs"`
+	Issues      []ValidationIssue `json:"issues"`
+	Warnings    []string       `json:"warnings"`
+	Errors      []string       `json:"errors"`
+}
+
+type HreflangLink struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
 }
\ No newline at end of file
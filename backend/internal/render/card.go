@@ -0,0 +1,432 @@
+// Package render draws approximate platform link cards as PNG images.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"ogp-verification-service/internal/models"
+)
+
+const cardPadding = 12
+
+// cardLayout describes how a platform arranges its link card.
+type cardLayout struct {
+	width int
+	// imageAspect is the width/height crop applied to the image; zero keeps
+	// the source aspect ratio.
+	imageAspect float64
+	// thumbnail places a square image on the left instead of a banner on top.
+	thumbnail bool
+	// accentBar draws a Discord-style coloured bar on the left edge.
+	accentBar bool
+	// metaFirst puts the site name or URL line above the title.
+	metaFirst bool
+	// authorRow draws the preview author between the meta line and the title.
+	authorRow  bool
+	titleSize  float64
+	descSize   float64
+	titleLines int
+	descLines  int
+	background color.RGBA
+	border     color.RGBA
+	titleColor color.RGBA
+	descColor  color.RGBA
+	metaColor  color.RGBA
+}
+
+var (
+	white     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	lightGray = color.RGBA{0xf0, 0xf2, 0xf5, 0xff}
+	gray      = color.RGBA{0xcf, 0xd9, 0xde, 0xff}
+	darkText  = color.RGBA{0x0f, 0x14, 0x19, 0xff}
+	mutedText = color.RGBA{0x53, 0x64, 0x71, 0xff}
+)
+
+var cardLayouts = map[string]cardLayout{
+	"twitter":  {width: 600, imageAspect: 1.91, titleSize: 15, descSize: 15, titleLines: 1, descLines: 2, background: white, border: gray, titleColor: darkText, descColor: mutedText, metaColor: mutedText},
+	"facebook": {width: 500, imageAspect: 1.91, titleSize: 16, descSize: 14, titleLines: 2, descLines: 1, background: lightGray, border: gray, titleColor: color.RGBA{0x1c, 0x1e, 0x21, 0xff}, descColor: color.RGBA{0x60, 0x67, 0x70, 0xff}, metaColor: color.RGBA{0x60, 0x67, 0x70, 0xff}},
+	"discord":  {width: 432, accentBar: true, metaFirst: true, authorRow: true, titleSize: 16, descSize: 14, titleLines: 2, descLines: 6, background: color.RGBA{0x2b, 0x2d, 0x31, 0xff}, border: color.RGBA{0x1e, 0x1f, 0x22, 0xff}, titleColor: color.RGBA{0x00, 0xa8, 0xfc, 0xff}, descColor: color.RGBA{0xdb, 0xde, 0xe1, 0xff}, metaColor: color.RGBA{0xb5, 0xba, 0xc1, 0xff}},
+	"mastodon": {width: 500, imageAspect: 1.91, titleSize: 15, descSize: 15, titleLines: 1, descLines: 2, background: color.RGBA{0x28, 0x2c, 0x37, 0xff}, border: color.RGBA{0x39, 0x3f, 0x4f, 0xff}, titleColor: white, descColor: color.RGBA{0x9b, 0xa3, 0xc8, 0xff}, metaColor: color.RGBA{0x9b, 0xa3, 0xc8, 0xff}},
+	"misskey":  {width: 400, thumbnail: true, titleSize: 14, descSize: 13, titleLines: 1, descLines: 2, background: white, border: gray, titleColor: darkText, descColor: mutedText, metaColor: mutedText},
+	"bluesky":  {width: 500, imageAspect: 1.91, titleSize: 15, descSize: 13, titleLines: 2, descLines: 2, background: white, border: gray, titleColor: darkText, descColor: mutedText, metaColor: mutedText},
+	"telegram": {width: 380, accentBar: true, metaFirst: true, titleSize: 15, descSize: 15, titleLines: 2, descLines: 6, background: white, border: white, titleColor: color.RGBA{0x16, 0x8a, 0xcd, 0xff}, descColor: darkText, metaColor: color.RGBA{0x16, 0x8a, 0xcd, 0xff}},
+	"whatsapp": {width: 330, thumbnail: true, titleSize: 15, descSize: 13, titleLines: 2, descLines: 1, background: color.RGBA{0xf0, 0xf0, 0xf0, 0xff}, border: color.RGBA{0xe0, 0xe0, 0xe0, 0xff}, titleColor: darkText, descColor: mutedText, metaColor: mutedText},
+	"imessage": {width: 280, imageAspect: 1.91, titleSize: 15, titleLines: 2, background: color.RGBA{0xe9, 0xe9, 0xeb, 0xff}, border: color.RGBA{0xe9, 0xe9, 0xeb, 0xff}, titleColor: darkText, descColor: mutedText, metaColor: mutedText},
+	"search":   {width: 600, metaFirst: true, titleSize: 20, descSize: 14, titleLines: 1, descLines: 2, background: white, border: white, titleColor: color.RGBA{0x1a, 0x0d, 0xab, 0xff}, descColor: color.RGBA{0x4d, 0x51, 0x56, 0xff}, metaColor: color.RGBA{0x20, 0x21, 0x24, 0xff}},
+}
+
+// CardRenderer lays out card text with the embedded Go fonts. Glyphs missing
+// from those fonts, such as Japanese, are drawn from an optional fallback font.
+type CardRenderer struct {
+	regular  *sfnt.Font
+	bold     *sfnt.Font
+	fallback *sfnt.Font
+}
+
+// NewCardRenderer panics if the embedded fonts cannot be parsed, which only
+// happens with a broken build.
+func NewCardRenderer() *CardRenderer {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(fmt.Sprintf("render: failed to parse regular font: %v", err))
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		panic(fmt.Sprintf("render: failed to parse bold font: %v", err))
+	}
+	return &CardRenderer{regular: regular, bold: bold}
+}
+
+// LoadFallbackFont reads a TrueType or OpenType font used for glyphs the
+// embedded fonts do not cover.
+func (r *CardRenderer) LoadFallbackFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read fallback font: %w", err)
+	}
+	fallback, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse fallback font: %w", err)
+	}
+	r.fallback = fallback
+	return nil
+}
+
+// Render draws the card for preview. meta is the domain or site name line and
+// img may be nil when the card has no image.
+func (r *CardRenderer) Render(preview models.PlatformPreview, meta string, img image.Image) (*image.RGBA, error) {
+	layout, ok := cardLayouts[preview.Platform]
+	if !ok {
+		return nil, fmt.Errorf("unsupported platform: %s", preview.Platform)
+	}
+	if preview.Platform == "search" {
+		img = nil
+	}
+
+	titleFace, err := r.newFaces(layout.titleSize, true)
+	if err != nil {
+		return nil, err
+	}
+	descFace, err := r.newFaces(layout.descSize, false)
+	if err != nil {
+		return nil, err
+	}
+	metaFace, err := r.newFaces(12, false)
+	if err != nil {
+		return nil, err
+	}
+	authorFace, err := r.newFaces(13, true)
+	if err != nil {
+		return nil, err
+	}
+
+	textLeft := cardPadding
+	if layout.accentBar {
+		textLeft += 4
+	}
+	thumbSize := 0
+	if img != nil && layout.thumbnail {
+		thumbSize = 88
+		textLeft += thumbSize
+	}
+	textWidth := layout.width - textLeft - cardPadding
+	// Discord puts small images in the top-right corner of the embed
+	sideThumbSize := 0
+	if img != nil && layout.accentBar && preview.Layout == models.PreviewLayoutThumbnail {
+		sideThumbSize = 80
+		textWidth -= sideThumbSize + cardPadding
+	}
+
+	metaLines := wrapText(metaFace, meta, textWidth, 1)
+	titleLines := wrapText(titleFace, preview.Title, textWidth, layout.titleLines)
+	descLines := wrapText(descFace, preview.Description, textWidth, layout.descLines)
+	authorLines := []string{}
+	if layout.authorRow && preview.Author != "" {
+		authorLines = wrapText(authorFace, preview.Author, textWidth, 1)
+	}
+
+	textHeight := len(metaLines)*metaFace.lineHeight() + len(authorLines)*authorFace.lineHeight() + len(titleLines)*titleFace.lineHeight() + len(descLines)*descFace.lineHeight()
+	textBlock := textHeight + 2*cardPadding
+	if thumbSize > 0 && textBlock < thumbSize {
+		textBlock = thumbSize
+	}
+	if sideThumbSize > 0 && textBlock < sideThumbSize+2*cardPadding {
+		textBlock = sideThumbSize + 2*cardPadding
+	}
+
+	imageRect := image.Rectangle{}
+	if img != nil && !layout.thumbnail && sideThumbSize == 0 {
+		imageRect = r.bannerRect(layout, img)
+	}
+
+	height := imageRect.Dy() + textBlock
+	if layout.accentBar && !imageRect.Empty() {
+		height += cardPadding
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, layout.width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(layout.background), image.Point{}, draw.Src)
+
+	textTop := cardPadding
+	switch {
+	case layout.accentBar:
+		// Discord and Telegram show the image below the text
+		imageRect = imageRect.Add(image.Pt(textLeft, textHeight+cardPadding))
+		accent := layout.titleColor
+		if c, ok := parseHexColor(preview.ThemeColor); ok {
+			accent = c
+		}
+		draw.Draw(canvas, image.Rect(0, 0, 4, height), image.NewUniform(accent), image.Point{}, draw.Src)
+		if sideThumbSize > 0 {
+			left := layout.width - cardPadding - sideThumbSize
+			drawCropped(canvas, image.Rect(left, cardPadding, left+sideThumbSize, cardPadding+sideThumbSize), img, 1)
+		}
+	case thumbSize > 0:
+		drawCropped(canvas, image.Rect(0, 0, thumbSize, thumbSize), img, 1)
+	default:
+		textTop += imageRect.Dy()
+	}
+	if !imageRect.Empty() {
+		drawCropped(canvas, imageRect, img, layout.imageAspect)
+	}
+
+	y := textTop
+	if layout.metaFirst {
+		y = r.drawLines(canvas, metaFace, metaLines, textLeft, y, layout.metaColor)
+	}
+	y = r.drawLines(canvas, authorFace, authorLines, textLeft, y, layout.descColor)
+	y = r.drawLines(canvas, titleFace, titleLines, textLeft, y, layout.titleColor)
+	y = r.drawLines(canvas, descFace, descLines, textLeft, y, layout.descColor)
+	if !layout.metaFirst {
+		r.drawLines(canvas, metaFace, metaLines, textLeft, y, layout.metaColor)
+	}
+
+	drawBorder(canvas, layout.border)
+	return canvas, nil
+}
+
+// RenderPNG renders the card and encodes it as PNG to w.
+func (r *CardRenderer) RenderPNG(w io.Writer, preview models.PlatformPreview, meta string, img image.Image) error {
+	canvas, err := r.Render(preview, meta, img)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, canvas)
+}
+
+func (r *CardRenderer) bannerRect(layout cardLayout, img image.Image) image.Rectangle {
+	width := layout.width
+	if layout.accentBar {
+		width = layout.width - 2*cardPadding - 4
+	}
+
+	aspect := layout.imageAspect
+	if aspect == 0 {
+		bounds := img.Bounds()
+		if bounds.Dy() == 0 {
+			return image.Rectangle{}
+		}
+		aspect = float64(bounds.Dx()) / float64(bounds.Dy())
+		if aspect < 0.75 {
+			aspect = 0.75
+		}
+	}
+	return image.Rect(0, 0, width, int(float64(width)/aspect))
+}
+
+func (r *CardRenderer) drawLines(dst *image.RGBA, faces *faceSet, lines []string, x, y int, c color.Color) int {
+	for _, line := range lines {
+		y += faces.lineHeight()
+		faces.draw(dst, line, x, y-faces.descent(), c)
+	}
+	return y
+}
+
+// parseHexColor reads a #rgb or #rrggbb colour.
+func parseHexColor(s string) (color.RGBA, bool) {
+	if !strings.HasPrefix(s, "#") {
+		return color.RGBA{}, false
+	}
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, true
+}
+
+// drawCropped centre-crops src to aspect (or keeps it when zero) and scales it into rect.
+func drawCropped(dst *image.RGBA, rect image.Rectangle, src image.Image, aspect float64) {
+	bounds := src.Bounds()
+	if aspect > 0 && bounds.Dy() > 0 {
+		current := float64(bounds.Dx()) / float64(bounds.Dy())
+		if current > aspect {
+			width := int(float64(bounds.Dy()) * aspect)
+			offset := (bounds.Dx() - width) / 2
+			bounds = image.Rect(bounds.Min.X+offset, bounds.Min.Y, bounds.Min.X+offset+width, bounds.Max.Y)
+		} else if current < aspect {
+			height := int(float64(bounds.Dx()) / aspect)
+			offset := (bounds.Dy() - height) / 2
+			bounds = image.Rect(bounds.Min.X, bounds.Min.Y+offset, bounds.Max.X, bounds.Min.Y+offset+height)
+		}
+	}
+	draw.CatmullRom.Scale(dst, rect, src, bounds, draw.Over, nil)
+}
+
+func drawBorder(dst *image.RGBA, c color.RGBA) {
+	b := dst.Bounds()
+	for x := b.Min.X; x < b.Max.X; x++ {
+		dst.SetRGBA(x, b.Min.Y, c)
+		dst.SetRGBA(x, b.Max.Y-1, c)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		dst.SetRGBA(b.Min.X, y, c)
+		dst.SetRGBA(b.Max.X-1, y, c)
+	}
+}
+
+// faceSet is a primary face plus an optional fallback for missing glyphs.
+type faceSet struct {
+	primaryFont *sfnt.Font
+	primary     font.Face
+	fallback    font.Face
+	fallbackFnt *sfnt.Font
+	buf         sfnt.Buffer
+}
+
+func (r *CardRenderer) newFaces(size float64, bold bool) (*faceSet, error) {
+	if size == 0 {
+		size = 13
+	}
+	primaryFont := r.regular
+	if bold {
+		primaryFont = r.bold
+	}
+
+	options := &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull}
+	primary, err := opentype.NewFace(primaryFont, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	faces := &faceSet{primaryFont: primaryFont, primary: primary}
+
+	if r.fallback != nil {
+		fallback, err := opentype.NewFace(r.fallback, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fallback font face: %w", err)
+		}
+		faces.fallback = fallback
+		faces.fallbackFnt = r.fallback
+	}
+	return faces, nil
+}
+
+func (f *faceSet) faceFor(r rune) font.Face {
+	if f.fallback == nil {
+		return f.primary
+	}
+	if idx, err := f.primaryFont.GlyphIndex(&f.buf, r); err == nil && idx != 0 {
+		return f.primary
+	}
+	if idx, err := f.fallbackFnt.GlyphIndex(&f.buf, r); err == nil && idx != 0 {
+		return f.fallback
+	}
+	return f.primary
+}
+
+func (f *faceSet) advance(r rune) fixed.Int26_6 {
+	adv, _ := f.faceFor(r).GlyphAdvance(r)
+	return adv
+}
+
+func (f *faceSet) measure(text string) int {
+	var width fixed.Int26_6
+	for _, r := range text {
+		width += f.advance(r)
+	}
+	return width.Ceil()
+}
+
+func (f *faceSet) lineHeight() int {
+	return int(float64(f.primary.Metrics().Height.Ceil()) * 1.25)
+}
+
+func (f *faceSet) descent() int {
+	return f.primary.Metrics().Descent.Ceil() + (f.lineHeight()-f.primary.Metrics().Height.Ceil())/2
+}
+
+func (f *faceSet) draw(dst *image.RGBA, text string, x, y int, c color.Color) {
+	dot := fixed.P(x, y)
+	src := image.NewUniform(c)
+	for _, r := range text {
+		d := font.Drawer{Dst: dst, Src: src, Face: f.faceFor(r), Dot: dot}
+		d.DrawString(string(r))
+		dot.X += f.advance(r)
+	}
+}
+
+// wrapText greedily wraps text into at most maxLines lines of width pixels,
+// breaking at spaces and between CJK characters, and ends a clamped last line
+// with an ellipsis.
+func wrapText(faces *faceSet, text string, width, maxLines int) []string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" || maxLines == 0 {
+		return nil
+	}
+
+	var lines []string
+	var line []rune
+	lineBreak := -1
+	for _, r := range text {
+		line = append(line, r)
+		if faces.measure(string(line)) <= width {
+			if r == ' ' || r >= 0x2E80 {
+				lineBreak = len(line)
+			}
+			continue
+		}
+
+		if len(lines) == maxLines-1 {
+			return append(lines, ellipsize(faces, line[:len(line)-1], width))
+		}
+
+		cut := len(line) - 1
+		if r != ' ' && r < 0x2E80 && lineBreak > 0 {
+			cut = lineBreak
+		}
+		lines = append(lines, strings.TrimRight(string(line[:cut]), " "))
+		line = []rune(strings.TrimLeft(string(line[cut:]), " "))
+		lineBreak = -1
+	}
+
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+func ellipsize(faces *faceSet, line []rune, width int) string {
+	for len(line) > 0 && faces.measure(string(line)+"...") > width {
+		line = line[:len(line)-1]
+	}
+	return strings.TrimRight(string(line), " ") + "..."
+}
@@ -0,0 +1,139 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+var cardPlatforms = []string{"twitter", "facebook", "discord", "mastodon", "misskey", "bluesky", "telegram", "whatsapp", "imessage", "search"}
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0x80, 0xff})
+		}
+	}
+	return img
+}
+
+func TestCardRenderer_Render(t *testing.T) {
+	renderer := NewCardRenderer()
+	img := testImage(1200, 630)
+
+	for _, platform := range cardPlatforms {
+		t.Run(platform, func(t *testing.T) {
+			preview := models.PlatformPreview{
+				Platform:    platform,
+				Title:       "A reasonably long title that should wrap onto a second line on narrow cards",
+				Description: "Description text for the card preview.",
+			}
+
+			withImage, err := renderer.Render(preview, "EXAMPLE.COM", img)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			withoutImage, err := renderer.Render(preview, "EXAMPLE.COM", nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if withImage.Bounds().Dx() != cardLayouts[platform].width {
+				t.Errorf("Expected width %d, got %d", cardLayouts[platform].width, withImage.Bounds().Dx())
+			}
+			if platform != "search" && withImage.Bounds().Dy() <= withoutImage.Bounds().Dy() && !cardLayouts[platform].thumbnail {
+				t.Errorf("Expected image to add height, got %d and %d", withImage.Bounds().Dy(), withoutImage.Bounds().Dy())
+			}
+		})
+	}
+}
+
+func TestCardRenderer_RenderUnknownPlatform(t *testing.T) {
+	renderer := NewCardRenderer()
+
+	if _, err := renderer.Render(models.PlatformPreview{Platform: "myspace"}, "", nil); err == nil {
+		t.Error("Expected error for unknown platform")
+	}
+}
+
+func TestCardRenderer_RenderPNG(t *testing.T) {
+	renderer := NewCardRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderPNG(&buf, models.PlatformPreview{Platform: "twitter", Title: "Title"}, "EXAMPLE.COM", testImage(800, 800))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Expected valid PNG: %v", err)
+	}
+	// A square image is cropped to 1.91:1
+	if decoded.Bounds().Dy() >= 600 {
+		t.Errorf("Expected cropped banner, got height %d", decoded.Bounds().Dy())
+	}
+}
+
+func TestCardRenderer_RenderDiscordEmbed(t *testing.T) {
+	renderer := NewCardRenderer()
+	img := testImage(1200, 630)
+	preview := models.PlatformPreview{
+		Platform:   "discord",
+		Title:      "Title",
+		Author:     "Jane Doe",
+		ThemeColor: "#ff0000",
+		Layout:     models.PreviewLayoutLargeImage,
+	}
+
+	large, err := renderer.Render(preview, "Example", img)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := large.RGBAAt(1, large.Bounds().Dy()/2); got != (color.RGBA{0xff, 0x00, 0x00, 0xff}) {
+		t.Errorf("Expected theme-color side bar, got %v", got)
+	}
+
+	preview.Layout = models.PreviewLayoutThumbnail
+	thumbnail, err := renderer.Render(preview, "Example", img)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if thumbnail.Bounds().Dy() >= large.Bounds().Dy() {
+		t.Errorf("Expected thumbnail embed to be shorter than large image, got %d and %d", thumbnail.Bounds().Dy(), large.Bounds().Dy())
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	renderer := NewCardRenderer()
+	faces, err := renderer.newFaces(14, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := wrapText(faces, strings.Repeat("word ", 50), 200, 2)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[1], "...") {
+		t.Errorf("Expected ellipsis on last line, got %q", lines[1])
+	}
+	for _, line := range lines {
+		if faces.measure(line) > 200 {
+			t.Errorf("Line %q exceeds width", line)
+		}
+	}
+
+	if lines := wrapText(faces, "short", 200, 2); len(lines) != 1 || lines[0] != "short" {
+		t.Errorf("Expected single short line, got %q", lines)
+	}
+	if lines := wrapText(faces, "", 200, 2); lines != nil {
+		t.Errorf("Expected no lines for empty text, got %q", lines)
+	}
+}
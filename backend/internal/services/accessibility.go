@@ -0,0 +1,182 @@
+package services
+
+import (
+	"image"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// maxImageAltLength is where X cuts image descriptions.
+	maxImageAltLength = 420
+	// minTextContrast is the WCAG AA contrast ratio for normal text.
+	minTextContrast = 4.5
+
+	// analysisSampleSize is the longest edge of the grid sampled from the image.
+	analysisSampleSize = 256
+	// maxAnalyzedPixels skips decoding images too large to analyse cheaply.
+	// 4 megapixels decode to about 16MB and leave ample room above the
+	// 1200x630 recommended for og:image.
+	maxAnalyzedPixels = 4_000_000
+	// edgeThreshold is the luma difference between neighbouring samples that
+	// counts as a sharp edge, and textEdgeDensity the share of such samples
+	// above which the image is assumed to contain text.
+	edgeThreshold   = 0.3
+	textEdgeDensity = 0.08
+)
+
+// placeholderAlts are alt texts that name the image rather than describe it.
+var placeholderAlts = map[string]bool{
+	"alt": true, "alt text": true, "image": true, "img": true, "photo": true,
+	"picture": true, "pic": true, "thumbnail": true, "banner": true, "cover": true,
+	"screenshot": true, "placeholder": true, "untitled": true, "og image": true,
+	"ogp": true, "ogp image": true, "social image": true, "share image": true,
+	"featured image": true, "hero image": true, "画像": true, "写真": true,
+}
+
+var imageFileNameRegex = regexp.MustCompile(`(?i)^[\w\-. ]+\.(png|jpe?g|gif|webp|avif|svg)$`)
+
+func (s *OGPService) accessibilityRules() []Rule {
+	return []Rule{
+		NewRule("A11Y_IMAGE_ALT_MISSING", func(input RuleInput) []models.ValidationIssue {
+			if input.OGPData.Image == "" || strings.TrimSpace(input.OGPData.ImageAlt) != "" {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("A11Y_IMAGE_ALT_MISSING", "og:image:alt", "")}
+		}),
+		NewRule("A11Y_IMAGE_ALT_PLACEHOLDER", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			for _, alt := range s.imageAlts(input.OGPData) {
+				if s.isPlaceholderAlt(alt.value) {
+					issues = append(issues, s.newIssue("A11Y_IMAGE_ALT_PLACEHOLDER", alt.property, alt.value, alt.property, alt.value))
+				}
+			}
+			return issues
+		}),
+		NewRule("A11Y_IMAGE_ALT_TOO_LONG", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			for _, alt := range s.imageAlts(input.OGPData) {
+				if length := utf8.RuneCountInString(alt.value); length > maxImageAltLength {
+					issues = append(issues, s.newIssue("A11Y_IMAGE_ALT_TOO_LONG", alt.property, alt.value, alt.property, strconv.Itoa(length)))
+				}
+			}
+			return issues
+		}),
+		NewRule("A11Y_TWITTER_ALT_MISMATCH", func(input RuleInput) []models.ValidationIssue {
+			ogAlt := strings.TrimSpace(input.OGPData.ImageAlt)
+			twitterAlt := strings.TrimSpace(input.OGPData.TwitterImageAlt)
+			if ogAlt == "" || twitterAlt == "" || ogAlt == twitterAlt {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("A11Y_TWITTER_ALT_MISMATCH", "twitter:image:alt", twitterAlt, twitterAlt, ogAlt)}
+		}),
+		NewRule("A11Y_IMAGE_TEXT", func(input RuleInput) []models.ValidationIssue {
+			analysis := input.ImageInfo.Analysis
+			if analysis == nil || !analysis.LikelyText {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("A11Y_IMAGE_TEXT", "og:image", input.OGPData.Image)}
+		}),
+		NewRule("A11Y_IMAGE_LOW_CONTRAST", func(input RuleInput) []models.ValidationIssue {
+			analysis := input.ImageInfo.Analysis
+			if analysis == nil || !analysis.LikelyText || analysis.Contrast >= minTextContrast {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("A11Y_IMAGE_LOW_CONTRAST", "og:image", input.OGPData.Image, strconv.FormatFloat(analysis.Contrast, 'f', 1, 64))}
+		}),
+	}
+}
+
+type imageAlt struct {
+	property string
+	value    string
+}
+
+func (s *OGPService) imageAlts(ogpData models.OGPData) []imageAlt {
+	alts := []imageAlt{}
+	if alt := strings.TrimSpace(ogpData.ImageAlt); alt != "" {
+		alts = append(alts, imageAlt{"og:image:alt", alt})
+	}
+	if alt := strings.TrimSpace(ogpData.TwitterImageAlt); alt != "" {
+		alts = append(alts, imageAlt{"twitter:image:alt", alt})
+	}
+	return alts
+}
+
+func (s *OGPService) isPlaceholderAlt(alt string) bool {
+	if imageFileNameRegex.MatchString(alt) {
+		return true
+	}
+	normalized := strings.Join(strings.FieldsFunc(strings.ToLower(alt), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+	return placeholderAlts[normalized]
+}
+
+// isAccessibilityIssue reports whether code belongs in the accessibility
+// section of the validation result.
+func (s *OGPService) isAccessibilityIssue(code string) bool {
+	return strings.HasPrefix(code, "A11Y_")
+}
+
+// analyzeImage samples the image on a coarse grid and estimates its contrast
+// and whether it contains text. It is a heuristic: detailed photographs can
+// look like text and large, soft lettering may not.
+func (s *OGPService) analyzeImage(img image.Image) models.ImageAnalysis {
+	bounds := img.Bounds()
+	step := max(1, max(bounds.Dx(), bounds.Dy())/analysisSampleSize)
+	cols, rows := (bounds.Dx()+step-1)/step, (bounds.Dy()+step-1)/step
+	if cols == 0 || rows == 0 {
+		return models.ImageAnalysis{}
+	}
+
+	luma := make([]float64, cols*rows)
+	luminance := make([]float64, 0, cols*rows)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			r, g, b, _ := img.At(bounds.Min.X+col*step, bounds.Min.Y+row*step).RGBA()
+			rs, gs, bs := float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff
+			luma[row*cols+col] = 0.2126*rs + 0.7152*gs + 0.0722*bs
+			luminance = append(luminance, 0.2126*s.linearize(rs)+0.7152*s.linearize(gs)+0.0722*s.linearize(bs))
+		}
+	}
+
+	edges := 0
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			value := luma[row*cols+col]
+			if (col+1 < cols && math.Abs(value-luma[row*cols+col+1]) > edgeThreshold) ||
+				(row+1 < rows && math.Abs(value-luma[(row+1)*cols+col]) > edgeThreshold) {
+				edges++
+			}
+		}
+	}
+
+	// Percentiles rather than extremes keep a few stray pixels from
+	// dominating the contrast
+	sort.Float64s(luminance)
+	dark := luminance[len(luminance)*5/100]
+	light := luminance[min(len(luminance)-1, len(luminance)*95/100)]
+
+	analysis := models.ImageAnalysis{
+		Contrast:    math.Round((light+0.05)/(dark+0.05)*100) / 100,
+		EdgeDensity: math.Round(float64(edges)/float64(len(luma))*1000) / 1000,
+	}
+	analysis.LikelyText = analysis.EdgeDensity >= textEdgeDensity
+	return analysis
+}
+
+// linearize converts an sRGB channel to linear light for WCAG luminance.
+func (s *OGPService) linearize(channel float64) float64 {
+	if channel <= 0.03928 {
+		return channel / 12.92
+	}
+	return math.Pow((channel+0.055)/1.055, 2.4)
+}
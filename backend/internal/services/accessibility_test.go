@@ -0,0 +1,139 @@
+package services
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+// stripedImage draws vertical stripes two pixels wide, which the analysis
+// reads as text.
+func stripedImage(width, height int, dark, light color.Gray) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x/2)%2 == 0 {
+				img.SetGray(x, y, dark)
+			} else {
+				img.SetGray(x, y, light)
+			}
+		}
+	}
+	return img
+}
+
+func TestOGPService_accessibilityRules(t *testing.T) {
+	service := NewOGPService()
+	described := models.OGPData{Image: "https://example.com/image.png", ImageAlt: "A chart of monthly sales rising through 2024"}
+
+	tests := []struct {
+		name     string
+		data     func(models.OGPData) models.OGPData
+		analysis *models.ImageAnalysis
+		expected []string
+	}{
+		{
+			name:     "described photo",
+			data:     func(d models.OGPData) models.OGPData { return d },
+			analysis: &models.ImageAnalysis{Contrast: 12, EdgeDensity: 0.01},
+			expected: []string{},
+		},
+		{
+			name: "missing alt",
+			data: func(d models.OGPData) models.OGPData {
+				d.ImageAlt = " "
+				return d
+			},
+			expected: []string{"A11Y_IMAGE_ALT_MISSING"},
+		},
+		{
+			name: "placeholder alts",
+			data: func(d models.OGPData) models.OGPData {
+				d.ImageAlt, d.TwitterImageAlt = "OG Image", "hero_banner-1200x630.png"
+				return d
+			},
+			expected: []string{"A11Y_IMAGE_ALT_PLACEHOLDER", "A11Y_IMAGE_ALT_PLACEHOLDER", "A11Y_TWITTER_ALT_MISMATCH"},
+		},
+		{
+			name: "alt too long",
+			data: func(d models.OGPData) models.OGPData {
+				d.ImageAlt = strings.Repeat("あ", 421)
+				return d
+			},
+			expected: []string{"A11Y_IMAGE_ALT_TOO_LONG"},
+		},
+		{
+			name:     "low contrast text",
+			data:     func(d models.OGPData) models.OGPData { return d },
+			analysis: &models.ImageAnalysis{Contrast: 2.3, EdgeDensity: 0.2, LikelyText: true},
+			expected: []string{"A11Y_IMAGE_LOW_CONTRAST", "A11Y_IMAGE_TEXT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := RuleInput{OGPData: tt.data(described), ImageInfo: models.ImageMetadata{Analysis: tt.analysis}}
+			codes := []string{}
+			for _, issue := range service.runRules(input, models.RuleConfig{}) {
+				if service.isAccessibilityIssue(issue.Code) {
+					codes = append(codes, issue.Code)
+				}
+			}
+			sort.Strings(codes)
+			if !reflect.DeepEqual(codes, tt.expected) {
+				t.Errorf("codes = %v, want %v", codes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOGPService_validateOGPDataAccessibility(t *testing.T) {
+	service := NewOGPService()
+	input := RuleInput{OGPData: models.OGPData{Title: "Title", Image: "https://example.com/image.png"}}
+
+	result := service.validateOGPData(input, models.RuleConfig{})
+	for _, issue := range result.Issues {
+		if service.isAccessibilityIssue(issue.Code) {
+			t.Errorf("Expected %s only in the accessibility section", issue.Code)
+		}
+	}
+	if len(result.Accessibility.Issues) != 1 || result.Accessibility.Issues[0].Code != "A11Y_IMAGE_ALT_MISSING" {
+		t.Errorf("Expected missing alt in accessibility, got %+v", result.Accessibility.Issues)
+	}
+	if result.Accessibility.HasImageAlt {
+		t.Error("Expected HasImageAlt to be false")
+	}
+}
+
+func TestOGPService_analyzeImage(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name       string
+		img        image.Image
+		likelyText bool
+		minRatio   float64
+		maxRatio   float64
+	}{
+		{"black on white text", stripedImage(400, 200, color.Gray{0x00}, color.Gray{0xff}), true, 20, 21},
+		{"grey on grey text", stripedImage(400, 200, color.Gray{0x60}, color.Gray{0xb0}), true, 2, 4.5},
+		{"flat colour", image.NewGray(image.Rect(0, 0, 1200, 630)), false, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := service.analyzeImage(tt.img)
+			if analysis.LikelyText != tt.likelyText {
+				t.Errorf("LikelyText = %v (edge density %v), want %v", analysis.LikelyText, analysis.EdgeDensity, tt.likelyText)
+			}
+			if analysis.Contrast < tt.minRatio || analysis.Contrast > tt.maxRatio {
+				t.Errorf("Contrast = %v, want between %v and %v", analysis.Contrast, tt.minRatio, tt.maxRatio)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// MaxBatchURLs is the most URLs accepted in one batch.
+	MaxBatchURLs = 100
+	// batchWorkers bounds how many URLs of a batch are verified at once.
+	batchWorkers = 8
+	// maxRequestsPerHost keeps a batch from hammering a single site.
+	maxRequestsPerHost = 2
+)
+
+// hostLimiter hands out a fixed number of slots per host.
+type hostLimiter struct {
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+	limit int
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{slots: map[string]chan struct{}{}, limit: limit}
+}
+
+// acquire blocks until host has a free slot and returns the function that
+// releases it.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	slot, ok := l.slots[host]
+	if !ok {
+		slot = make(chan struct{}, l.limit)
+		l.slots[host] = slot
+	}
+	l.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ProgressFunc is called each time a URL of a batch or crawl has been
+// verified, with the number of URLs known so far. It may be called from
+// several goroutines at once.
+type ProgressFunc func(result models.BatchResult, total int)
+
+// ValidateBatch checks the URL count and options of a batch.
+func (s *OGPService) ValidateBatch(urls []string, opts models.VerifyOptions) error {
+	if len(urls) == 0 {
+		return fmt.Errorf("urls is required")
+	}
+	if len(urls) > MaxBatchURLs {
+		return fmt.Errorf("at most %d URLs are allowed per batch", MaxBatchURLs)
+	}
+	return s.ValidateOptions(opts)
+}
+
+// VerifyBatch verifies each URL with opts using a bounded pool of workers and
+// at most maxRequestsPerHost concurrent verifications per host. A URL that
+// fails is reported in its result; only invalid input fails the whole batch.
+func (s *OGPService) VerifyBatch(ctx context.Context, urls []string, opts models.VerifyOptions) (*models.BatchResponse, error) {
+	return s.VerifyBatchWithProgress(ctx, urls, opts, nil)
+}
+
+// VerifyBatchWithProgress is VerifyBatch reporting each result to progress,
+// in completion order, as soon as it is ready.
+func (s *OGPService) VerifyBatchWithProgress(ctx context.Context, urls []string, opts models.VerifyOptions, progress ProgressFunc) (*models.BatchResponse, error) {
+	if err := s.ValidateBatch(urls, opts); err != nil {
+		return nil, err
+	}
+	// Alternates would multiply the requests of every page
+	opts.CheckAlternates = false
+
+	results := make([]models.BatchResult, len(urls))
+	s.forEachURL(ctx, urls, func(i int, err error) {
+		results[i] = models.BatchResult{URL: urls[i]}
+		if err == nil {
+			results[i].Response, err = s.FetchOGPDataWithOptions(ctx, urls[i], opts)
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+		if progress != nil {
+			progress(results[i], len(urls))
+		}
+	})
+
+	return &models.BatchResponse{
+		Results:   results,
+		Summary:   s.summarizeBatch(results),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// forEachURL calls fn for every URL from a bounded pool of workers, holding
+// one of the URL's host slots while fn runs. fn receives an error instead
+// when the URL cannot be parsed or ctx ends before a slot is free.
+func (s *OGPService) forEachURL(ctx context.Context, urls []string, fn func(i int, err error)) {
+	hosts := newHostLimiter(maxRequestsPerHost)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(batchWorkers, len(urls)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				parsed, err := url.Parse(urls[i])
+				if err != nil {
+					fn(i, fmt.Errorf("invalid URL: %w", err))
+					continue
+				}
+				release, err := hosts.acquire(ctx, parsed.Hostname())
+				if err != nil {
+					fn(i, err)
+					continue
+				}
+				fn(i, nil)
+				release()
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func (s *OGPService) summarizeBatch(results []models.BatchResult) models.BatchSummary {
+	summary := models.BatchSummary{
+		Total:       len(results),
+		IssueCounts: map[string]int{},
+	}
+
+	totalScore := 0
+	for _, result := range results {
+		if result.Response == nil {
+			summary.Failed++
+			continue
+		}
+		summary.Succeeded++
+
+		response := result.Response
+		if response.Validation.IsValid {
+			summary.Valid++
+		}
+		if response.Score.Passed {
+			summary.Passed++
+		}
+		totalScore += response.Score.Score
+		for _, issues := range [][]models.ValidationIssue{response.Validation.Issues, response.Validation.Accessibility.Issues} {
+			for _, issue := range issues {
+				summary.IssueCounts[issue.Code]++
+			}
+		}
+	}
+
+	if summary.Succeeded > 0 {
+		summary.AverageScore = math.Round(float64(totalScore)/float64(summary.Succeeded)*10) / 10
+	}
+	return summary
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_VerifyBatch(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := map[string]int{}, map[string]int{}
+
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight[r.Host]++
+		maxInFlight[r.Host] = max(maxInFlight[r.Host], inFlight[r.Host])
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight[r.Host]--
+			mu.Unlock()
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `<html><head><title>%s</title><meta property="og:title" content="%s" /></head></html>`, r.URL.Path, r.URL.Path)
+	}))
+
+	urls := []string{}
+	for i := 0; i < 6; i++ {
+		urls = append(urls, fmt.Sprintf("http://a.example.test/page-%d", i), fmt.Sprintf("http://b.example.test/page-%d", i))
+	}
+	urls = append(urls, "http://a.example.test/missing")
+
+	response, err := service.VerifyBatch(context.Background(), urls, models.VerifyOptions{MinScore: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(response.Results) != len(urls) {
+		t.Fatalf("Expected %d results, got %d", len(urls), len(response.Results))
+	}
+	for i, result := range response.Results {
+		if result.URL != urls[i] {
+			t.Errorf("Result %d is for %s, want %s", i, result.URL, urls[i])
+		}
+	}
+	last := response.Results[len(urls)-1]
+	if last.Response != nil || !strings.Contains(last.Error, "404") {
+		t.Errorf("Expected the missing page to fail, got %+v", last)
+	}
+	if title := response.Results[0].Response.OGPData.Title; title != "/page-0" {
+		t.Errorf("Expected the first page's title, got %q", title)
+	}
+
+	summary := response.Summary
+	if summary.Total != 13 || summary.Succeeded != 12 || summary.Failed != 1 || summary.Passed != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.IssueCounts["OG_IMAGE_MISSING"] != 12 {
+		t.Errorf("Expected OG_IMAGE_MISSING on every page, got %v", summary.IssueCounts)
+	}
+	if summary.AverageScore <= 0 {
+		t.Errorf("Expected an average score, got %v", summary.AverageScore)
+	}
+
+	for host, n := range maxInFlight {
+		if n > maxRequestsPerHost {
+			t.Errorf("Expected at most %d concurrent requests to %s, got %d", maxRequestsPerHost, host, n)
+		}
+	}
+}
+
+func TestOGPService_VerifyBatchInvalid(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name string
+		urls []string
+		opts models.VerifyOptions
+	}{
+		{"no URLs", nil, models.VerifyOptions{}},
+		{"too many URLs", make([]string, MaxBatchURLs+1), models.VerifyOptions{}},
+		{"invalid options", []string{"https://example.com"}, models.VerifyOptions{Lang: "fr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.VerifyBatch(context.Background(), tt.urls, tt.opts); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"ogp-verification-service/internal/models"
+)
+
+// singleValuedProperties may appear only once. Repeating og:image and its
+// structured properties is allowed, so they are not listed.
+var singleValuedProperties = map[string]bool{
+	"og:title":            true,
+	"og:description":      true,
+	"og:url":              true,
+	"og:type":             true,
+	"og:site_name":        true,
+	"og:locale":           true,
+	"twitter:card":        true,
+	"twitter:title":       true,
+	"twitter:description": true,
+	"twitter:image":       true,
+	"twitter:site":        true,
+	"twitter:creator":     true,
+}
+
+func (s *OGPService) isSharingProperty(key string) bool {
+	return strings.HasPrefix(key, "og:") || strings.HasPrefix(key, "twitter:")
+}
+
+// isConflictTag reports whether tag sets a single-valued property as
+// platforms read it: og: properties from property=, and twitter: properties,
+// which X reads from either attribute, usually name=.
+func (s *OGPService) isConflictTag(tag sourceTag) bool {
+	if !singleValuedProperties[tag.key] {
+		return false
+	}
+	return tag.attr == "property" || (tag.attr == "name" && strings.HasPrefix(tag.key, "twitter:"))
+}
+
+// findTagConflicts groups repeated single-valued properties with their
+// values in document order.
+func (s *OGPService) findTagConflicts(tags []sourceTag) []models.TagConflict {
+	conflicts := []models.TagConflict{}
+	index := map[string]int{}
+	for _, tag := range tags {
+		if !s.isConflictTag(tag) {
+			continue
+		}
+		i, ok := index[tag.key]
+		if !ok {
+			i = len(conflicts)
+			index[tag.key] = i
+			conflicts = append(conflicts, models.TagConflict{Property: tag.key})
+		}
+		conflicts[i].Values = append(conflicts[i].Values, tag.value)
+	}
+
+	duplicates := []models.TagConflict{}
+	for _, conflict := range conflicts {
+		if len(conflict.Values) >= 2 {
+			duplicates = append(duplicates, conflict)
+		}
+	}
+	return duplicates
+}
+
+func (s *OGPService) tagRules() []Rule {
+	return []Rule{
+		NewRule("OG_DUPLICATE_PROPERTY", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			for _, conflict := range s.findTagConflicts(input.tags) {
+				issue := s.newIssue("OG_DUPLICATE_PROPERTY", conflict.Property, conflict.Values[1],
+					conflict.Property, strconv.Itoa(len(conflict.Values)), conflict.Values[0])
+				// Point at the first repeat rather than the tag platforms use
+				seen := 0
+				for _, tag := range input.tags {
+					if s.isConflictTag(tag) && tag.key == conflict.Property {
+						if seen++; seen == 2 {
+							issue.Line, issue.Column = tag.line, tag.column
+							break
+						}
+					}
+				}
+				issues = append(issues, issue)
+			}
+			return issues
+		}),
+		s.tagRule("OG_TAG_IN_BODY", func(tag sourceTag) bool {
+			return tag.inBody
+		}),
+		s.tagRule("OG_NAME_ATTRIBUTE", func(tag sourceTag) bool {
+			return tag.attr == "name" && strings.HasPrefix(tag.key, "og:")
+		}),
+		s.tagRule("OG_EMPTY_CONTENT", func(tag sourceTag) bool {
+			return strings.TrimSpace(tag.value) == ""
+		}),
+	}
+}
+
+// tagRule reports every og: or twitter: meta tag matching check at its
+// source position.
+func (s *OGPService) tagRule(code string, check func(sourceTag) bool) Rule {
+	return NewRule(code, func(input RuleInput) []models.ValidationIssue {
+		issues := []models.ValidationIssue{}
+		for _, tag := range input.tags {
+			if tag.attr == "rel" || !s.isSharingProperty(tag.key) || !check(tag) {
+				continue
+			}
+			issue := s.newIssue(code, tag.key, tag.value, tag.key)
+			issue.Line, issue.Column = tag.line, tag.column
+			issues = append(issues, issue)
+		}
+		return issues
+	})
+}
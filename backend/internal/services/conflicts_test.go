@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+const conflictTestHTML = `<html><head>
+<meta property="og:title" content="From CMS" />
+<meta property="og:title" content="From plugin" />
+<meta property="og:image" content="https://example.com/a.png" />
+<meta property="og:image" content="https://example.com/b.png" />
+<meta name="og:description" content="Named" />
+<meta property="og:site_name" content="" />
+<meta name="twitter:card" content="summary" />
+<meta name="twitter:card" content="summary_large_image" />
+</head><body>
+<meta property="og:type" content="article" />
+</body></html>`
+
+func TestOGPService_findTagConflicts(t *testing.T) {
+	service := NewOGPService()
+
+	conflicts := service.findTagConflicts(service.scanSourceTags(conflictTestHTML))
+	expected := []models.TagConflict{
+		{Property: "og:title", Values: []string{"From CMS", "From plugin"}},
+		{Property: "twitter:card", Values: []string{"summary", "summary_large_image"}},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected og:title and the name= twitter:card to conflict, got %+v", conflicts)
+	}
+}
+
+func TestOGPService_tagRules(t *testing.T) {
+	service := NewOGPService()
+	input := RuleInput{
+		OGPData: service.parseOGPTags(conflictTestHTML),
+		tags:    service.scanSourceTags(conflictTestHTML),
+	}
+	result := service.validateOGPData(input, models.RuleConfig{})
+
+	type located struct {
+		property string
+		line     int
+	}
+	found := map[string]located{}
+	for _, issue := range result.Issues {
+		if _, ok := found[issue.Code]; !ok {
+			found[issue.Code] = located{issue.Property, issue.Line}
+		}
+	}
+
+	expected := map[string]located{
+		"OG_DUPLICATE_PROPERTY": {"og:title", 3},
+		"OG_NAME_ATTRIBUTE":     {"og:description", 6},
+		"OG_EMPTY_CONTENT":      {"og:site_name", 7},
+		"OG_TAG_IN_BODY":        {"og:type", 11},
+	}
+	for code, want := range expected {
+		if got, ok := found[code]; !ok || got != want {
+			t.Errorf("%s = %+v (found %v), want %+v", code, got, ok, want)
+		}
+	}
+	if len(result.Conflicts) != 2 {
+		t.Errorf("expected conflicts in the validation result, got %+v", result.Conflicts)
+	}
+}
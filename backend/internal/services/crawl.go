@@ -0,0 +1,380 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// DefaultCrawlPages is the page budget of a crawl that does not set one.
+	DefaultCrawlPages = 50
+	// MaxCrawlPages is the largest page budget a crawl may ask for.
+	MaxCrawlPages = 200
+
+	defaultCrawlDepth = 2
+	maxCrawlDepth     = 5
+
+	// maxSitemapBytes caps a sitemap both as downloaded and after gunzip.
+	maxSitemapBytes = 50 << 20
+	// maxSitemapFetches caps how many sitemaps of an index are read.
+	maxSitemapFetches = 20
+	// maxWorstPages is how many of the lowest-scoring pages are reported.
+	maxWorstPages = 10
+)
+
+// skippedLinkExtensions are file types that are never HTML pages.
+var skippedLinkExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".svg": true,
+	".pdf": true, ".zip": true, ".gz": true, ".mp3": true, ".mp4": true, ".css": true, ".js": true, ".xml": true,
+}
+
+// sitemapDocument is either a urlset or a sitemapindex.
+type sitemapDocument struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// ValidateCrawl reports crawl requests the service would reject. A zero
+// max_pages or max_depth takes its default before the range is checked.
+func (s *OGPService) ValidateCrawl(req models.CrawlRequest) error {
+	req = s.crawlDefaults(req)
+	if (req.SitemapURL == "") == (req.StartURL == "") {
+		return fmt.Errorf("exactly one of sitemap_url and start_url is required")
+	}
+	for name, raw := range map[string]string{"sitemap_url": req.SitemapURL, "start_url": req.StartURL} {
+		if parsed, err := url.Parse(raw); raw != "" && (err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https")) {
+			return fmt.Errorf("%s must be an absolute http or https URL", name)
+		}
+	}
+	if req.MaxPages < 1 || req.MaxPages > MaxCrawlPages {
+		return fmt.Errorf("max_pages must be between 1 and %d", MaxCrawlPages)
+	}
+	if req.MaxDepth < 1 || req.MaxDepth > maxCrawlDepth {
+		return fmt.Errorf("max_depth must be between 1 and %d", maxCrawlDepth)
+	}
+	return s.ValidateOptions(req.VerifyOptions)
+}
+
+// crawlDefaults fills in the page budget and depth a request leaves out.
+func (s *OGPService) crawlDefaults(req models.CrawlRequest) models.CrawlRequest {
+	if req.MaxPages == 0 {
+		req.MaxPages = DefaultCrawlPages
+	}
+	if req.MaxDepth == 0 {
+		req.MaxDepth = defaultCrawlDepth
+	}
+	return req
+}
+
+// Crawl verifies every page listed in a sitemap, or found by following
+// same-host links from a start page, and reports the site's worst pages and
+// the titles and images several pages share.
+func (s *OGPService) Crawl(ctx context.Context, req models.CrawlRequest) (*models.CrawlReport, error) {
+	return s.CrawlWithProgress(ctx, req, nil)
+}
+
+// CrawlWithProgress is Crawl reporting each page to progress as soon as it
+// has been verified. The total grows as links are discovered.
+func (s *OGPService) CrawlWithProgress(ctx context.Context, req models.CrawlRequest, progress ProgressFunc) (*models.CrawlReport, error) {
+	if progress == nil {
+		progress = func(models.BatchResult, int) {}
+	}
+	if err := s.ValidateCrawl(req); err != nil {
+		return nil, err
+	}
+	req = s.crawlDefaults(req)
+	opts := req.VerifyOptions
+	opts.CheckAlternates = false
+
+	report := &models.CrawlReport{
+		Pages:  []models.CrawlPage{},
+		Errors: []string{},
+	}
+	results := []models.BatchResult{}
+
+	if req.SitemapURL != "" {
+		report.Source = "sitemap"
+		urls, truncated, errs, err := s.collectSitemapURLs(ctx, req.SitemapURL, req.MaxPages)
+		if err != nil {
+			return nil, err
+		}
+		report.Truncated = truncated
+		report.Errors = errs
+
+		pages := make([]models.CrawlPage, len(urls))
+		pageResults := make([]models.BatchResult, len(urls))
+		s.forEachURL(ctx, urls, func(i int, err error) {
+			if err != nil {
+				pages[i], pageResults[i] = s.failedCrawlPage(urls[i], 0, err)
+			} else {
+				pages[i], pageResults[i], _ = s.crawlPage(ctx, urls[i], 0, false, opts)
+			}
+			progress(pageResults[i], len(urls))
+		})
+		report.Pages = append(report.Pages, pages...)
+		results = append(results, pageResults...)
+	} else {
+		report.Source = "links"
+		startURL, _ := url.Parse(req.StartURL)
+
+		// Pages are deduplicated by normalized URL, so that /page and /page/
+		// are only verified once
+		seen := map[string]bool{s.normalizeURL(startURL): true}
+		level := []string{req.StartURL}
+		for depth := 0; len(level) > 0; depth++ {
+			pages := make([]models.CrawlPage, len(level))
+			pageResults := make([]models.BatchResult, len(level))
+			links := make([][]string, len(level))
+			known := len(report.Pages) + len(level)
+			s.forEachURL(ctx, level, func(i int, err error) {
+				if err != nil {
+					pages[i], pageResults[i] = s.failedCrawlPage(level[i], depth, err)
+				} else {
+					pages[i], pageResults[i], links[i] = s.crawlPage(ctx, level[i], depth, depth < req.MaxDepth, opts)
+				}
+				progress(pageResults[i], known)
+			})
+			report.Pages = append(report.Pages, pages...)
+			results = append(results, pageResults...)
+
+			next := []string{}
+			for _, pageLinks := range links {
+				for _, link := range pageLinks {
+					linkURL, err := url.Parse(link)
+					if err != nil || !strings.EqualFold(linkURL.Host, startURL.Host) {
+						continue
+					}
+					key := s.normalizeURL(linkURL)
+					if seen[key] {
+						continue
+					}
+					if len(report.Pages)+len(next) == req.MaxPages {
+						report.Truncated = true
+						break
+					}
+					seen[key] = true
+					next = append(next, link)
+				}
+			}
+			level = next
+		}
+	}
+
+	report.Summary = s.summarizeBatch(results)
+	report.WorstPages = s.worstPages(report.Pages)
+	report.DuplicateTitles = s.duplicateValues(report.Pages, func(p models.CrawlPage) string { return strings.TrimSpace(p.Title) })
+	report.DuplicateImages = s.duplicateValues(report.Pages, func(p models.CrawlPage) string { return p.Image })
+	report.Timestamp = time.Now()
+	return report, nil
+}
+
+// crawlPage verifies one page and, when followLinks is set, returns the
+// links it contains.
+func (s *OGPService) crawlPage(ctx context.Context, targetURL string, depth int, followLinks bool, opts models.VerifyOptions) (models.CrawlPage, models.BatchResult, []string) {
+	page, err := s.fetchPage(ctx, targetURL)
+	if err != nil {
+		crawled, result := s.failedCrawlPage(targetURL, depth, err)
+		return crawled, result, nil
+	}
+
+	response := s.verifyDocument(ctx, targetURL, page.url, page.body, page.robotsHeader, true, opts, nil)
+	if s.onVerified != nil {
+		s.onVerified(response)
+	}
+	crawled := models.CrawlPage{
+		URL:        targetURL,
+		Depth:      depth,
+		Title:      response.OGPData.Title,
+		Image:      response.ImageInfo.URL,
+		Score:      response.Score.Score,
+		IsValid:    response.Validation.IsValid,
+		IssueCodes: []string{},
+	}
+	for _, issues := range [][]models.ValidationIssue{response.Validation.Issues, response.Validation.Accessibility.Issues} {
+		for _, issue := range issues {
+			crawled.IssueCodes = append(crawled.IssueCodes, issue.Code)
+		}
+	}
+
+	var links []string
+	if followLinks {
+		links = s.extractLinks(page.url, page.body)
+	}
+	return crawled, models.BatchResult{URL: targetURL, Response: response}, links
+}
+
+func (s *OGPService) failedCrawlPage(targetURL string, depth int, err error) (models.CrawlPage, models.BatchResult) {
+	return models.CrawlPage{URL: targetURL, Depth: depth, IssueCodes: []string{}, Error: err.Error()},
+		models.BatchResult{URL: targetURL, Error: err.Error()}
+}
+
+// collectSitemapURLs reads a sitemap, following sitemap indexes, and returns
+// up to limit page URLs. Only a failure to read sitemapURL itself is an
+// error; nested sitemaps that cannot be read are listed in errs.
+func (s *OGPService) collectSitemapURLs(ctx context.Context, sitemapURL string, limit int) (urls []string, truncated bool, errs []string, err error) {
+	urls, errs = []string{}, []string{}
+	queue := []string{sitemapURL}
+	visited := map[string]bool{sitemapURL: true}
+	seen := map[string]bool{}
+
+	for fetched := 0; len(queue) > 0 && !truncated; fetched++ {
+		if fetched == maxSitemapFetches {
+			errs = append(errs, fmt.Sprintf("stopped after reading %d sitemaps", maxSitemapFetches))
+			break
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		doc, err := s.fetchSitemap(ctx, current)
+		if err != nil {
+			if current == sitemapURL {
+				return nil, false, nil, err
+			}
+			errs = append(errs, fmt.Sprintf("%s: %v", current, err))
+			continue
+		}
+
+		for _, child := range doc.Sitemaps {
+			loc := strings.TrimSpace(child.Loc)
+			if loc != "" && !visited[loc] {
+				visited[loc] = true
+				queue = append(queue, loc)
+			}
+		}
+		for _, entry := range doc.URLs {
+			loc := strings.TrimSpace(entry.Loc)
+			if loc == "" || seen[loc] {
+				continue
+			}
+			if len(urls) == limit {
+				truncated = true
+				break
+			}
+			seen[loc] = true
+			urls = append(urls, loc)
+		}
+	}
+
+	return urls, truncated, errs, nil
+}
+
+// fetchSitemap downloads and parses a sitemap, gunzipping it when needed.
+func (s *OGPService) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapDocument, error) {
+	downloaded, err := s.download(ctx, nil, sitemapURL, "sitemap", maxSitemapBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	body := downloaded.body
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip sitemap: %w", err)
+		}
+		body, err = io.ReadAll(io.LimitReader(reader, maxSitemapBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip sitemap: %w", err)
+		}
+		if len(body) > maxSitemapBytes {
+			return nil, fmt.Errorf("sitemap exceeds %d bytes", maxSitemapBytes)
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid sitemap XML: %w", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("not a sitemap: root element is <%s>", doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// extractLinks returns the absolute http(s) links of a page, without
+// fragments and in document order, skipping obvious non-HTML files.
+func (s *OGPService) extractLinks(pageURL *url.URL, htmlContent string) []string {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil
+	}
+
+	links := []string{}
+	seen := map[string]bool{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			if resolved, err := s.resolveURL(pageURL, s.getAttr(n, "href")); err == nil &&
+				(resolved.Scheme == "http" || resolved.Scheme == "https") &&
+				!skippedLinkExtensions[strings.ToLower(path.Ext(resolved.Path))] {
+				resolved.Fragment = ""
+				if link := resolved.String(); !seen[link] {
+					seen[link] = true
+					links = append(links, link)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return links
+}
+
+// worstPages returns the lowest-scoring pages that could be verified.
+func (s *OGPService) worstPages(pages []models.CrawlPage) []models.CrawlPage {
+	worst := []models.CrawlPage{}
+	for _, page := range pages {
+		if page.Error == "" {
+			worst = append(worst, page)
+		}
+	}
+	sort.SliceStable(worst, func(i, j int) bool { return worst[i].Score < worst[j].Score })
+	if len(worst) > maxWorstPages {
+		worst = worst[:maxWorstPages]
+	}
+	return worst
+}
+
+// duplicateValues groups pages by value and returns the values used by more
+// than one page, most shared first.
+func (s *OGPService) duplicateValues(pages []models.CrawlPage, value func(models.CrawlPage) string) []models.DuplicateValue {
+	groups := map[string][]string{}
+	order := []string{}
+	for _, page := range pages {
+		v := value(page)
+		if v == "" || page.Error != "" {
+			continue
+		}
+		if _, ok := groups[v]; !ok {
+			order = append(order, v)
+		}
+		groups[v] = append(groups[v], page.URL)
+	}
+
+	duplicates := []models.DuplicateValue{}
+	for _, v := range order {
+		if len(groups[v]) > 1 {
+			duplicates = append(duplicates, models.DuplicateValue{Value: v, URLs: groups[v]})
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool { return len(duplicates[i].URLs) > len(duplicates[j].URLs) })
+	return duplicates
+}
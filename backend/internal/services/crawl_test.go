@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatalf("Failed to gzip: %v", err)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+// crawlSite serves a small site: /, /a and /b link to each other and to
+// /deep, /b also links to /a spelled differently, /a and /b share a title
+// and an image, and the sitemaps list them.
+func crawlSite(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	page := func(title, image, links string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `<html><head><meta property="og:title" content="%s" /><meta property="og:image" content="%s" /></head><body>%s</body></html>`, title, image, links)
+		}
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		page("Home", "", `<a href="/a">A</a><a href="/b#top">B</a><a href="https://other.test/">Other</a><a href="/brochure.pdf">PDF</a>`)(w, r)
+	})
+	mux.HandleFunc("/a", page("Product", "/shared.png", `<a href="/">Home</a><a href="/deep">Deep</a>`))
+	mux.HandleFunc("/b", page("Product", "/shared.png", `<a href="/a">A</a><a href="/a/">A</a><a href="http://EXAMPLE.test/a">A</a>`))
+	mux.HandleFunc("/deep", page("Deep", "", ""))
+	mux.HandleFunc("/shared.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(testPNG(t, 1200, 630))
+	})
+	mux.HandleFunc("/sitemap_index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>http://example.test/sitemap-pages.xml.gz</loc></sitemap>
+  <sitemap><loc>http://example.test/missing.xml</loc></sitemap>
+</sitemapindex>`)
+	})
+	mux.HandleFunc("/sitemap-pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(gzipBytes(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>http://example.test/a</loc></url>
+  <url><loc>http://example.test/b</loc></url>
+  <url><loc>http://example.test/a</loc></url>
+  <url><loc>http://example.test/gone</loc></url>
+</urlset>`))
+	})
+	return mux
+}
+
+func crawledURLs(report *models.CrawlReport) []string {
+	urls := []string{}
+	for _, page := range report.Pages {
+		urls = append(urls, page.URL)
+	}
+	return urls
+}
+
+func TestOGPService_CrawlSitemap(t *testing.T) {
+	service := newTestService(t, crawlSite(t))
+
+	report, err := service.Crawl(context.Background(), models.CrawlRequest{SitemapURL: "http://example.test/sitemap_index.xml"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"http://example.test/a", "http://example.test/b", "http://example.test/gone"}
+	if report.Source != "sitemap" || !reflect.DeepEqual(crawledURLs(report), expected) {
+		t.Errorf("Crawled %v from %s, want %v", crawledURLs(report), report.Source, expected)
+	}
+	if len(report.Errors) != 1 {
+		t.Errorf("Expected the missing nested sitemap to be reported, got %v", report.Errors)
+	}
+	if report.Summary.Succeeded != 2 || report.Summary.Failed != 1 {
+		t.Errorf("Unexpected summary: %+v", report.Summary)
+	}
+	if len(report.DuplicateTitles) != 1 || report.DuplicateTitles[0].Value != "Product" || len(report.DuplicateTitles[0].URLs) != 2 {
+		t.Errorf("Expected the shared title, got %+v", report.DuplicateTitles)
+	}
+	if len(report.DuplicateImages) != 1 || report.DuplicateImages[0].Value != "http://example.test/shared.png" {
+		t.Errorf("Expected the shared image, got %+v", report.DuplicateImages)
+	}
+	if len(report.WorstPages) != 2 {
+		t.Errorf("Expected only verified pages among the worst, got %+v", report.WorstPages)
+	}
+
+	report, err = service.Crawl(context.Background(), models.CrawlRequest{SitemapURL: "http://example.test/sitemap_index.xml", MaxPages: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Pages) != 1 || !report.Truncated {
+		t.Errorf("Expected one page and truncation, got %v", crawledURLs(report))
+	}
+
+	if _, err := service.Crawl(context.Background(), models.CrawlRequest{SitemapURL: "http://example.test/missing.xml"}); err == nil {
+		t.Error("Expected error when the sitemap cannot be read")
+	}
+}
+
+func TestOGPService_CrawlLinks(t *testing.T) {
+	service := newTestService(t, crawlSite(t))
+
+	tests := []struct {
+		name      string
+		depth     int
+		maxPages  int
+		expected  []string
+		truncated bool
+	}{
+		{"depth 1", 1, 0, []string{"http://example.test/", "http://example.test/a", "http://example.test/b"}, false},
+		{"default depth", 0, 0, []string{"http://example.test/", "http://example.test/a", "http://example.test/b", "http://example.test/deep"}, false},
+		{"page budget", 2, 2, []string{"http://example.test/", "http://example.test/a"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := service.Crawl(context.Background(), models.CrawlRequest{StartURL: "http://example.test/", MaxDepth: tt.depth, MaxPages: tt.maxPages})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(crawledURLs(report), tt.expected) {
+				t.Errorf("Crawled %v, want %v", crawledURLs(report), tt.expected)
+			}
+			if report.Truncated != tt.truncated {
+				t.Errorf("Truncated = %v, want %v", report.Truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestOGPService_ValidateCrawl(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name string
+		req  models.CrawlRequest
+	}{
+		{"neither source", models.CrawlRequest{}},
+		{"both sources", models.CrawlRequest{SitemapURL: "https://example.com/sitemap.xml", StartURL: "https://example.com/"}},
+		{"relative start URL", models.CrawlRequest{StartURL: "/"}},
+		{"too many pages", models.CrawlRequest{StartURL: "https://example.com/", MaxPages: MaxCrawlPages + 1}},
+		{"too deep", models.CrawlRequest{StartURL: "https://example.com/", MaxDepth: maxCrawlDepth + 1}},
+		{"negative pages", models.CrawlRequest{StartURL: "https://example.com/", MaxPages: -1}},
+		{"negative depth", models.CrawlRequest{StartURL: "https://example.com/", MaxDepth: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.ValidateCrawl(tt.req); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	// Omitted limits take their defaults
+	if err := service.ValidateCrawl(models.CrawlRequest{StartURL: "https://example.com/"}); err != nil {
+		t.Errorf("Expected defaults to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ogp-verification-service/internal/models"
+)
+
+// diffIgnoredFields are left out of a diff's field changes: the time and
+// report link of the verification, and the issue messages that are compared
+// as issues instead.
+var diffIgnoredFields = map[string]bool{
+	"timestamp":           true,
+	"permalink":           true,
+	"validation.warnings": true,
+	"validation.errors":   true,
+}
+
+func init() {
+	for _, platform := range PlatformNames {
+		for _, field := range []string{"warnings", "hints", "debugger_warnings"} {
+			diffIgnoredFields["previews."+platform+"."+field] = true
+		}
+	}
+}
+
+// ValidateDiff checks that each side of a diff names one verification.
+func (s *OGPService) ValidateDiff(req models.DiffRequest) error {
+	for _, side := range []struct {
+		name   string
+		target models.DiffTarget
+	}{{"before", req.Before}, {"after", req.After}} {
+		name, target := side.name, side.target
+		if (target.HistoryID == 0) == (target.URL == "") {
+			return fmt.Errorf("%s needs exactly one of history_id and url", name)
+		}
+		if parsed, err := url.Parse(target.URL); target.URL != "" && (err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https")) {
+			return fmt.Errorf("%s.url must be an absolute http or https URL", name)
+		}
+	}
+	return s.ValidateOptions(req.VerifyOptions)
+}
+
+// DiffResponses compares two verifications field by field. Issues from the
+// validation, accessibility, SEO and icon checks and the platform previews
+// are compared as sets of code and property, so that a changed message or
+// position is not reported as a new issue.
+func (s *OGPService) DiffResponses(before, after *models.OGPResponse) (models.VerificationDiff, error) {
+	beforeFields, err := flattenResponse(before)
+	if err != nil {
+		return models.VerificationDiff{}, err
+	}
+	afterFields, err := flattenResponse(after)
+	if err != nil {
+		return models.VerificationDiff{}, err
+	}
+
+	diff := models.VerificationDiff{
+		Before:        diffSide(before),
+		After:         diffSide(after),
+		ScoreDelta:    after.Score.Score - before.Score.Score,
+		Changes:       []models.FieldChange{},
+		IssuesAdded:   issuesMissingFrom(s.responseIssues(after), s.responseIssues(before)),
+		IssuesRemoved: issuesMissingFrom(s.responseIssues(before), s.responseIssues(after)),
+	}
+
+	fields := make([]string, 0, len(beforeFields)+len(afterFields))
+	for field := range beforeFields {
+		fields = append(fields, field)
+	}
+	for field := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if !reflect.DeepEqual(beforeFields[field], afterFields[field]) {
+			diff.Changes = append(diff.Changes, models.FieldChange{Field: field, Before: beforeFields[field], After: afterFields[field]})
+		}
+	}
+	return diff, nil
+}
+
+// FormatDiff renders a diff as plain text for pasting into tickets and
+// chat.
+func (s *OGPService) FormatDiff(diff models.VerificationDiff) string {
+	var b strings.Builder
+	side := func(label string, side models.DiffSide) {
+		fmt.Fprintf(&b, "%s %s (", label, side.URL)
+		if side.HistoryID != 0 {
+			fmt.Fprintf(&b, "history %d, ", side.HistoryID)
+		}
+		fmt.Fprintf(&b, "%s)\n", side.VerifiedAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+	}
+	side("Before:", diff.Before)
+	side("After: ", diff.After)
+	fmt.Fprintf(&b, "Score:  %d -> %d (%+d)\n", diff.Before.Score, diff.After.Score, diff.ScoreDelta)
+	fmt.Fprintf(&b, "Valid:  %t -> %t\n", diff.Before.IsValid, diff.After.IsValid)
+
+	fmt.Fprintf(&b, "\nChanged fields (%d)\n", len(diff.Changes))
+	for _, change := range diff.Changes {
+		fmt.Fprintf(&b, "  %s: %s -> %s\n", change.Field, formatDiffValue(change.Before), formatDiffValue(change.After))
+	}
+	for _, group := range []struct {
+		title  string
+		sign   string
+		issues []models.ValidationIssue
+	}{
+		{"Issues added", "+", diff.IssuesAdded},
+		{"Issues removed", "-", diff.IssuesRemoved},
+	} {
+		fmt.Fprintf(&b, "\n%s (%d)\n", group.title, len(group.issues))
+		for _, issue := range group.issues {
+			fmt.Fprintf(&b, "  %s %s %s", group.sign, issue.Severity, issue.Code)
+			if issue.Property != "" {
+				fmt.Fprintf(&b, " (%s)", issue.Property)
+			}
+			fmt.Fprintf(&b, ": %s\n", issue.Message)
+		}
+	}
+	return b.String()
+}
+
+func diffSide(response *models.OGPResponse) models.DiffSide {
+	return models.DiffSide{
+		URL:        response.URL,
+		VerifiedAt: response.Timestamp,
+		IsValid:    response.Validation.IsValid,
+		Score:      response.Score.Score,
+		Permalink:  response.Permalink,
+	}
+}
+
+// diffListKeys are the fields that identify an element of a list, in order
+// of preference.
+var diffListKeys = []string{"property", "code", "locale", "lang", "name", "url"}
+
+// flattenResponse maps the JSON path of every scalar in a response to its
+// value, leaving out issues and diffIgnoredFields. List elements are keyed
+// by diffElementKey rather than by position, so that an element inserted in
+// the middle of a list is one change instead of a shift of every element
+// after it.
+func flattenResponse(response *models.OGPResponse) (map[string]interface{}, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	fields := map[string]interface{}{}
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		if diffIgnoredFields[path] || path == "issues" || strings.HasSuffix(path, ".issues") {
+			return
+		}
+		join := func(key string) string {
+			if path == "" {
+				return key
+			}
+			return path + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for key, child := range value {
+				walk(join(key), child)
+			}
+		case []interface{}:
+			seen := map[string]int{}
+			for i, child := range value {
+				key := diffElementKey(child, i)
+				seen[key]++
+				if seen[key] > 1 {
+					key += "#" + strconv.Itoa(seen[key])
+				}
+				walk(path+"["+key+"]", child)
+			}
+		default:
+			fields[path] = value
+		}
+	}
+	walk("", decoded)
+	return fields, nil
+}
+
+// diffElementKey returns the path segment of the i-th element of a list:
+// the first of diffListKeys an object has, the value itself for a scalar so
+// that lists of values compare as sets, and the position otherwise.
+func diffElementKey(element interface{}, i int) string {
+	switch element := element.(type) {
+	case map[string]interface{}:
+		for _, field := range diffListKeys {
+			if key, ok := element[field].(string); ok && key != "" {
+				return key
+			}
+		}
+	case string, float64, bool:
+		return fmt.Sprint(element)
+	}
+	return strconv.Itoa(i)
+}
+
+// responseIssues returns the issues of a response's checks and previews.
+// Preview issues have no property of their own and take the preview's path,
+// such as previews.telegram, so that the same issue on two platforms is
+// told apart.
+func (s *OGPService) responseIssues(response *models.OGPResponse) []models.ValidationIssue {
+	var issues []models.ValidationIssue
+	for _, list := range [][]models.ValidationIssue{
+		response.Validation.Issues,
+		response.Validation.Accessibility.Issues,
+		response.SEO.Issues,
+		response.Icons.Issues,
+	} {
+		issues = append(issues, list...)
+	}
+	for i, preview := range s.previewList(&response.Previews) {
+		for _, issue := range preview.Issues {
+			issue.Property = "previews." + PlatformNames[i]
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// issuesMissingFrom returns the issues whose code and property are not in
+// other, without repeats.
+func issuesMissingFrom(issues, other []models.ValidationIssue) []models.ValidationIssue {
+	key := func(issue models.ValidationIssue) string {
+		return issue.Code + "\x00" + issue.Property
+	}
+	seen := map[string]bool{}
+	for _, issue := range other {
+		seen[key(issue)] = true
+	}
+
+	missing := []models.ValidationIssue{}
+	for _, issue := range issues {
+		if !seen[key(issue)] {
+			seen[key(issue)] = true
+			missing = append(missing, issue)
+		}
+	}
+	return missing
+}
+
+func formatDiffValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return strconv.Quote(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_DiffResponses(t *testing.T) {
+	service := NewOGPService()
+
+	before := &models.OGPResponse{
+		URL:        "https://example.com/",
+		OGPData:    models.OGPData{Title: "Launch", Image: "https://example.com/card.png"},
+		Validation: models.ValidationResult{IsValid: true, Issues: []models.ValidationIssue{{Code: "OG_DESCRIPTION_MISSING", Severity: models.SeverityWarning, Property: "og:description", Message: "Missing og:description tag"}}},
+		Score:      models.QualityScore{Score: 80},
+		Timestamp:  time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		Permalink:  "/r/Xk3v9QmB2pLw7tZa",
+	}
+	after := &models.OGPResponse{
+		URL:     "https://example.com/",
+		OGPData: models.OGPData{Title: "Launch day", Image: "https://example.com/card.png"},
+		Validation: models.ValidationResult{Issues: []models.ValidationIssue{
+			{Code: "OG_DESCRIPTION_MISSING", Severity: models.SeverityWarning, Property: "og:description", Message: "og:descriptionタグがありません", Line: 3},
+		}},
+		SEO:       models.SEOResult{Issues: []models.ValidationIssue{{Code: "SEO_CANONICAL_MISSING", Severity: models.SeverityWarning, Message: "Missing canonical link"}}},
+		Score:     models.QualityScore{Score: 65},
+		Timestamp: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+		Permalink: "/r/Pq8sW2nLx5vY7cRb",
+	}
+
+	diff, err := service.DiffResponses(before, after)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if diff.ScoreDelta != -15 || !diff.Before.IsValid || diff.After.IsValid {
+		t.Errorf("Expected the score delta and validity of each side, got %+v", diff)
+	}
+	if diff.Before.Permalink != before.Permalink || diff.After.Permalink != after.Permalink {
+		t.Errorf("Expected the report of each side, got %+v and %+v", diff.Before, diff.After)
+	}
+
+	changed := []string{}
+	for _, change := range diff.Changes {
+		changed = append(changed, change.Field)
+	}
+	expected := []string{"ogp_data.title", "score.score", "validation.is_valid"}
+	if strings.Join(changed, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected changes to %v, got %v", expected, changed)
+	}
+	if diff.Changes[0].Before != "Launch" || diff.Changes[0].After != "Launch day" {
+		t.Errorf("Expected both titles, got %+v", diff.Changes[0])
+	}
+
+	// A translated message on the same issue is not a new issue
+	if len(diff.IssuesAdded) != 1 || diff.IssuesAdded[0].Code != "SEO_CANONICAL_MISSING" || len(diff.IssuesRemoved) != 0 {
+		t.Errorf("Expected only the canonical issue added, got %+v and %+v", diff.IssuesAdded, diff.IssuesRemoved)
+	}
+
+	text := service.FormatDiff(diff)
+	for _, line := range []string{
+		"Before: https://example.com/ (2026-10-01 09:00:00 UTC)",
+		"Score:  80 -> 65 (-15)",
+		`  ogp_data.title: "Launch" -> "Launch day"`,
+		"  + warning SEO_CANONICAL_MISSING: Missing canonical link",
+		"Issues removed (0)",
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected the text diff to contain %q, got:\n%s", line, text)
+		}
+	}
+}
+
+func TestOGPService_DiffResponses_ListInsertedInMiddle(t *testing.T) {
+	service := NewOGPService()
+
+	before := &models.OGPResponse{
+		OGPData: models.OGPData{LocaleAlternates: []string{"en_US", "fr_FR"}},
+		SEO: models.SEOResult{Hreflangs: []models.HreflangLink{
+			{Lang: "en", URL: "https://example.com/en/"},
+			{Lang: "fr", URL: "https://example.com/fr/"},
+		}},
+	}
+	after := &models.OGPResponse{
+		OGPData: models.OGPData{LocaleAlternates: []string{"en_US", "de_DE", "fr_FR"}},
+		SEO: models.SEOResult{Hreflangs: []models.HreflangLink{
+			{Lang: "en", URL: "https://example.com/en/"},
+			{Lang: "de", URL: "https://example.com/de/"},
+			{Lang: "fr", URL: "https://example.com/fr/"},
+		}},
+	}
+
+	diff, err := service.DiffResponses(before, after)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	changed := []string{}
+	for _, change := range diff.Changes {
+		if change.Before != nil {
+			t.Errorf("Expected only additions, got %+v", change)
+		}
+		changed = append(changed, change.Field)
+	}
+	expected := []string{"ogp_data.locale_alternates[de_DE]", "seo.hreflangs[de].lang", "seo.hreflangs[de].url"}
+	if strings.Join(changed, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected changes to %v, got %v", expected, changed)
+	}
+}
+
+func TestOGPService_DiffResponses_PreviewIssues(t *testing.T) {
+	service := NewOGPService()
+
+	english := []models.ValidationIssue{service.newIssue("IMESSAGE_NO_DESCRIPTION", "", "")}
+	before := &models.OGPResponse{Previews: models.PlatformPreviews{
+		IMessage: models.PlatformPreview{Issues: english, Hints: []string{english[0].Message}},
+	}}
+
+	// The same hint in another language, and a new Telegram warning
+	japanese := []models.ValidationIssue{service.newIssue("IMESSAGE_NO_DESCRIPTION", "", "")}
+	service.localizeIssues(japanese, LangJapanese)
+	tooLarge := service.newIssue("TELEGRAM_IMAGE_TOO_LARGE", "", "")
+	after := &models.OGPResponse{Previews: models.PlatformPreviews{
+		IMessage: models.PlatformPreview{Issues: japanese, Hints: []string{japanese[0].Message}},
+		Telegram: models.PlatformPreview{Issues: []models.ValidationIssue{tooLarge}, Warnings: []string{tooLarge.Message}},
+	}}
+
+	diff, err := service.DiffResponses(before, after)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(diff.Changes) != 0 {
+		t.Errorf("Expected preview messages to be left out of the changes, got %+v", diff.Changes)
+	}
+	if len(diff.IssuesAdded) != 1 || diff.IssuesAdded[0].Code != "TELEGRAM_IMAGE_TOO_LARGE" || diff.IssuesAdded[0].Property != "previews.telegram" || len(diff.IssuesRemoved) != 0 {
+		t.Errorf("Expected only the Telegram issue added, got %+v and %+v", diff.IssuesAdded, diff.IssuesRemoved)
+	}
+}
+
+func TestOGPService_ValidateDiff(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name    string
+		req     models.DiffRequest
+		wantErr string
+	}{
+		{"history entries", models.DiffRequest{Before: models.DiffTarget{HistoryID: 1}, After: models.DiffTarget{HistoryID: 2}}, ""},
+		{"live urls", models.DiffRequest{Before: models.DiffTarget{URL: "https://staging.example.com/"}, After: models.DiffTarget{URL: "https://example.com/"}}, ""},
+		{"missing before", models.DiffRequest{After: models.DiffTarget{HistoryID: 2}}, "before needs exactly one of history_id and url"},
+		{"both set", models.DiffRequest{Before: models.DiffTarget{HistoryID: 1}, After: models.DiffTarget{HistoryID: 2, URL: "https://example.com/"}}, "after needs exactly one of history_id and url"},
+		{"relative url", models.DiffRequest{Before: models.DiffTarget{HistoryID: 1}, After: models.DiffTarget{URL: "/about"}}, "after.url must be an absolute http or https URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateDiff(tt.req)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strings"
+
+	"ogp-verification-service/internal/models"
+)
+
+// discordLargeImageCard is the twitter:card value that makes Discord show the
+// image full width instead of as a thumbnail.
+const discordLargeImageCard = "summary_large_image"
+
+// generateDiscordPreview lays out the embed the way Discord does: the site
+// name and author rows above the title, and an image that is large only for
+// summary_large_image cards. The theme-color side bar is set by
+// applySiteIcons.
+func (s *OGPService) generateDiscordPreview(ogpData models.OGPData) models.PlatformPreview {
+	preview := s.newPlatformPreview("discord", ogpData, 256, 2048)
+	s.checkPreviewLengths(&preview, "Discord")
+	preview.Hints = []string{}
+	preview.SiteName = ogpData.SiteName
+	preview.Author = strings.TrimSpace(ogpData.Author)
+
+	switch {
+	case ogpData.Image == "":
+		preview.Layout = models.PreviewLayoutNone
+	case ogpData.TwitterCard == discordLargeImageCard:
+		preview.Layout = models.PreviewLayoutLargeImage
+		s.addPreviewIssue(&preview, "DISCORD_IMAGE_LARGE")
+	default:
+		preview.Layout = models.PreviewLayoutThumbnail
+		s.addPreviewIssue(&preview, "DISCORD_IMAGE_THUMBNAIL")
+	}
+
+	return preview
+}
+
+// normalizeHexColor expands a #rgb or #rrggbb colour to lower-case #rrggbb.
+func (s *OGPService) normalizeHexColor(color string) (string, bool) {
+	if !themeColorRegex.MatchString(color) {
+		return "", false
+	}
+	color = strings.ToLower(color)
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color, true
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_parseDiscordTags(t *testing.T) {
+	service := NewOGPService()
+
+	html := `<html><head>
+		<meta name="twitter:card" content="summary_large_image" />
+		<meta name="author" content="Jane Doe" />
+	</head></html>`
+
+	result := service.parseOGPTags(html)
+	if result.TwitterCard != "summary_large_image" {
+		t.Errorf("Expected twitter:card, got %q", result.TwitterCard)
+	}
+	if result.Author != "Jane Doe" {
+		t.Errorf("Expected author, got %q", result.Author)
+	}
+}
+
+func TestOGPService_generateDiscordPreview(t *testing.T) {
+	service := NewOGPService()
+	base := models.OGPData{Title: "Title", SiteName: "Example", Author: "Jane Doe", Image: "https://example.com/image.jpg"}
+
+	tests := []struct {
+		name   string
+		card   string
+		image  string
+		layout string
+	}{
+		{"large image card", "summary_large_image", base.Image, models.PreviewLayoutLargeImage},
+		{"summary card", "summary", base.Image, models.PreviewLayoutThumbnail},
+		{"no twitter:card", "", base.Image, models.PreviewLayoutThumbnail},
+		{"no image", "summary_large_image", "", models.PreviewLayoutNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := base
+			data.TwitterCard, data.Image = tt.card, tt.image
+
+			result := service.generateDiscordPreview(data)
+			if result.Layout != tt.layout {
+				t.Errorf("Expected layout %q, got %q", tt.layout, result.Layout)
+			}
+			if len(result.Warnings) != 0 {
+				t.Errorf("Expected no warnings, got %v", result.Warnings)
+			}
+			if result.SiteName != "Example" || result.Author != "Jane Doe" {
+				t.Errorf("Expected site name and author rows, got %q and %q", result.SiteName, result.Author)
+			}
+		})
+	}
+}
+
+func TestOGPService_applySiteIconsThemeColor(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name       string
+		themeColor string
+		color      string
+	}{
+		{"hex colour", "#5865F2", "#5865f2"},
+		{"short hex colour", "#abc", "#aabbcc"},
+		{"no colour", "", ""},
+		{"named colour", "rebeccapurple", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previews := models.PlatformPreviews{Discord: service.generateDiscordPreview(models.OGPData{Title: "Title"})}
+			service.applySiteIcons(&previews, models.SiteIcons{ThemeColor: tt.themeColor})
+			if previews.Discord.ThemeColor != tt.color {
+				t.Errorf("Expected colour %q, got %q", tt.color, previews.Discord.ThemeColor)
+			}
+			// An invalid colour is reported by the icons as THEME_COLOR_INVALID only
+			if len(previews.Discord.Warnings) != 0 {
+				t.Errorf("Expected no preview warnings, got %v", previews.Discord.Warnings)
+			}
+		})
+	}
+}
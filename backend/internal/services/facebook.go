@@ -0,0 +1,134 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// facebookMinImageSize is the smallest og:image edge Facebook accepts.
+	facebookMinImageSize = 200
+	// facebookLargeImageWidth and facebookLargeImageHeight are the smallest
+	// image shown as a full-width card rather than a small thumbnail.
+	facebookLargeImageWidth  = 600
+	facebookLargeImageHeight = 315
+	facebookImageRatio       = 1.91
+)
+
+var fbAppIDRegex = regexp.MustCompile(`^[0-9]+$`)
+
+// facebookObjectTypes are the og:type values Facebook recognises.
+var facebookObjectTypes = map[string]bool{
+	"website":             true,
+	"article":             true,
+	"book":                true,
+	"profile":             true,
+	"music.song":          true,
+	"music.album":         true,
+	"music.playlist":      true,
+	"music.radio_station": true,
+	"video.movie":         true,
+	"video.episode":       true,
+	"video.tv_show":       true,
+	"video.other":         true,
+	"object":              true,
+}
+
+// facebookDebuggerCodes are the issues the Facebook Sharing Debugger also
+// reports as warnings. The rest are guidance only.
+var facebookDebuggerCodes = map[string]bool{
+	"OG_TITLE_MISSING":       true,
+	"OG_DESCRIPTION_MISSING": true,
+	"OG_IMAGE_MISSING":       true,
+	"OG_DUPLICATE_PROPERTY":  true,
+	"FB_APP_ID_MISSING":      true,
+	"FB_APP_ID_INVALID":      true,
+	"FB_OG_TYPE_UNKNOWN":     true,
+	"FB_INFERRED_PROPERTY":   true,
+	"FB_IMAGE_TOO_SMALL":     true,
+}
+
+func (s *OGPService) facebookRules() []Rule {
+	return []Rule{
+		NewRule("FB_APP_ID_MISSING", func(input RuleInput) []models.ValidationIssue {
+			if input.OGPData.FBAppID != "" {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_APP_ID_MISSING", "fb:app_id", "")}
+		}),
+		NewRule("FB_APP_ID_INVALID", func(input RuleInput) []models.ValidationIssue {
+			appID := input.OGPData.FBAppID
+			if appID == "" || fbAppIDRegex.MatchString(appID) {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_APP_ID_INVALID", "fb:app_id", appID, appID)}
+		}),
+		NewRule("FB_OG_TYPE_UNKNOWN", func(input RuleInput) []models.ValidationIssue {
+			ogType := input.OGPData.Type
+			if ogType == "" || facebookObjectTypes[ogType] {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_OG_TYPE_UNKNOWN", "og:type", ogType, ogType)}
+		}),
+		NewRule("FB_INFERRED_PROPERTY", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			if input.OGPData.URL == "" {
+				issues = append(issues, s.newIssue("FB_INFERRED_PROPERTY", "og:url", "", "og:url"))
+			}
+			if input.OGPData.Type == "" {
+				issues = append(issues, s.newIssue("FB_INFERRED_PROPERTY", "og:type", "", "og:type"))
+			}
+			return issues
+		}),
+		NewRule("FB_IMAGE_TOO_SMALL", func(input RuleInput) []models.ValidationIssue {
+			info := input.ImageInfo
+			if info.Width == 0 || info.Height == 0 || (info.Width >= facebookMinImageSize && info.Height >= facebookMinImageSize) {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_IMAGE_TOO_SMALL", "og:image", input.OGPData.Image, s.imageSize(info))}
+		}),
+		NewRule("FB_IMAGE_SMALL_PREVIEW", func(input RuleInput) []models.ValidationIssue {
+			info := input.ImageInfo
+			if info.Width < facebookMinImageSize || info.Height < facebookMinImageSize ||
+				(info.Width >= facebookLargeImageWidth && info.Height >= facebookLargeImageHeight) {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_IMAGE_SMALL_PREVIEW", "og:image", input.OGPData.Image, s.imageSize(info))}
+		}),
+		NewRule("FB_IMAGE_ASPECT_RATIO", func(input RuleInput) []models.ValidationIssue {
+			info := input.ImageInfo
+			if info.Width == 0 || info.Height == 0 {
+				return nil
+			}
+			ratio := float64(info.Width) / float64(info.Height)
+			if math.Abs(ratio-facebookImageRatio) <= 0.1 {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_IMAGE_ASPECT_RATIO", "og:image", input.OGPData.Image, strconv.FormatFloat(ratio, 'f', 2, 64))}
+		}),
+		NewRule("FB_IMAGE_DIMENSIONS_UNDECLARED", func(input RuleInput) []models.ValidationIssue {
+			if input.OGPData.Image == "" || (input.OGPData.ImageWidth != "" && input.OGPData.ImageHeight != "") {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("FB_IMAGE_DIMENSIONS_UNDECLARED", "og:image", input.OGPData.Image)}
+		}),
+	}
+}
+
+func (s *OGPService) imageSize(info models.ImageMetadata) string {
+	return strconv.Itoa(info.Width) + "x" + strconv.Itoa(info.Height)
+}
+
+// applyFacebookDebugger lists on the Facebook preview the issues the Sharing
+// Debugger would also warn about. issues must already be localized.
+func (s *OGPService) applyFacebookDebugger(preview *models.PlatformPreview, issues []models.ValidationIssue) {
+	preview.DebuggerWarnings = []string{}
+	for _, issue := range issues {
+		if facebookDebuggerCodes[issue.Code] {
+			preview.DebuggerWarnings = append(preview.DebuggerWarnings, issue.Message)
+		}
+	}
+}
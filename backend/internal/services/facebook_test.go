@@ -0,0 +1,103 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_facebookRules(t *testing.T) {
+	service := NewOGPService()
+	complete := models.OGPData{
+		URL:         "https://example.com/",
+		Type:        "article",
+		Image:       "https://example.com/image.png",
+		ImageWidth:  "1200",
+		ImageHeight: "630",
+		FBAppID:     "1234567890",
+	}
+	large := models.ImageMetadata{Width: 1200, Height: 630}
+
+	tests := []struct {
+		name     string
+		data     func(models.OGPData) models.OGPData
+		info     models.ImageMetadata
+		expected []string
+	}{
+		{
+			name:     "complete",
+			data:     func(d models.OGPData) models.OGPData { return d },
+			info:     large,
+			expected: []string{},
+		},
+		{
+			name: "missing app id, url and type",
+			data: func(d models.OGPData) models.OGPData {
+				d.FBAppID, d.URL, d.Type = "", "", ""
+				return d
+			},
+			info:     large,
+			expected: []string{"FB_APP_ID_MISSING", "FB_INFERRED_PROPERTY", "FB_INFERRED_PROPERTY"},
+		},
+		{
+			name: "invalid app id and unknown type",
+			data: func(d models.OGPData) models.OGPData {
+				d.FBAppID, d.Type = "acme", "blog"
+				return d
+			},
+			info:     large,
+			expected: []string{"FB_APP_ID_INVALID", "FB_OG_TYPE_UNKNOWN"},
+		},
+		{
+			name:     "tiny image",
+			data:     func(d models.OGPData) models.OGPData { return d },
+			info:     models.ImageMetadata{Width: 150, Height: 150},
+			expected: []string{"FB_IMAGE_ASPECT_RATIO", "FB_IMAGE_TOO_SMALL"},
+		},
+		{
+			name: "small image without declared size",
+			data: func(d models.OGPData) models.OGPData {
+				d.ImageWidth, d.ImageHeight = "", ""
+				return d
+			},
+			info:     models.ImageMetadata{Width: 400, Height: 210},
+			expected: []string{"FB_IMAGE_DIMENSIONS_UNDECLARED", "FB_IMAGE_SMALL_PREVIEW"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := RuleInput{OGPData: tt.data(complete), ImageInfo: tt.info}
+			codes := []string{}
+			for _, issue := range service.runRules(input, models.RuleConfig{}) {
+				if strings.HasPrefix(issue.Code, "FB_") {
+					codes = append(codes, issue.Code)
+				}
+			}
+			sort.Strings(codes)
+			if !reflect.DeepEqual(codes, tt.expected) {
+				t.Errorf("codes = %v, want %v", codes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOGPService_applyFacebookDebugger(t *testing.T) {
+	service := NewOGPService()
+	issues := []models.ValidationIssue{
+		service.newIssue("FB_APP_ID_MISSING", "fb:app_id", ""),
+		service.newIssue("FB_IMAGE_ASPECT_RATIO", "og:image", "", "1.00"),
+		service.newIssue("OG_TITLE_MISSING", "og:title", ""),
+	}
+
+	preview := models.PlatformPreview{}
+	service.applyFacebookDebugger(&preview, issues)
+
+	expected := []string{"Missing fb:app_id", "Missing og:title tag"}
+	if !reflect.DeepEqual(preview.DebuggerWarnings, expected) {
+		t.Errorf("DebuggerWarnings = %v, want %v", preview.DebuggerWarnings, expected)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// blueskyMaxImageBytes is the blob size limit for external embed thumbnails.
+	blueskyMaxImageBytes = 1000000
+	// blueskyAspectRatio is the crop Bluesky applies to link card images.
+	blueskyAspectRatio = 1.91
+)
+
+var fediverseHandleRegex = regexp.MustCompile(`^@?[A-Za-z0-9_.-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+
+func (s *OGPService) generateMastodonPreview(ogpData models.OGPData) models.PlatformPreview {
+	preview := s.newPlatformPreview("mastodon", ogpData, 255, 255)
+	s.checkPreviewLengths(&preview, "Mastodon")
+
+	if creator := strings.TrimSpace(ogpData.FediverseCreator); creator != "" {
+		if fediverseHandleRegex.MatchString(creator) {
+			preview.Author = "@" + strings.TrimPrefix(creator, "@")
+		} else {
+			s.addPreviewIssue(&preview, "MASTODON_CREATOR_INVALID", creator)
+		}
+	}
+
+	// Mastodon attaches at most one card to a status, for its first link.
+	s.addPreviewIssue(&preview, "MASTODON_ONE_CARD")
+
+	return preview
+}
+
+func (s *OGPService) generateMisskeyPreview(ogpData models.OGPData) models.PlatformPreview {
+	preview := s.newPlatformPreview("misskey", ogpData, 100, 300)
+	s.checkPreviewLengths(&preview, "Misskey")
+	return preview
+}
+
+func (s *OGPService) generateBlueskyPreview(ogpData models.OGPData, imageInfo models.ImageMetadata) models.PlatformPreview {
+	preview := s.newPlatformPreview("bluesky", ogpData, 300, 1000)
+	s.checkPreviewLengths(&preview, "Bluesky")
+
+	if ogpData.Image == "" {
+		return preview
+	}
+
+	if !imageProbed(imageInfo) {
+		s.addPreviewIssue(&preview, "PREVIEW_IMAGE_NOT_PROBED", "Bluesky")
+		return preview
+	}
+	if !imageInfo.Fetched {
+		s.addPreviewIssue(&preview, "PREVIEW_IMAGE_NOT_FETCHED", "Bluesky")
+		return preview
+	}
+
+	if imageInfo.Size > blueskyMaxImageBytes {
+		preview.IsValid = false
+		s.addPreviewIssue(&preview, "BLUESKY_IMAGE_TOO_LARGE", strconv.Itoa(blueskyMaxImageBytes))
+	}
+
+	if imageInfo.Width > 0 && imageInfo.Height > 0 {
+		ratio := float64(imageInfo.Width) / float64(imageInfo.Height)
+		if math.Abs(ratio-blueskyAspectRatio)/blueskyAspectRatio > 0.1 {
+			s.addPreviewIssue(&preview, "BLUESKY_IMAGE_CROPPED")
+		}
+	}
+
+	return preview
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_parseFediverseCreator(t *testing.T) {
+	service := NewOGPService()
+
+	html := `<html><head>
+		<meta property="og:title" content="Post" />
+		<meta name="fediverse:creator" content="@alice@mastodon.example" />
+	</head></html>`
+
+	result := service.parseOGPTags(html)
+	if result.FediverseCreator != "@alice@mastodon.example" {
+		t.Errorf("Expected fediverse creator, got %q", result.FediverseCreator)
+	}
+}
+
+func TestOGPService_generateMastodonPreview(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name           string
+		creator        string
+		expectedAuthor string
+		expectWarning  bool
+	}{
+		{"no creator", "", "", false},
+		{"valid creator", "@alice@mastodon.example", "@alice@mastodon.example", false},
+		{"creator without leading at", "alice@mastodon.example", "@alice@mastodon.example", false},
+		{"invalid creator", "alice", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.generateMastodonPreview(models.OGPData{Title: "Title", FediverseCreator: tt.creator})
+
+			if result.Platform != "mastodon" {
+				t.Errorf("Expected platform mastodon, got %s", result.Platform)
+			}
+			if result.Author != tt.expectedAuthor {
+				t.Errorf("Expected author %q, got %q", tt.expectedAuthor, result.Author)
+			}
+			if (len(result.Warnings) > 0) != tt.expectWarning {
+				t.Errorf("Expected warning %v, got %v", tt.expectWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestOGPService_generateMastodonPreviewOneCard(t *testing.T) {
+	service := NewOGPService()
+
+	result := service.generateMastodonPreview(models.OGPData{Title: "Title"})
+
+	if len(result.Hints) != 1 || len(result.Issues) != 1 || result.Issues[0].Code != "MASTODON_ONE_CARD" {
+		t.Errorf("Expected one-card hint, got hints %v, issues %v", result.Hints, result.Issues)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", result.Warnings)
+	}
+}
+
+func TestOGPService_generateMisskeyPreview(t *testing.T) {
+	service := NewOGPService()
+
+	result := service.generateMisskeyPreview(models.OGPData{Title: strings.Repeat("a", 120)})
+
+	if result.MaxTitleLen != 100 || result.MaxDescLen != 300 {
+		t.Errorf("Expected limits 100/300, got %d/%d", result.MaxTitleLen, result.MaxDescLen)
+	}
+	if len(result.Title) != 100 {
+		t.Errorf("Expected title truncated to 100, got %d", len(result.Title))
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected one warning, got %v", result.Warnings)
+	}
+}
+
+func TestOGPService_generateBlueskyPreview(t *testing.T) {
+	service := NewOGPService()
+	data := models.OGPData{Title: "Title", Image: "https://example.com/image.jpg"}
+
+	tests := []struct {
+		name          string
+		image         models.ImageMetadata
+		expectValid   bool
+		expectWarning string
+	}{
+		{
+			name:        "image within limits",
+			image:       models.ImageMetadata{Fetched: true, Size: 200000, Width: 1200, Height: 630},
+			expectValid: true,
+		},
+		{
+			name:          "image too large",
+			image:         models.ImageMetadata{Fetched: true, Size: 2000000, Width: 1200, Height: 630},
+			expectValid:   false,
+			expectWarning: "Image exceeds Bluesky size limit (1000000 bytes)",
+		},
+		{
+			name:          "square image",
+			image:         models.ImageMetadata{Fetched: true, Size: 200000, Width: 800, Height: 800},
+			expectValid:   true,
+			expectWarning: "Image will be cropped to 1.91:1 on Bluesky",
+		},
+		{
+			name:          "image not fetched",
+			image:         models.ImageMetadata{Error: "HTTP error: 404"},
+			expectValid:   true,
+			expectWarning: "Image could not be fetched for Bluesky size check",
+		},
+		{
+			// Probing turned off is a hint, not a warning
+			name:        "image not probed",
+			image:       models.ImageMetadata{},
+			expectValid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.generateBlueskyPreview(data, tt.image)
+
+			if result.IsValid != tt.expectValid {
+				t.Errorf("Expected IsValid %v, got %v", tt.expectValid, result.IsValid)
+			}
+			if tt.expectWarning == "" && len(result.Warnings) > 0 {
+				t.Errorf("Expected no warnings, got %v", result.Warnings)
+			}
+			if tt.expectWarning != "" && (len(result.Warnings) == 0 || result.Warnings[0] != tt.expectWarning) {
+				t.Errorf("Expected warning %q, got %v", tt.expectWarning, result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"ogp-verification-service/internal/models"
+)
+
+// latinAdvanceWidths holds the advance widths of printable ASCII (U+0020 to
+// U+007E) in 1/1000 em, taken from the Helvetica/Arial metrics that most
+// platforms fall back to for Latin text.
+var latinAdvanceWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// cjkRanges are the blocks rendered at a full em by CJK system fonts.
+var cjkRanges = []*unicode.RangeTable{
+	unicode.Han,
+	unicode.Hiragana,
+	unicode.Katakana,
+	unicode.Hangul,
+}
+
+const (
+	defaultAdvanceWidth   = 556
+	fullWidthAdvanceWidth = 1000
+	halfWidthAdvanceWidth = 500
+)
+
+// textLayout describes the box a platform renders a line-clamped text into.
+// A maxLines of zero means the platform never clamps the text.
+type textLayout struct {
+	fontSize  float64
+	lineWidth float64
+	maxLines  int
+}
+
+type platformTextLayout struct {
+	name  string
+	title textLayout
+	desc  textLayout
+}
+
+// platformLayouts approximates each platform's card text box on desktop.
+var platformLayouts = map[string]platformTextLayout{
+	"twitter":  {name: "Twitter", title: textLayout{15, 480, 1}, desc: textLayout{15, 480, 2}},
+	"facebook": {name: "Facebook", title: textLayout{16, 490, 2}, desc: textLayout{14, 490, 1}},
+	"discord":  {name: "Discord", title: textLayout{16, 400, 2}, desc: textLayout{14, 400, 0}},
+	"mastodon": {name: "Mastodon", title: textLayout{15, 450, 1}, desc: textLayout{15, 450, 2}},
+	"misskey":  {name: "Misskey", title: textLayout{14, 350, 1}, desc: textLayout{13, 350, 2}},
+	"bluesky":  {name: "Bluesky", title: textLayout{15, 500, 2}, desc: textLayout{13, 500, 2}},
+	"telegram": {name: "Telegram", title: textLayout{15, 360, 2}, desc: textLayout{15, 360, 6}},
+	"whatsapp": {name: "WhatsApp", title: textLayout{15, 300, 2}, desc: textLayout{13, 300, 1}},
+	"imessage": {name: "iMessage", title: textLayout{15, 250, 2}, desc: textLayout{13, 250, 0}},
+	"search":   {name: "Google", title: textLayout{searchTitleFontSize, searchMaxTitleWidth, 1}, desc: textLayout{searchDescFontSize, searchMaxDescWidth / 2, 2}},
+}
+
+func (s *OGPService) runeAdvance(r rune) int {
+	switch {
+	case r >= 0x20 && r <= 0x7E:
+		return latinAdvanceWidths[r-0x20]
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0xFF61 && r <= 0xFFDC:
+		return halfWidthAdvanceWidth
+	case r >= 0xFF01 && r <= 0xFF60, r >= 0x3000 && r <= 0x303F, r >= 0x1F300 && r <= 0x1FAFF:
+		return fullWidthAdvanceWidth
+	case unicode.IsOneOf(cjkRanges, r):
+		return fullWidthAdvanceWidth
+	case unicode.IsUpper(r):
+		return 667
+	default:
+		return defaultAdvanceWidth
+	}
+}
+
+func (s *OGPService) runeWidth(r rune, fontSize float64) float64 {
+	return float64(s.runeAdvance(r)) * fontSize / 1000
+}
+
+// estimateTextWidth returns the rendered width of text on a single line in pixels.
+func (s *OGPService) estimateTextWidth(text string, fontSize float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += s.runeWidth(r, fontSize)
+	}
+	return width
+}
+
+// layoutText wraps text into the layout's lines, breaking at spaces and around
+// CJK characters, and returns the text as displayed, the number of runes shown
+// before the ellipsis (zero when nothing is cut) and whether the text fits.
+func (s *OGPService) layoutText(text string, layout textLayout) (string, int, bool) {
+	if layout.maxLines == 0 || text == "" {
+		return text, 0, true
+	}
+
+	runes := []rune(text)
+	widths := make([]float64, len(runes))
+	for i, r := range runes {
+		widths[i] = s.runeWidth(r, layout.fontSize)
+	}
+
+	lines := 1
+	lineStart := 0
+	lineWidth := 0.0
+	breakAt := -1
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if s.isCJK(r) && i > lineStart {
+			breakAt = i
+		}
+
+		if lineWidth+widths[i] > layout.lineWidth && i > lineStart {
+			if lines == layout.maxLines {
+				end := s.ellipsisCut(widths, lineStart, i, layout)
+				return strings.TrimRight(string(runes[:end]), " ") + "...", end, false
+			}
+			lines++
+
+			if unicode.IsSpace(r) {
+				lineStart, lineWidth, breakAt = i+1, 0, -1
+				continue
+			}
+
+			next := i
+			if breakAt > lineStart {
+				next = breakAt
+			}
+			lineStart, lineWidth, breakAt = next, 0, -1
+			for j := next; j < i; j++ {
+				lineWidth += widths[j]
+			}
+		}
+
+		lineWidth += widths[i]
+		if unicode.IsSpace(r) || s.isCJK(r) {
+			breakAt = i + 1
+		}
+	}
+
+	return text, 0, true
+}
+
+// ellipsisCut backs off from end until the last line and an ellipsis fit.
+func (s *OGPService) ellipsisCut(widths []float64, lineStart, end int, layout textLayout) int {
+	ellipsis := s.estimateTextWidth("...", layout.fontSize)
+	width := 0.0
+	for j := lineStart; j < end; j++ {
+		width += widths[j]
+	}
+	for end > lineStart && width+ellipsis > layout.lineWidth {
+		end--
+		width -= widths[end]
+	}
+	return end
+}
+
+func (s *OGPService) isCJK(r rune) bool {
+	return s.runeAdvance(r) == fullWidthAdvanceWidth
+}
+
+// measurePreviewText fills in the pixel-width fields of a preview from the
+// untruncated title and description.
+func (s *OGPService) measurePreviewText(preview *models.PlatformPreview, title, description string) {
+	layout, ok := platformLayouts[preview.Platform]
+	if !ok {
+		preview.DisplayTitle = preview.Title
+		preview.DisplayDescription = preview.Description
+		preview.TitleFitsWidth = true
+		preview.DescFitsWidth = true
+		return
+	}
+
+	preview.TitlePixelWidth = s.estimateTextWidth(title, layout.title.fontSize)
+	preview.MaxTitlePixelWidth = layout.title.lineWidth * float64(layout.title.maxLines)
+	preview.DisplayTitle, preview.TitleTruncateAt, preview.TitleFitsWidth = s.layoutText(title, layout.title)
+
+	preview.DescPixelWidth = s.estimateTextWidth(description, layout.desc.fontSize)
+	preview.MaxDescPixelWidth = layout.desc.lineWidth * float64(layout.desc.maxLines)
+	preview.DisplayDescription, preview.DescTruncateAt, preview.DescFitsWidth = s.layoutText(description, layout.desc)
+}
+
+// applyWidthTruncation switches a preview to the predicted on-screen text.
+func (s *OGPService) applyWidthTruncation(preview *models.PlatformPreview) {
+	preview.Title = preview.DisplayTitle
+	preview.Description = preview.DisplayDescription
+
+	name := preview.Platform
+	if layout, ok := platformLayouts[preview.Platform]; ok {
+		name = layout.name
+	}
+	if !preview.TitleFitsWidth {
+		s.addPreviewIssue(preview, "PREVIEW_TITLE_CUT_OFF", name, strconv.Itoa(preview.TitleTruncateAt))
+	}
+	if !preview.DescFitsWidth {
+		s.addPreviewIssue(preview, "PREVIEW_DESCRIPTION_CUT_OFF", name, strconv.Itoa(preview.DescTruncateAt))
+	}
+}
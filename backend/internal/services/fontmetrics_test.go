@@ -0,0 +1,149 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_estimateTextWidth(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		text     string
+		fontSize float64
+		expected float64
+	}{
+		{"", 16, 0},
+		{"i", 1000, 222},
+		{"W", 1000, 944},
+		{"Hello", 10, 22.78},
+		{"日本語", 20, 60},
+		{"ｶﾀｶﾅ", 10, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result := service.estimateTextWidth(tt.text, tt.fontSize)
+			if diff := result - tt.expected; diff > 0.001 || diff < -0.001 {
+				t.Errorf("Expected width %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestOGPService_layoutText(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name            string
+		text            string
+		layout          textLayout
+		expectedDisplay string
+		expectedCut     int
+		expectFits      bool
+	}{
+		{
+			name:            "fits on one line",
+			text:            "Short title",
+			layout:          textLayout{16, 400, 1},
+			expectedDisplay: "Short title",
+			expectFits:      true,
+		},
+		{
+			name:            "unlimited lines",
+			text:            strings.Repeat("word ", 200),
+			layout:          textLayout{16, 100, 0},
+			expectedDisplay: strings.Repeat("word ", 200),
+			expectFits:      true,
+		},
+		{
+			name:            "single line cut",
+			text:            "abcdefghij",
+			layout:          textLayout{10, 30, 1},
+			expectedDisplay: "abc...",
+			expectedCut:     3,
+			expectFits:      false,
+		},
+		{
+			name:            "wraps at word boundary",
+			text:            "aaaa bbbb cccc",
+			layout:          textLayout{10, 30, 2},
+			expectedDisplay: "aaaa bbb...",
+			expectedCut:     8,
+			expectFits:      false,
+		},
+		{
+			name:            "wraps two lines without cut",
+			text:            "aaaa bbbb",
+			layout:          textLayout{10, 30, 2},
+			expectedDisplay: "aaaa bbbb",
+			expectFits:      true,
+		},
+		{
+			name:            "CJK breaks anywhere",
+			text:            "日本語のタイトルです",
+			layout:          textLayout{10, 50, 1},
+			expectedDisplay: "日本語の...",
+			expectedCut:     4,
+			expectFits:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			display, cut, fits := service.layoutText(tt.text, tt.layout)
+
+			if display != tt.expectedDisplay {
+				t.Errorf("Expected display %q, got %q", tt.expectedDisplay, display)
+			}
+			if cut != tt.expectedCut {
+				t.Errorf("Expected cut at %d, got %d", tt.expectedCut, cut)
+			}
+			if fits != tt.expectFits {
+				t.Errorf("Expected fits %v, got %v", tt.expectFits, fits)
+			}
+		})
+	}
+}
+
+func TestOGPService_widthTruncationMode(t *testing.T) {
+	service := NewOGPService()
+
+	// Narrow capitals fit in 70 characters but not in Twitter's single title line
+	data := models.OGPData{Title: strings.Repeat("W", 60)}
+
+	chars := service.generatePlatformPreviews(nil, data, models.ImageMetadata{}, models.SEOResult{}, models.VerifyOptions{})
+	width := service.generatePlatformPreviews(nil, data, models.ImageMetadata{}, models.SEOResult{}, models.VerifyOptions{TruncationMode: models.TruncationModeWidth})
+
+	if !chars.Twitter.TitleWithinCharLimit || chars.Twitter.TitleFitsWidth {
+		t.Errorf("Expected title within char limit but too wide, got %+v", chars.Twitter)
+	}
+	if chars.Twitter.Title != data.Title {
+		t.Errorf("Expected untruncated title in chars mode, got %q", chars.Twitter.Title)
+	}
+	if chars.Twitter.TitleTruncateAt == 0 || chars.Twitter.DisplayTitle == data.Title {
+		t.Errorf("Expected predicted truncation point, got %+v", chars.Twitter)
+	}
+
+	if width.Twitter.Title != chars.Twitter.DisplayTitle {
+		t.Errorf("Expected width mode title %q, got %q", chars.Twitter.DisplayTitle, width.Twitter.Title)
+	}
+	if len(width.Twitter.Warnings) != 1 {
+		t.Errorf("Expected cut-off warning, got %v", width.Twitter.Warnings)
+	}
+}
+
+func TestOGPService_ValidateOptions(t *testing.T) {
+	service := NewOGPService()
+
+	for _, mode := range []string{"", models.TruncationModeChars, models.TruncationModeWidth} {
+		if err := service.ValidateOptions(models.VerifyOptions{TruncationMode: mode}); err != nil {
+			t.Errorf("Unexpected error for mode %q: %v", mode, err)
+		}
+	}
+	if err := service.ValidateOptions(models.VerifyOptions{TruncationMode: "pixels"}); err == nil {
+		t.Error("Expected error for unknown truncation mode")
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// DefaultHistoryRetention is how long verifications are kept.
+	DefaultHistoryRetention = 30 * 24 * time.Hour
+	// DefaultHistoryLimit is how many entries are listed when no limit is given.
+	DefaultHistoryLimit = 20
+	// MaxHistoryLimit bounds the entries listed at once.
+	MaxHistoryLimit = 100
+	// historyPruneInterval is how often expired entries are deleted.
+	historyPruneInterval = time.Hour
+	// maxMemoryHistory bounds the entries a MemoryHistoryStore keeps.
+	maxMemoryHistory = 10000
+)
+
+var ErrHistoryNotFound = errors.New("history entry not found")
+
+// HistoryStore keeps past verifications. storage.SQLiteHistoryStore keeps
+// them across restarts; MemoryHistoryStore is enough for tests and
+// development.
+type HistoryStore interface {
+	// Save stores an entry with its response and returns it with its ID.
+	Save(entry models.HistoryEntry) (models.HistoryEntry, error)
+	// List returns up to limit entries for url, newest first, without their
+	// responses.
+	List(url string, limit int) ([]models.HistoryEntry, error)
+	// Get returns an entry with its response, or ErrHistoryNotFound.
+	Get(id int64) (models.HistoryEntry, error)
+	// DeleteBefore removes the entries created before t and returns how many
+	// there were.
+	DeleteBefore(t time.Time) (int, error)
+}
+
+// MemoryHistoryStore keeps the most recent verifications in memory.
+type MemoryHistoryStore struct {
+	mu      sync.RWMutex
+	entries []models.HistoryEntry
+	nextID  int64
+}
+
+func NewMemoryHistoryStore() *MemoryHistoryStore {
+	return &MemoryHistoryStore{nextID: 1}
+}
+
+func (s *MemoryHistoryStore) Save(entry models.HistoryEntry) (models.HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.ID = s.nextID
+	s.nextID++
+	s.entries = append(s.entries, entry)
+	if len(s.entries) > maxMemoryHistory {
+		s.entries = s.entries[len(s.entries)-maxMemoryHistory:]
+	}
+	return entry, nil
+}
+
+func (s *MemoryHistoryStore) List(url string, limit int) ([]models.HistoryEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := []models.HistoryEntry{}
+	for i := len(s.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if s.entries[i].URL == url {
+			entry := s.entries[i]
+			entry.Response = nil
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *MemoryHistoryStore) Get(id int64) (models.HistoryEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, entry := range s.entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return models.HistoryEntry{}, ErrHistoryNotFound
+}
+
+func (s *MemoryHistoryStore) DeleteBefore(t time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if !entry.CreatedAt.Before(t) {
+			kept = append(kept, entry)
+		}
+	}
+	deleted := len(s.entries) - len(kept)
+	s.entries = kept
+	return deleted, nil
+}
+
+// History records every verification of a URL so that past results can be
+// listed, and deletes them after the retention period.
+type History struct {
+	service   *OGPService
+	store     HistoryStore
+	retention time.Duration
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewHistory records verifications in store. A retention of zero keeps
+// them forever.
+func NewHistory(service *OGPService, store HistoryStore, retention time.Duration) *History {
+	return &History{service: service, store: store, retention: retention}
+}
+
+// Record stores a verification. Failures are logged; they do not fail the
+// verification.
+func (h *History) Record(response *models.OGPResponse) {
+	entry := models.HistoryEntry{
+		URL:             response.URL,
+		CreatedAt:       response.Timestamp,
+		ValidationState: h.service.validationState(response),
+		Response:        response,
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if _, err := h.store.Save(entry); err != nil {
+		log.Printf("Verification of %s not saved to history: %v", response.URL, err)
+	}
+	h.prune()
+}
+
+// List returns the latest verifications of url, newest first. A limit of
+// zero lists DefaultHistoryLimit entries.
+func (h *History) List(url string, limit int) ([]models.HistoryEntry, error) {
+	if url == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if limit == 0 {
+		limit = DefaultHistoryLimit
+	}
+	if limit < 1 || limit > MaxHistoryLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxHistoryLimit)
+	}
+	return h.store.List(url, limit)
+}
+
+// Get returns a stored verification with its full response.
+func (h *History) Get(id int64) (models.HistoryEntry, error) {
+	return h.store.Get(id)
+}
+
+// prune deletes expired entries at most once per historyPruneInterval.
+func (h *History) prune() {
+	if h.retention <= 0 {
+		return
+	}
+	h.mu.Lock()
+	if time.Since(h.lastPruned) < historyPruneInterval {
+		h.mu.Unlock()
+		return
+	}
+	h.lastPruned = time.Now()
+	h.mu.Unlock()
+
+	if _, err := h.store.DeleteBefore(time.Now().Add(-h.retention)); err != nil {
+		log.Printf("Expired history not deleted: %v", err)
+	}
+}
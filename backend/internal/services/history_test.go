@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestHistory(t *testing.T) {
+	store := NewMemoryHistoryStore()
+	history := NewHistory(NewOGPService(), store, 24*time.Hour)
+
+	// An entry past the retention period is deleted by the next Record
+	store.Save(models.HistoryEntry{URL: "https://example.com/", CreatedAt: time.Now().Add(-48 * time.Hour)})
+	history.Record(&models.OGPResponse{
+		URL:        "https://example.com/",
+		Validation: models.ValidationResult{Issues: []models.ValidationIssue{{Code: "OG_IMAGE_INVALID", Severity: models.SeverityError}}},
+		Score:      models.QualityScore{Score: 45},
+	})
+
+	entries, err := history.List("https://example.com/", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != 2 || entries[0].Score != 45 || len(entries[0].ErrorCodes) != 1 || entries[0].Response != nil {
+		t.Fatalf("Expected only the recorded entry without its response, got %+v", entries)
+	}
+	entry, err := history.Get(entries[0].ID)
+	if err != nil || entry.Response == nil || entry.CreatedAt.IsZero() {
+		t.Errorf("Expected the entry with its response, got %+v (%v)", entry, err)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		limit   int
+		wantErr string
+	}{
+		{"missing url", "", 0, "url is required"},
+		{"negative limit", "https://example.com/", -1, "limit must be between 1 and 100"},
+		{"limit too large", "https://example.com/", 101, "limit must be between 1 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := history.List(tt.url, tt.limit); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
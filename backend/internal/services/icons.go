@@ -0,0 +1,391 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// maxIconFetches caps how many discovered icons are downloaded per request.
+	maxIconFetches = 8
+	// iconsTimeout bounds the time spent on the manifest and icons together.
+	iconsTimeout = 5 * time.Second
+
+	maxIconBytes     = 1 << 20
+	maxManifestBytes = 256 << 10
+
+	iconSourceLink     = "link"
+	iconSourceManifest = "manifest"
+	iconSourceDefault  = "default"
+
+	iconRelIcon           = "icon"
+	iconRelAppleTouchIcon = "apple-touch-icon"
+)
+
+// minIconSizes are the smallest icon edges that stay sharp where each kind
+// of icon is shown: favicons in chat unfurls, apple-touch-icon in iMessage
+// and manifest icons on home screens.
+var minIconSizes = map[string]int{
+	iconRelIcon:           32,
+	iconRelAppleTouchIcon: 180,
+	iconSourceManifest:    192,
+}
+
+var (
+	themeColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	iconSizeRegex   = regexp.MustCompile(`^(\d+)x(\d+)$`)
+)
+
+// iconTags are the icon, manifest and theme-color declarations in a document.
+type iconTags struct {
+	icons       []models.IconMetadata
+	manifest    string
+	themeColors []themeColorTag
+}
+
+type themeColorTag struct {
+	value string
+	media string
+}
+
+// webManifest is the subset of a web app manifest the service reads.
+type webManifest struct {
+	Name       string `json:"name"`
+	ShortName  string `json:"short_name"`
+	ThemeColor string `json:"theme_color"`
+	Icons      []struct {
+		Src   string `json:"src"`
+		Sizes string `json:"sizes"`
+		Type  string `json:"type"`
+	} `json:"icons"`
+}
+
+func (s *OGPService) parseIconTags(htmlContent string) iconTags {
+	tags := iconTags{}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return tags
+	}
+
+	s.extractIconTags(doc, &tags)
+	return tags
+}
+
+func (s *OGPService) extractIconTags(n *html.Node, tags *iconTags) {
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "link":
+			href := strings.TrimSpace(s.getAttr(n, "href"))
+			if href == "" {
+				break
+			}
+			for _, rel := range strings.Fields(strings.ToLower(s.getAttr(n, "rel"))) {
+				icon := models.IconMetadata{
+					URL:    href,
+					Source: iconSourceLink,
+					Sizes:  strings.TrimSpace(s.getAttr(n, "sizes")),
+					Type:   strings.TrimSpace(s.getAttr(n, "type")),
+				}
+				switch rel {
+				case "icon":
+					icon.Rel = iconRelIcon
+					tags.icons = append(tags.icons, icon)
+				case "apple-touch-icon", "apple-touch-icon-precomposed":
+					icon.Rel = iconRelAppleTouchIcon
+					tags.icons = append(tags.icons, icon)
+				case "manifest":
+					if tags.manifest == "" {
+						tags.manifest = href
+					}
+				}
+			}
+		case "meta":
+			if strings.ToLower(s.getAttr(n, "name")) == "theme-color" {
+				tags.themeColors = append(tags.themeColors, themeColorTag{
+					value: strings.TrimSpace(s.getAttr(n, "content")),
+					media: strings.TrimSpace(s.getAttr(n, "media")),
+				})
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.extractIconTags(c, tags)
+	}
+}
+
+// inspectIcons discovers the page's icons, web app manifest and theme colour
+// and downloads the icons, in parallel and within iconsTimeout, to check
+// their format and dimensions.
+func (s *OGPService) inspectIcons(ctx context.Context, pageURL *url.URL, htmlContent string) models.SiteIcons {
+	ctx, cancel := context.WithTimeout(ctx, iconsTimeout)
+	defer cancel()
+
+	tags := s.parseIconTags(htmlContent)
+	result := models.SiteIcons{
+		Icons:  []models.IconMetadata{},
+		Issues: []models.ValidationIssue{},
+	}
+
+	hasFavicon := false
+	for _, icon := range tags.icons {
+		if resolved, err := s.resolveURL(pageURL, icon.URL); err == nil {
+			icon.URL = resolved.String()
+		}
+		hasFavicon = hasFavicon || icon.Rel == iconRelIcon
+		result.Icons = append(result.Icons, icon)
+	}
+	// Browsers and most crawlers fall back to /favicon.ico
+	if !hasFavicon && pageURL != nil && pageURL.IsAbs() {
+		result.Icons = append(result.Icons, models.IconMetadata{
+			URL:    pageURL.ResolveReference(&url.URL{Path: "/favicon.ico"}).String(),
+			Rel:    iconRelIcon,
+			Source: iconSourceDefault,
+		})
+	}
+
+	var manifest webManifest
+	if tags.manifest != "" {
+		result.Manifest, manifest = s.fetchManifest(ctx, pageURL, tags.manifest)
+		manifestURL, _ := url.Parse(result.Manifest.URL)
+		for _, entry := range manifest.Icons {
+			icon := models.IconMetadata{URL: entry.Src, Rel: iconRelIcon, Source: iconSourceManifest, Sizes: entry.Sizes, Type: entry.Type}
+			if resolved, err := s.resolveURL(manifestURL, entry.Src); err == nil {
+				icon.URL = resolved.String()
+			}
+			result.Icons = append(result.Icons, icon)
+		}
+		if !result.Manifest.Fetched {
+			result.Issues = append(result.Issues, s.newIssue("MANIFEST_INVALID", "manifest", result.Manifest.URL, result.Manifest.URL, result.Manifest.Error))
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range result.Icons[:min(len(result.Icons), maxIconFetches)] {
+		wg.Add(1)
+		go func(icon *models.IconMetadata) {
+			defer wg.Done()
+			s.probeIcon(ctx, icon)
+		}(&result.Icons[i])
+	}
+	wg.Wait()
+
+	result.ThemeColor = s.pickThemeColor(tags.themeColors, manifest.ThemeColor)
+	if result.ThemeColor != "" && !themeColorRegex.MatchString(result.ThemeColor) {
+		result.Issues = append(result.Issues, s.newIssue("THEME_COLOR_INVALID", "theme-color", result.ThemeColor, result.ThemeColor))
+	}
+
+	result.Favicon, result.AppleTouchIcon = s.pickIcons(result.Icons)
+	result.Issues = append(result.Issues, s.checkIcons(result)...)
+
+	return result
+}
+
+func (s *OGPService) fetchManifest(ctx context.Context, pageURL *url.URL, manifestURL string) (*models.ManifestInfo, webManifest) {
+	info := &models.ManifestInfo{URL: manifestURL}
+	var manifest webManifest
+
+	downloaded, err := s.download(ctx, pageURL, manifestURL, "manifest", maxManifestBytes)
+	if downloaded != nil {
+		info.URL = downloaded.url
+	}
+	if err != nil {
+		info.Error = err.Error()
+		return info, manifest
+	}
+	if err := json.Unmarshal(downloaded.body, &manifest); err != nil {
+		info.Error = fmt.Sprintf("invalid JSON: %v", err)
+		return info, manifest
+	}
+
+	info.Fetched = true
+	info.Name = manifest.Name
+	info.ShortName = manifest.ShortName
+	info.ThemeColor = manifest.ThemeColor
+	return info, manifest
+}
+
+// probeIcon downloads an icon and records its format and largest dimensions.
+func (s *OGPService) probeIcon(ctx context.Context, icon *models.IconMetadata) {
+	downloaded, err := s.download(ctx, nil, icon.URL, "icon", maxIconBytes)
+	if downloaded != nil {
+		icon.Size = downloaded.size
+	}
+	if err != nil {
+		icon.Error = err.Error()
+		return
+	}
+	icon.Fetched = true
+
+	body := downloaded.body
+	switch {
+	case bytes.HasPrefix(body, []byte{0, 0, 1, 0}):
+		icon.Format = "ico"
+		icon.Width, icon.Height, err = s.decodeICOConfig(body)
+	case downloaded.contentType == "image/svg+xml" || bytes.Contains(body[:min(len(body), 512)], []byte("<svg")):
+		// SVG icons scale to any size
+		icon.Format = "svg"
+	default:
+		var config image.Config
+		config, icon.Format, err = image.DecodeConfig(bytes.NewReader(body))
+		icon.Width, icon.Height = config.Width, config.Height
+	}
+	if err != nil {
+		icon.Error = fmt.Sprintf("failed to decode icon: %v", err)
+	}
+}
+
+// decodeICOConfig returns the dimensions of the largest image in an ICO file.
+func (s *OGPService) decodeICOConfig(data []byte) (int, int, error) {
+	if len(data) < 6 || binary.LittleEndian.Uint16(data[2:]) != 1 {
+		return 0, 0, errors.New("not an ICO file")
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	if count == 0 || len(data) < 6+16*count {
+		return 0, 0, errors.New("truncated ICO directory")
+	}
+
+	width, height := 0, 0
+	for i := 0; i < count; i++ {
+		entry := data[6+16*i:]
+		// A zero byte means 256 pixels
+		w, h := int(entry[0]), int(entry[1])
+		if w == 0 {
+			w = 256
+		}
+		if h == 0 {
+			h = 256
+		}
+		if w*h > width*height {
+			width, height = w, h
+		}
+	}
+	return width, height, nil
+}
+
+// pickThemeColor prefers a theme-color without a media query, as used for
+// the default (light) appearance, then the manifest's.
+func (s *OGPService) pickThemeColor(tags []themeColorTag, manifestColor string) string {
+	for _, tag := range tags {
+		if tag.media == "" && tag.value != "" {
+			return tag.value
+		}
+	}
+	for _, tag := range tags {
+		if tag.value != "" {
+			return tag.value
+		}
+	}
+	return strings.TrimSpace(manifestColor)
+}
+
+// pickIcons returns the largest fetched favicon and the first fetched
+// apple-touch-icon.
+func (s *OGPService) pickIcons(icons []models.IconMetadata) (string, string) {
+	favicon, appleTouchIcon := "", ""
+	bestEdge := -1
+	for _, icon := range icons {
+		if !icon.Fetched || icon.Error != "" {
+			continue
+		}
+		switch {
+		case icon.Rel == iconRelAppleTouchIcon && appleTouchIcon == "":
+			appleTouchIcon = icon.URL
+		case icon.Rel == iconRelIcon && icon.Source != iconSourceManifest:
+			edge := icon.Width
+			if icon.Format == "svg" {
+				edge = 1 << 16
+			}
+			if edge > bestEdge {
+				favicon, bestEdge = icon.URL, edge
+			}
+		}
+	}
+	return favicon, appleTouchIcon
+}
+
+func (s *OGPService) checkIcons(icons models.SiteIcons) []models.ValidationIssue {
+	issues := []models.ValidationIssue{}
+
+	for _, icon := range icons.Icons {
+		property := icon.Rel
+		if icon.Source == iconSourceManifest {
+			property = "manifest"
+		}
+
+		if icon.Error != "" {
+			// A missing /favicon.ico is reported once as ICON_MISSING below
+			if icon.Source != iconSourceDefault {
+				issues = append(issues, s.newIssue("ICON_FETCH_FAILED", property, icon.URL, icon.URL, icon.Error))
+			}
+			continue
+		}
+		if !icon.Fetched || icon.Format == "svg" || icon.Width == 0 {
+			continue
+		}
+
+		size := fmt.Sprintf("%dx%d", icon.Width, icon.Height)
+		if icon.Width != icon.Height {
+			issues = append(issues, s.newIssue("ICON_NOT_SQUARE", property, icon.URL, icon.URL, size))
+		}
+		if match := iconSizeRegex.FindStringSubmatch(strings.ToLower(icon.Sizes)); match != nil && match[0] != size && icon.Format != "ico" {
+			issues = append(issues, s.newIssue("ICON_SIZE_MISMATCH", property, icon.URL, icon.URL, icon.Sizes, size))
+		}
+
+		minimum := minIconSizes[icon.Rel]
+		if icon.Source == iconSourceManifest {
+			minimum = minIconSizes[iconSourceManifest]
+		}
+		if icon.Width < minimum && !s.hasLargerIcon(icons.Icons, icon, minimum) {
+			issues = append(issues, s.newIssue("ICON_TOO_SMALL", property, icon.URL, icon.URL, size, strconv.Itoa(minimum)))
+		}
+	}
+
+	if icons.Favicon == "" {
+		issues = append(issues, s.newIssue("ICON_MISSING", "icon", ""))
+	}
+	if icons.AppleTouchIcon == "" {
+		issues = append(issues, s.newIssue("APPLE_TOUCH_ICON_MISSING", "apple-touch-icon", ""))
+	}
+
+	return issues
+}
+
+// hasLargerIcon reports whether another icon of the same kind meets minimum,
+// since pages commonly offer a small favicon alongside larger ones.
+func (s *OGPService) hasLargerIcon(icons []models.IconMetadata, icon models.IconMetadata, minimum int) bool {
+	for _, other := range icons {
+		if other.Rel == icon.Rel && other.Source == icon.Source && (other.Width >= minimum || other.Format == "svg") && other.Fetched && other.Error == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applySiteIcons adds the site icon to the previews of platforms that show
+// one, and colours Discord's embed with the page's theme-color. An invalid
+// colour is reported once, as THEME_COLOR_INVALID.
+func (s *OGPService) applySiteIcons(previews *models.PlatformPreviews, icons models.SiteIcons) {
+	previews.Discord.Icon = icons.Favicon
+	previews.Discord.ThemeColor, _ = s.normalizeHexColor(icons.ThemeColor)
+
+	previews.IMessage.Icon = icons.AppleTouchIcon
+	if previews.IMessage.Icon == "" {
+		previews.IMessage.Icon = icons.Favicon
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testICO(sizes ...int) []byte {
+	data := make([]byte, 6+16*len(sizes))
+	binary.LittleEndian.PutUint16(data[2:], 1)
+	binary.LittleEndian.PutUint16(data[4:], uint16(len(sizes)))
+	for i, size := range sizes {
+		data[6+16*i] = byte(size % 256)
+		data[7+16*i] = byte(size % 256)
+	}
+	return data
+}
+
+func TestOGPService_decodeICOConfig(t *testing.T) {
+	service := NewOGPService()
+
+	width, height, err := service.decodeICOConfig(testICO(16, 32, 256))
+	if err != nil {
+		t.Fatalf("decodeICOConfig() error = %v", err)
+	}
+	if width != 256 || height != 256 {
+		t.Errorf("Expected the largest 256x256 entry, got %dx%d", width, height)
+	}
+
+	if _, _, err := service.decodeICOConfig([]byte{0, 0, 1, 0, 2, 0}); err == nil {
+		t.Error("Expected error for truncated directory")
+	}
+}
+
+func TestOGPService_inspectIcons(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/favicon-16.png":
+			w.Write(testPNG(t, 16, 16))
+		case "/favicon.ico":
+			w.Write(testICO(16, 48))
+		case "/apple-touch-icon.png":
+			w.Write(testPNG(t, 120, 100))
+		case "/static/manifest.json":
+			w.Write([]byte(`{"name": "Example", "theme_color": "#000000", "icons": [{"src": "icon-192.png", "sizes": "192x192"}]}`))
+		case "/static/icon-192.png":
+			w.Write(testPNG(t, 192, 192))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	pageURL, _ := url.Parse("http://example.test/article")
+	htmlContent := `<html><head>
+		<link rel="icon" href="/favicon-16.png" sizes="32x32" />
+		<link rel="shortcut icon" href="/favicon.ico" />
+		<link rel="apple-touch-icon" href="/apple-touch-icon.png" />
+		<link rel="manifest" href="/static/manifest.json" />
+		<meta name="theme-color" content="#1e90ff" media="(prefers-color-scheme: dark)" />
+		<meta name="theme-color" content="#336699" />
+	</head></html>`
+
+	icons := service.inspectIcons(context.Background(), pageURL, htmlContent)
+
+	if len(icons.Icons) != 4 {
+		t.Fatalf("Expected 4 icons, got %+v", icons.Icons)
+	}
+	if manifestIcon := icons.Icons[3]; manifestIcon.URL != "http://example.test/static/icon-192.png" || manifestIcon.Width != 192 {
+		t.Errorf("Expected manifest icon resolved against the manifest URL, got %+v", manifestIcon)
+	}
+	if icons.Favicon != "http://example.test/favicon.ico" {
+		t.Errorf("Expected the largest favicon, got %q", icons.Favicon)
+	}
+	if icons.AppleTouchIcon != "http://example.test/apple-touch-icon.png" {
+		t.Errorf("Unexpected apple-touch-icon %q", icons.AppleTouchIcon)
+	}
+	if icons.Manifest == nil || icons.Manifest.Name != "Example" {
+		t.Errorf("Expected manifest to be read, got %+v", icons.Manifest)
+	}
+	if icons.ThemeColor != "#336699" {
+		t.Errorf("Expected theme-color without media, got %q", icons.ThemeColor)
+	}
+
+	codes := map[string]string{}
+	for _, issue := range icons.Issues {
+		codes[issue.Code] = issue.Value
+	}
+	expected := map[string]string{
+		"ICON_SIZE_MISMATCH": "http://example.test/favicon-16.png",
+		"ICON_NOT_SQUARE":    "http://example.test/apple-touch-icon.png",
+		"ICON_TOO_SMALL":     "http://example.test/apple-touch-icon.png",
+	}
+	for code, value := range expected {
+		if codes[code] != value {
+			t.Errorf("Expected %s for %s, got issues %+v", code, value, icons.Issues)
+		}
+	}
+	// The 16px favicon is fine because a 48px one is also offered
+	if len(codes) != len(expected) {
+		t.Errorf("Unexpected issues: %+v", icons.Issues)
+	}
+}
+
+func TestOGPService_inspectIconsDefaults(t *testing.T) {
+	service := newTestService(t, http.NotFoundHandler())
+	pageURL, _ := url.Parse("http://example.test/article")
+
+	icons := service.inspectIcons(context.Background(), pageURL, `<html><head><meta name="theme-color" content="rebeccapurple" /></head></html>`)
+
+	if len(icons.Icons) != 1 || icons.Icons[0].Source != "default" || icons.Icons[0].URL != "http://example.test/favicon.ico" {
+		t.Fatalf("Expected /favicon.ico fallback, got %+v", icons.Icons)
+	}
+
+	codes := []string{}
+	for _, issue := range icons.Issues {
+		codes = append(codes, issue.Code)
+	}
+	expected := []string{"THEME_COLOR_INVALID", "ICON_MISSING", "APPLE_TOUCH_ICON_MISSING"}
+	if len(codes) != len(expected) {
+		t.Fatalf("codes = %v, want %v", codes, expected)
+	}
+	for i := range expected {
+		if codes[i] != expected[i] {
+			t.Errorf("codes = %v, want %v", codes, expected)
+		}
+	}
+}
+
+func TestOGPService_inspectIconsParallel(t *testing.T) {
+	// Each icon is served only once all of them are requested, so probing
+	// them one after another would fail
+	const icons = 3
+	var mu sync.Mutex
+	requested := 0
+	all := make(chan struct{})
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested++
+		if requested == icons {
+			close(all)
+		}
+		mu.Unlock()
+
+		select {
+		case <-all:
+			w.Write(testICO(32))
+		case <-time.After(2 * time.Second):
+			http.Error(w, "probed one at a time", http.StatusServiceUnavailable)
+		}
+	}))
+	pageURL, _ := url.Parse("http://example.test/")
+
+	result := service.inspectIcons(context.Background(), pageURL, `<html><head>
+		<link rel="icon" href="/a.ico" />
+		<link rel="icon" href="/b.ico" />
+		<link rel="apple-touch-icon" href="/c.ico" />
+	</head></html>`)
+
+	if len(result.Icons) != icons {
+		t.Fatalf("Expected %d icons, got %+v", icons, result.Icons)
+	}
+	for _, icon := range result.Icons {
+		if !icon.Fetched || icon.Error != "" {
+			t.Errorf("Expected %s to be fetched, got %+v", icon.URL, icon)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+	"ogp-verification-service/internal/models"
+)
+
+// maxImageBytes caps how much of an og:image is downloaded when probing it.
+const maxImageBytes = 20 << 20
+
+// maxRenderedPixels caps the dimensions of an image decoded for a rendered
+// card; a small compressed file can otherwise decode to a huge bitmap.
+const maxRenderedPixels = 16_000_000
+
+// downloadedImage is the raw body of a resource fetched by download.
+type downloadedImage struct {
+	url         string
+	contentType string
+	size        int64
+	body        []byte
+}
+
+// probeImage fetches the og:image and records its size, type and dimensions.
+// Failures are reported in the returned metadata rather than as an error so
+// that a broken image never prevents the rest of the response from being built.
+func (s *OGPService) probeImage(ctx context.Context, pageURL *url.URL, imageURL string) models.ImageMetadata {
+	meta := models.ImageMetadata{URL: imageURL}
+
+	downloaded, err := s.downloadImage(ctx, pageURL, imageURL)
+	if downloaded != nil {
+		meta.URL = downloaded.url
+		meta.Fetched = downloaded.body != nil
+		meta.ContentType = downloaded.contentType
+		meta.Size = downloaded.size
+	}
+	if err != nil {
+		meta.Error = err.Error()
+		return meta
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(downloaded.body))
+	if err != nil {
+		meta.Error = fmt.Sprintf("failed to decode image: %v", err)
+		return meta
+	}
+	meta.Format = format
+	meta.Width = config.Width
+	meta.Height = config.Height
+
+	if config.Width*config.Height <= maxAnalyzedPixels {
+		if img, _, err := image.Decode(bytes.NewReader(downloaded.body)); err == nil {
+			analysis := s.analyzeImage(img)
+			meta.Analysis = &analysis
+		}
+	}
+
+	return meta
+}
+
+// imageProbed reports whether probeImage ran on an image: every probe ends
+// with the image fetched or an error, and the metadata stays empty when
+// probing is turned off.
+func imageProbed(info models.ImageMetadata) bool {
+	return info.Fetched || info.Error != ""
+}
+
+// FetchImage downloads and decodes an image with the same restrictions that
+// apply to og:image probing.
+func (s *OGPService) FetchImage(ctx context.Context, imageURL string) (image.Image, error) {
+	downloaded, err := s.downloadImage(ctx, nil, imageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(downloaded.body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > maxRenderedPixels {
+		return nil, fmt.Errorf("image exceeds %d pixels", maxRenderedPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(downloaded.body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// downloadImage resolves imageURL against pageURL and downloads it. When the
+// body was read but is too large, both the partial result and an error are
+// returned.
+func (s *OGPService) downloadImage(ctx context.Context, pageURL *url.URL, imageURL string) (*downloadedImage, error) {
+	return s.download(ctx, pageURL, imageURL, "image", maxImageBytes)
+}
+
+// download fetches a page resource with the same restrictions as the page
+// itself. kind names the resource in error messages.
+func (s *OGPService) download(ctx context.Context, pageURL *url.URL, rawURL, kind string, maxBytes int) (*downloadedImage, error) {
+	resolved, err := s.resolveURL(pageURL, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s URL: %w", kind, err)
+	}
+	downloaded := &downloadedImage{url: resolved.String()}
+
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return downloaded, fmt.Errorf("%s URL must use http or https", kind)
+	}
+	if s.isPrivateIP(resolved.Hostname()) {
+		return downloaded, errors.New("private IP addresses are not allowed")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", downloaded.url, nil)
+	if err != nil {
+		return downloaded, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "OGP-Verification-Service/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return downloaded, fmt.Errorf("failed to fetch %s: %w", kind, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return downloaded, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)+1))
+	if err != nil {
+		return downloaded, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+
+	downloaded.body = body
+	downloaded.contentType = strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	downloaded.size = int64(len(body))
+	if resp.ContentLength > downloaded.size {
+		downloaded.size = resp.ContentLength
+	}
+	if len(body) > maxBytes {
+		return downloaded, fmt.Errorf("%s exceeds %d bytes", kind, maxBytes)
+	}
+
+	return downloaded, nil
+}
+
+func (s *OGPService) resolveURL(base *url.URL, ref string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return parsed, nil
+	}
+	return base.ResolveReference(parsed), nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newTestService returns a service whose HTTP client sends every request to
+// handler, regardless of the host in the URL, so tests can use public-looking
+// hostnames without tripping the private IP check.
+func newTestService(t *testing.T, handler http.Handler) *OGPService {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	addr := srv.Listener.Addr().String()
+	service := NewOGPService()
+	service.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	return service
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader returns the signature and IHDR chunk of a PNG claiming the given
+// dimensions, which is all image.DecodeConfig reads.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12] = 8 // bit depth
+	ihdr[13] = 6 // RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func TestOGPService_FetchImage(t *testing.T) {
+	imageData := testPNG(t, 1200, 630)
+	hugeData := pngHeader(50000, 50000)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(imageData)
+	})
+	mux.HandleFunc("/huge.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(hugeData)
+	})
+	service := newTestService(t, mux)
+
+	img, err := service.FetchImage(context.Background(), "http://example.test/image.png")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 1200 || bounds.Dy() != 630 {
+		t.Errorf("Expected a 1200x630 image, got %v", bounds)
+	}
+
+	// A tiny file claiming huge dimensions is rejected before decoding
+	if _, err := service.FetchImage(context.Background(), "http://example.test/huge.png"); err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Errorf("Expected a pixel limit error, got %v", err)
+	}
+}
+
+func TestOGPService_probeImage(t *testing.T) {
+	imageData := testPNG(t, 1200, 630)
+	largeData := testPNG(t, 2500, 1700)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(imageData)
+	})
+	mux.HandleFunc("/large.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(largeData)
+	})
+	mux.HandleFunc("/broken.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("not an image"))
+	})
+	service := newTestService(t, mux)
+
+	pageURL, _ := url.Parse("http://example.test/articles/1")
+
+	t.Run("relative URL", func(t *testing.T) {
+		meta := service.probeImage(context.Background(), pageURL, "/image.png")
+		if !meta.Fetched {
+			t.Fatalf("Expected image to be fetched, got error %q", meta.Error)
+		}
+		if meta.URL != "http://example.test/image.png" {
+			t.Errorf("Expected resolved URL, got %s", meta.URL)
+		}
+		if meta.Width != 1200 || meta.Height != 630 {
+			t.Errorf("Expected 1200x630, got %dx%d", meta.Width, meta.Height)
+		}
+		if meta.Format != "png" || meta.ContentType != "image/png" {
+			t.Errorf("Expected png image/png, got %s %s", meta.Format, meta.ContentType)
+		}
+		if meta.Size != int64(len(imageData)) {
+			t.Errorf("Expected size %d, got %d", len(imageData), meta.Size)
+		}
+		if meta.Analysis == nil {
+			t.Error("Expected image to be analysed")
+		}
+	})
+
+	t.Run("image too large to analyse", func(t *testing.T) {
+		meta := service.probeImage(context.Background(), pageURL, "/large.png")
+		if !meta.Fetched || meta.Width != 2500 || meta.Height != 1700 {
+			t.Fatalf("Expected 2500x1700 image to be fetched, got %+v", meta)
+		}
+		if meta.Analysis != nil {
+			t.Errorf("Expected no analysis above %d pixels, got %+v", maxAnalyzedPixels, meta.Analysis)
+		}
+	})
+
+	t.Run("undecodable image", func(t *testing.T) {
+		meta := service.probeImage(context.Background(), pageURL, "http://example.test/broken.png")
+		if !meta.Fetched || meta.Error == "" {
+			t.Errorf("Expected fetched image with decode error, got %+v", meta)
+		}
+	})
+
+	t.Run("missing image", func(t *testing.T) {
+		meta := service.probeImage(context.Background(), pageURL, "http://example.test/missing.png")
+		if meta.Fetched || meta.Error == "" {
+			t.Errorf("Expected fetch error, got %+v", meta)
+		}
+	})
+
+	t.Run("private host", func(t *testing.T) {
+		meta := service.probeImage(context.Background(), pageURL, "http://127.0.0.1/image.png")
+		if meta.Fetched || meta.Error != "private IP addresses are not allowed" {
+			t.Errorf("Expected private IP error, got %+v", meta)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	docsOGPMetadata     = "https://ogp.me/#metadata"
+	docsOGPImage        = "https://ogp.me/#structured"
+	docsOGPArrays       = "https://ogp.me/#array"
+	docsOGPOptional     = "https://ogp.me/#optional"
+	docsOGPTypes        = "https://ogp.me/#types"
+	docsFacebookSharing = "https://developers.facebook.com/docs/sharing/webmasters"
+	docsFacebookImages  = "https://developers.facebook.com/docs/sharing/webmasters/images"
+	docsFavicon         = "https://developers.google.com/search/docs/appearance/favicon-in-search"
+	docsAppleTouchIcon  = "https://developer.apple.com/library/archive/documentation/AppleApplications/Reference/SafariWebContent/ConfiguringWebApplications/ConfiguringWebApplications.html"
+	docsManifest        = "https://developer.mozilla.org/en-US/docs/Web/Manifest"
+	docsThemeColor      = "https://developer.mozilla.org/en-US/docs/Web/HTML/Element/meta/name/theme-color"
+	docsTitleLink       = "https://developers.google.com/search/docs/appearance/title-link"
+	docsSnippet         = "https://developers.google.com/search/docs/appearance/snippet"
+	docsCanonical       = "https://developers.google.com/search/docs/crawling-indexing/consolidate-duplicate-urls"
+	docsNoindex         = "https://developers.google.com/search/docs/crawling-indexing/block-indexing"
+	docsHreflang        = "https://developers.google.com/search/docs/specialty/international/localized-versions"
+	docsOGPCanonical    = "https://developers.facebook.com/docs/sharing/webmasters/getting-started/versioned-link"
+	docsImageAlt        = "https://www.w3.org/WAI/tutorials/images/informative/"
+	docsTwitterImageAlt = "https://developer.x.com/en/docs/x-for-websites/cards/overview/markup"
+	docsImagesOfText    = "https://www.w3.org/WAI/WCAG21/Understanding/images-of-text.html"
+	docsContrast        = "https://www.w3.org/WAI/WCAG21/Understanding/contrast-minimum.html"
+)
+
+// issueDefinition is the catalog entry for an issue code. Message is the
+// English fmt format string filled in with the parameters given to newIssue;
+// translations live in issueTranslations.
+type issueDefinition struct {
+	Severity string
+	Message  string
+	DocsURL  string
+}
+
+var issueCatalog = map[string]issueDefinition{
+	"OG_TITLE_MISSING":             {models.SeverityWarning, "Missing og:title tag", docsOGPMetadata},
+	"OG_DESCRIPTION_MISSING":       {models.SeverityWarning, "Missing og:description tag", docsOGPMetadata},
+	"OG_IMAGE_MISSING":             {models.SeverityWarning, "Missing og:image tag", docsOGPMetadata},
+	"OG_IMAGE_INVALID":             {models.SeverityError, "Invalid image URL", docsOGPImage},
+	"OG_DUPLICATE_PROPERTY":        {models.SeverityWarning, "%s appears %s times; platforms use the first value %q", docsOGPArrays},
+	"OG_TAG_IN_BODY":               {models.SeverityWarning, "%s is inside <body>; crawlers that only read <head> will miss it", docsOGPMetadata},
+	"OG_NAME_ATTRIBUTE":            {models.SeverityWarning, "%s uses name= instead of property=; Facebook ignores it", docsOGPMetadata},
+	"OG_LOCALE_INVALID":            {models.SeverityWarning, "%q is not a valid language_TERRITORY locale", docsOGPOptional},
+	"OG_LOCALE_MISSING":            {models.SeverityWarning, "Missing og:locale; platforms assume en_US but <html lang> is %q", docsOGPOptional},
+	"OG_LOCALE_HTML_LANG_MISMATCH": {models.SeverityWarning, "og:locale (%s) does not match <html lang> (%s)", docsOGPOptional},
+	"OG_LOCALE_ALTERNATE_SELF":     {models.SeverityWarning, "og:locale:alternate %q repeats og:locale", docsOGPOptional},
+	"OG_LOCALE_NO_HREFLANG":        {models.SeverityWarning, "og:locale:alternate %q has no matching hreflang link", docsHreflang},
+	"OG_EMPTY_CONTENT":             {models.SeverityWarning, "%s has an empty content attribute", docsOGPMetadata},
+
+	"FB_APP_ID_MISSING":              {models.SeverityWarning, "Missing fb:app_id", docsFacebookSharing},
+	"FB_APP_ID_INVALID":              {models.SeverityWarning, "fb:app_id %q is not a numeric app ID", docsFacebookSharing},
+	"FB_OG_TYPE_UNKNOWN":             {models.SeverityWarning, "og:type %q is not a type Facebook recognises", docsOGPTypes},
+	"FB_INFERRED_PROPERTY":           {models.SeverityWarning, "%s should be explicitly provided, even if Facebook can infer it", docsFacebookSharing},
+	"FB_IMAGE_TOO_SMALL":             {models.SeverityWarning, "og:image is %s; Facebook requires at least 200x200", docsFacebookImages},
+	"FB_IMAGE_SMALL_PREVIEW":         {models.SeverityInfo, "og:image is %s; Facebook shows images smaller than 600x315 as a small thumbnail", docsFacebookImages},
+	"FB_IMAGE_ASPECT_RATIO":          {models.SeverityInfo, "og:image aspect ratio is %s:1; Facebook crops link images to 1.91:1", docsFacebookImages},
+	"FB_IMAGE_DIMENSIONS_UNDECLARED": {models.SeverityInfo, "og:image:width and og:image:height are not set; the image may not appear on the first share", docsFacebookImages},
+
+	"A11Y_IMAGE_ALT_MISSING":     {models.SeverityWarning, "og:image has no og:image:alt; screen reader users get no description of the image", docsImageAlt},
+	"A11Y_IMAGE_ALT_PLACEHOLDER": {models.SeverityWarning, "%s %q does not describe the image", docsImageAlt},
+	"A11Y_IMAGE_ALT_TOO_LONG":    {models.SeverityWarning, "%s is %s characters; X cuts image descriptions at 420", docsTwitterImageAlt},
+	"A11Y_TWITTER_ALT_MISMATCH":  {models.SeverityInfo, "twitter:image:alt %q differs from og:image:alt %q; X and other platforms describe the image differently", docsTwitterImageAlt},
+	"A11Y_IMAGE_TEXT":            {models.SeverityInfo, "og:image appears to contain text; repeat it in og:image:alt", docsImagesOfText},
+	"A11Y_IMAGE_LOW_CONTRAST":    {models.SeverityWarning, "Text in og:image may be hard to read (contrast %s:1, below 4.5:1)", docsContrast},
+
+	"ICON_MISSING":             {models.SeverityWarning, "No favicon found; platforms will show a generic icon", docsFavicon},
+	"ICON_FETCH_FAILED":        {models.SeverityWarning, "Icon %s could not be fetched: %s", docsFavicon},
+	"ICON_NOT_SQUARE":          {models.SeverityWarning, "Icon %s is not square (%s)", docsFavicon},
+	"ICON_SIZE_MISMATCH":       {models.SeverityWarning, "Icon %s declares sizes %s but is %s", docsFavicon},
+	"ICON_TOO_SMALL":           {models.SeverityWarning, "Icon %s is %s; at least %spx is recommended", docsFavicon},
+	"APPLE_TOUCH_ICON_MISSING": {models.SeverityInfo, "No apple-touch-icon; iMessage shows it when there is no og:image", docsAppleTouchIcon},
+	"MANIFEST_INVALID":         {models.SeverityWarning, "Web app manifest %s could not be read: %s", docsManifest},
+	"THEME_COLOR_INVALID":      {models.SeverityWarning, "theme-color %q is not a hex colour; Discord will not use it for the embed", docsThemeColor},
+
+	"SEO_TITLE_MISSING":         {models.SeverityWarning, "Missing <title> tag", docsTitleLink},
+	"SEO_TITLE_MULTIPLE":        {models.SeverityWarning, "Multiple <title> tags found", docsTitleLink},
+	"SEO_DESCRIPTION_MISSING":   {models.SeverityWarning, "Missing meta description", docsSnippet},
+	"SEO_NOINDEX":               {models.SeverityWarning, "Page is marked noindex and will not appear in search results", docsNoindex},
+	"SEO_CANONICAL_MISSING":     {models.SeverityWarning, "Missing canonical link", docsCanonical},
+	"SEO_CANONICAL_MULTIPLE":    {models.SeverityWarning, "Multiple canonical links found", docsCanonical},
+	"SEO_CANONICAL_RELATIVE":    {models.SeverityWarning, "Canonical URL should be absolute", docsCanonical},
+	"OG_URL_CANONICAL_MISMATCH": {models.SeverityWarning, "og:url (%s) does not match canonical URL (%s)", docsOGPCanonical},
+	"HREFLANG_INVALID":          {models.SeverityWarning, "Invalid hreflang value %q", docsHreflang},
+	"HREFLANG_RELATIVE":         {models.SeverityWarning, "hreflang %q URL should be absolute", docsHreflang},
+	"HREFLANG_CONFLICT":         {models.SeverityWarning, "hreflang %q points to multiple URLs", docsHreflang},
+	"HREFLANG_NO_SELF":          {models.SeverityWarning, "hreflang links do not include this page", docsHreflang},
+
+	// Preview issues are reported per platform in PlatformPreview.Issues
+	"PREVIEW_TITLE_TOO_LONG":       {models.SeverityWarning, "Title exceeds %s limit (%s characters)", ""},
+	"PREVIEW_DESCRIPTION_TOO_LONG": {models.SeverityWarning, "Description exceeds %s limit (%s characters)", ""},
+	"PREVIEW_TITLE_CUT_OFF":        {models.SeverityWarning, "Title is predicted to be cut off on %s after %s characters", ""},
+	"PREVIEW_DESCRIPTION_CUT_OFF":  {models.SeverityWarning, "Description is predicted to be cut off on %s after %s characters", ""},
+	"PREVIEW_IMAGE_NOT_FETCHED":    {models.SeverityWarning, "Image could not be fetched for %s size check", ""},
+	"PREVIEW_IMAGE_NOT_PROBED":     {models.SeverityInfo, "Image probing is turned off, so the image was not checked for %s", ""},
+
+	"DISCORD_IMAGE_LARGE":           {models.SeverityInfo, "Discord shows the image full width below the description", ""},
+	"DISCORD_IMAGE_THUMBNAIL":       {models.SeverityInfo, "Discord shows the image as a thumbnail; set twitter:card to summary_large_image for a large image", ""},
+	"MASTODON_CREATOR_INVALID":      {models.SeverityWarning, "fediverse:creator %q is not a valid @user@domain handle", ""},
+	"MASTODON_ONE_CARD":             {models.SeverityInfo, "Mastodon shows a card only for the first link in a post, and none when the post has media or a poll", ""},
+	"BLUESKY_IMAGE_TOO_LARGE":       {models.SeverityWarning, "Image exceeds Bluesky size limit (%s bytes)", ""},
+	"BLUESKY_IMAGE_CROPPED":         {models.SeverityWarning, "Image will be cropped to 1.91:1 on Bluesky", ""},
+	"TELEGRAM_CHANNEL_INVALID":      {models.SeverityWarning, "telegram:channel %q is not a valid channel username", ""},
+	"TELEGRAM_INSTANT_VIEW_CHANNEL": {models.SeverityInfo, "Instant View will link to %s", ""},
+	"TELEGRAM_INSTANT_VIEW_ARTICLE": {models.SeverityInfo, "Article pages can open in Instant View when a template exists for the site", ""},
+	"TELEGRAM_IMAGE_TOO_LARGE":      {models.SeverityWarning, "Image exceeds Telegram limit (5MB); preview will be shown without image", ""},
+	"TELEGRAM_IMAGE_LARGE":          {models.SeverityInfo, "Telegram shows the image as a large photo", ""},
+	"TELEGRAM_IMAGE_THUMBNAIL":      {models.SeverityInfo, "Telegram shows the image as a small thumbnail", ""},
+	"WHATSAPP_IMAGE_TOO_LARGE":      {models.SeverityWarning, "Image exceeds WhatsApp limit (300KB); preview will be shown without image", ""},
+	"WHATSAPP_IMAGE_TOO_NARROW":     {models.SeverityWarning, "Image is narrower than 300px; WhatsApp may not show it", ""},
+	"IMESSAGE_NO_DESCRIPTION":       {models.SeverityInfo, "iMessage does not display og:description", ""},
+	"IMESSAGE_APPLE_TOUCH_ICON":     {models.SeverityInfo, "iMessage falls back to apple-touch-icon", ""},
+	"IMESSAGE_NO_IMAGE":             {models.SeverityWarning, "No usable og:image or apple-touch-icon; iMessage will show a plain link", ""},
+	"SEARCH_TITLE_TOO_WIDE":         {models.SeverityWarning, "Title exceeds Google limit (600px)", ""},
+	"SEARCH_DESCRIPTION_TOO_WIDE":   {models.SeverityWarning, "Description exceeds Google limit (920px)", ""},
+	"SEARCH_NOINDEX":                {models.SeverityWarning, "Page is noindex and will not be shown in search results", ""},
+}
+
+// newIssue builds an issue from the catalog. property and value identify the
+// offending tag; params fill in the catalog message.
+func (s *OGPService) newIssue(code, property, value string, params ...string) models.ValidationIssue {
+	definition, ok := issueCatalog[code]
+	if !ok {
+		definition = issueDefinition{Severity: models.SeverityWarning, Message: code}
+	}
+
+	return models.ValidationIssue{
+		Code:     code,
+		Severity: definition.Severity,
+		Message:  s.formatMessage(definition.Message, params),
+		Property: property,
+		Value:    value,
+		Params:   params,
+		DocsURL:  definition.DocsURL,
+	}
+}
+
+func (s *OGPService) formatMessage(format string, params []string) string {
+	if len(params) == 0 {
+		return format
+	}
+	args := make([]interface{}, len(params))
+	for i, param := range params {
+		args[i] = param
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// issueMessages derives the legacy warning and error lists from issues.
+func (s *OGPService) issueMessages(issues []models.ValidationIssue) ([]string, []string) {
+	warnings := []string{}
+	errors := []string{}
+	for _, issue := range issues {
+		switch issue.Severity {
+		case models.SeverityError:
+			errors = append(errors, issue.Message)
+		case models.SeverityWarning:
+			warnings = append(warnings, issue.Message)
+		}
+	}
+	return warnings, errors
+}
+
+func (s *OGPService) hasErrors(issues []models.ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == models.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// locateIssues fills in the source line and column of each issue not yet
+// located from the first tag carrying its property, preferring one whose
+// value matches.
+func (s *OGPService) locateIssues(issues []models.ValidationIssue, tags []sourceTag) {
+	for i := range issues {
+		issue := &issues[i]
+		if issue.Property == "" || issue.Line != 0 {
+			continue
+		}
+
+		var match *sourceTag
+		for j := range tags {
+			if tags[j].key != issue.Property {
+				continue
+			}
+			if match == nil {
+				match = &tags[j]
+			}
+			if issue.Value != "" && tags[j].value == issue.Value {
+				match = &tags[j]
+				break
+			}
+		}
+
+		if match != nil {
+			issue.Line = match.line
+			issue.Column = match.column
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_validateOGPDataIssues(t *testing.T) {
+	service := NewOGPService()
+
+	result := service.validateOGPData(RuleInput{OGPData: models.OGPData{
+		Title: "Title",
+		Image: "http://[::1",
+	}}, onlyRules(service, "OG_TITLE_MISSING", "OG_DESCRIPTION_MISSING", "OG_IMAGE_MISSING", "OG_IMAGE_INVALID"))
+
+	codes := []string{}
+	for _, issue := range result.Issues {
+		codes = append(codes, issue.Code)
+		if issue.DocsURL == "" {
+			t.Errorf("issue %s has no docs URL", issue.Code)
+		}
+	}
+	expected := []string{"OG_DESCRIPTION_MISSING", "OG_IMAGE_INVALID"}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Fatalf("codes = %v, want %v", codes, expected)
+	}
+
+	invalid := result.Issues[1]
+	if invalid.Severity != models.SeverityError || invalid.Property != "og:image" || invalid.Value != "http://[::1" {
+		t.Errorf("unexpected image issue: %+v", invalid)
+	}
+	if result.IsValid {
+		t.Error("expected result with an error issue to be invalid")
+	}
+	if !reflect.DeepEqual(result.Warnings, []string{"Missing og:description tag"}) {
+		t.Errorf("warnings = %v", result.Warnings)
+	}
+	if !reflect.DeepEqual(result.Errors, []string{"Invalid image URL"}) {
+		t.Errorf("errors = %v", result.Errors)
+	}
+}
+
+func TestOGPService_locateIssues(t *testing.T) {
+	service := NewOGPService()
+	pageURL, _ := url.Parse("https://example.com/article")
+	htmlContent := "<html><head>\n" +
+		"<title>記事</title>\n" +
+		"  <meta property=\"og:url\" content=\"https://example.com/other\" />\n" +
+		"<link rel=\"canonical\" href=\"https://example.com/article\" />\n" +
+		"<link rel=\"alternate\" hreflang=\"en_US\" href=\"https://example.com/article\" />\n" +
+		"</head></html>"
+
+	ogpData := service.parseOGPTags(htmlContent)
+	seo := service.validateSEO(pageURL, ogpData, service.parseSEOTags(htmlContent), "")
+	service.locateIssues(seo.Issues, service.scanSourceTags(htmlContent))
+
+	positions := map[string][2]int{}
+	for _, issue := range seo.Issues {
+		positions[issue.Code] = [2]int{issue.Line, issue.Column}
+	}
+
+	tests := []struct {
+		code     string
+		expected [2]int
+	}{
+		{"OG_URL_CANONICAL_MISMATCH", [2]int{3, 3}},
+		{"HREFLANG_INVALID", [2]int{5, 1}},
+		// Missing tags have nowhere to point
+		{"SEO_DESCRIPTION_MISSING", [2]int{0, 0}},
+	}
+	for _, tt := range tests {
+		position, ok := positions[tt.code]
+		if !ok {
+			t.Errorf("expected issue %s, got %+v", tt.code, seo.Issues)
+			continue
+		}
+		if position != tt.expected {
+			t.Errorf("%s position = %v, want %v", tt.code, position, tt.expected)
+		}
+	}
+}
+
+func TestOGPService_scanSourceTags(t *testing.T) {
+	service := NewOGPService()
+	htmlContent := "<head><title>日本語</title><meta property=\"og:title\" content=\"a\"></head>\n" +
+		"<body><meta name=\"description\" content=\"b\"></body>"
+
+	tags := service.scanSourceTags(htmlContent)
+	if len(tags) != 3 {
+		t.Fatalf("expected 3 tags, got %+v", tags)
+	}
+
+	ogTitle := tags[1]
+	// Columns count runes, so the Japanese title counts 3 columns rather than 9 bytes
+	if ogTitle.key != "og:title" || ogTitle.line != 1 || ogTitle.column != 25 || ogTitle.inBody {
+		t.Errorf("unexpected og:title tag: %+v", ogTitle)
+	}
+	if description := tags[2]; description.key != "description" || description.line != 2 || !description.inBody {
+		t.Errorf("unexpected description tag: %+v", description)
+	}
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// DefaultJobWorkers is how many jobs run at once.
+	DefaultJobWorkers = 2
+	// DefaultJobQueueSize bounds the jobs waiting for a worker.
+	DefaultJobQueueSize = 20
+	// DefaultJobRetention is how long a finished job can still be fetched.
+	DefaultJobRetention = time.Hour
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobQueueFull = errors.New("job queue is full")
+	ErrJobsClosed   = errors.New("job manager is shutting down")
+	ErrJobFinished  = errors.New("job has already finished")
+)
+
+// JobStore keeps the state of jobs. Save is called every time a job changes,
+// so an implementation backed by a database lets clients follow jobs across
+// instances; MemoryJobStore is enough for a single instance.
+type JobStore interface {
+	Save(job models.Job) error
+	// Get returns ErrJobNotFound for unknown or expired jobs.
+	Get(id string) (models.Job, error)
+}
+
+// MemoryJobStore keeps jobs in memory and forgets finished jobs after the
+// retention period.
+type MemoryJobStore struct {
+	mu        sync.RWMutex
+	jobs      map[string]models.Job
+	retention time.Duration
+}
+
+func NewMemoryJobStore(retention time.Duration) *MemoryJobStore {
+	return &MemoryJobStore{jobs: map[string]models.Job{}, retention: retention}
+}
+
+func (s *MemoryJobStore) Save(job models.Job) error {
+	// The caller keeps appending to Results
+	job.Results = append([]models.BatchResult(nil), job.Results...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	for id, stored := range s.jobs {
+		if s.expired(stored) {
+			delete(s.jobs, id)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryJobStore) Get(id string) (models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok || s.expired(job) {
+		return models.Job{}, ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (s *MemoryJobStore) expired(job models.Job) bool {
+	return job.FinishedAt != nil && time.Since(*job.FinishedAt) > s.retention
+}
+
+// JobManager runs batches and crawls in the background on a fixed number of
+// workers, with a bounded queue in front of them.
+type JobManager struct {
+	service *OGPService
+	store   JobStore
+	queue   chan *jobRun
+
+	mu       sync.Mutex
+	active   map[string]*jobRun
+	closed   bool
+	onFinish func(job models.Job)
+
+	// ctx is cancelled when Shutdown gives up waiting for running jobs.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// jobRun is a queued or running job. Its fields are guarded by the
+// manager's mutex.
+type jobRun struct {
+	job       models.Job
+	request   models.JobRequest
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func NewJobManager(service *OGPService, store JobStore, workers, queueSize int) *JobManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &JobManager{
+		service: service,
+		store:   store,
+		queue:   make(chan *jobRun, queueSize),
+		active:  map[string]*jobRun{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+	return m
+}
+
+// OnFinish sets fn to be called with every job that succeeds, fails or is
+// cancelled. It is called with the manager locked, so it must not block or
+// call back into the manager. It must be set before jobs are submitted.
+func (m *JobManager) OnFinish(fn func(job models.Job)) {
+	m.onFinish = fn
+}
+
+// ValidateJob checks a job request the same way the synchronous endpoint
+// for its type would.
+func (s *OGPService) ValidateJob(req models.JobRequest) error {
+	switch req.Type {
+	case models.JobTypeBatch:
+		if req.Batch == nil {
+			return fmt.Errorf("batch is required for %s jobs", req.Type)
+		}
+		return s.ValidateBatch(req.Batch.URLs, req.Batch.VerifyOptions)
+	case models.JobTypeCrawl:
+		if req.Crawl == nil {
+			return fmt.Errorf("crawl is required for %s jobs", req.Type)
+		}
+		return s.ValidateCrawl(*req.Crawl)
+	default:
+		return fmt.Errorf("invalid job type %q", req.Type)
+	}
+}
+
+// Submit queues a job and returns it in the queued state. It fails with
+// ErrJobQueueFull when no more jobs can wait and ErrJobsClosed after
+// Shutdown.
+func (m *JobManager) Submit(req models.JobRequest) (models.Job, error) {
+	if err := m.service.ValidateJob(req); err != nil {
+		return models.Job{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	run := &jobRun{
+		job: models.Job{
+			ID:        id,
+			Type:      req.Type,
+			Status:    models.JobStatusQueued,
+			CreatedAt: time.Now(),
+		},
+		request: req,
+	}
+	if req.Type == models.JobTypeBatch {
+		run.job.Progress.Total = len(req.Batch.URLs)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return models.Job{}, ErrJobsClosed
+	}
+	select {
+	case m.queue <- run:
+	default:
+		return models.Job{}, ErrJobQueueFull
+	}
+	m.active[id] = run
+	m.save(run)
+	return run.job, nil
+}
+
+// Get returns the current state of a job, including partial results while
+// it runs.
+func (m *JobManager) Get(id string) (models.Job, error) {
+	return m.store.Get(id)
+}
+
+// Cancel stops a job. A queued job is cancelled at once; a running job
+// stops after the URLs in flight and keeps the results it already has.
+func (m *JobManager) Cancel(id string) (models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.active[id]
+	if !ok {
+		job, err := m.store.Get(id)
+		if err != nil {
+			return models.Job{}, err
+		}
+		return job, ErrJobFinished
+	}
+
+	run.cancelled = true
+	if run.cancel != nil {
+		run.cancel()
+		return run.job, nil
+	}
+	m.finish(run, models.JobStatusCancelled)
+	return run.job, nil
+}
+
+// Shutdown stops accepting jobs and waits for the queued and running ones
+// to finish. When ctx ends first, the remaining jobs are cancelled and
+// Shutdown returns ctx's error once the workers have stopped.
+func (m *JobManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (m *JobManager) work() {
+	defer m.wg.Done()
+	for run := range m.queue {
+		m.run(run)
+	}
+}
+
+func (m *JobManager) run(run *jobRun) {
+	m.mu.Lock()
+	if run.cancelled {
+		m.mu.Unlock()
+		return
+	}
+	if m.ctx.Err() != nil {
+		m.finish(run, models.JobStatusCancelled)
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	run.cancel = cancel
+	now := time.Now()
+	run.job.Status = models.JobStatusRunning
+	run.job.StartedAt = &now
+	m.save(run)
+	m.mu.Unlock()
+
+	progress := func(result models.BatchResult, total int) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		run.job.Results = append(run.job.Results, result)
+		run.job.Progress.Completed++
+		run.job.Progress.Total = max(run.job.Progress.Total, total)
+		m.save(run)
+	}
+
+	var batch *models.BatchResponse
+	var crawl *models.CrawlReport
+	var err error
+	switch run.request.Type {
+	case models.JobTypeBatch:
+		batch, err = m.service.VerifyBatchWithProgress(ctx, run.request.Batch.URLs, run.request.Batch.VerifyOptions, progress)
+	case models.JobTypeCrawl:
+		crawl, err = m.service.CrawlWithProgress(ctx, *run.request.Crawl, progress)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case run.cancelled || ctx.Err() != nil:
+		m.finish(run, models.JobStatusCancelled)
+	case err != nil:
+		run.job.Error = err.Error()
+		m.finish(run, models.JobStatusFailed)
+	default:
+		run.job.Batch = batch
+		run.job.Crawl = crawl
+		run.job.Results = nil
+		m.finish(run, models.JobStatusSucceeded)
+	}
+}
+
+// finish records the final status of a job. The caller holds m.mu.
+func (m *JobManager) finish(run *jobRun, status string) {
+	now := time.Now()
+	run.job.Status = status
+	run.job.FinishedAt = &now
+	delete(m.active, run.job.ID)
+	m.save(run)
+	if m.onFinish != nil {
+		m.onFinish(run.job)
+	}
+}
+
+// save writes the job to the store. The caller holds m.mu so that updates
+// reach the store in order.
+func (m *JobManager) save(run *jobRun) {
+	if err := m.store.Save(run.job); err != nil {
+		log.Printf("Job %s not saved: %v", run.job.ID, err)
+	}
+}
+
+// newID returns a random 128-bit hex ID for jobs, webhooks and deliveries.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
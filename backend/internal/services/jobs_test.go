@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+// waitForJob polls the job until done reports true.
+func waitForJob(t *testing.T, jobs *JobManager, id string, done func(models.Job) bool) models.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := jobs.Get(id)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if done(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job %s did not reach the expected state, last %+v", id, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func batchJob(urls ...string) models.JobRequest {
+	return models.JobRequest{Type: models.JobTypeBatch, Batch: &models.BatchRequest{URLs: urls}}
+}
+
+func TestJobManager_Batch(t *testing.T) {
+	release := make(chan struct{})
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		fmt.Fprintf(w, `<html><head><meta property="og:title" content="%s" /></head></html>`, r.URL.Path)
+	}))
+	jobs := NewJobManager(service, NewMemoryJobStore(time.Hour), 1, 1)
+	defer jobs.Shutdown(context.Background())
+
+	job, err := jobs.Submit(batchJob("http://a.example.test/fast", "http://b.example.test/slow"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if job.Status != models.JobStatusQueued || job.Progress.Total != 2 {
+		t.Errorf("Expected a queued job of 2 URLs, got %+v", job)
+	}
+
+	partial := waitForJob(t, jobs, job.ID, func(j models.Job) bool { return j.Progress.Completed == 1 })
+	if partial.Status != models.JobStatusRunning || len(partial.Results) != 1 || partial.Results[0].URL != "http://a.example.test/fast" {
+		t.Errorf("Expected the fast URL as a partial result, got %+v", partial)
+	}
+	close(release)
+
+	done := waitForJob(t, jobs, job.ID, func(j models.Job) bool { return j.Status != models.JobStatusRunning })
+	if done.Status != models.JobStatusSucceeded {
+		t.Fatalf("Expected the job to succeed, got %+v", done)
+	}
+	if done.Batch == nil || done.Batch.Summary.Succeeded != 2 || done.Results != nil {
+		t.Errorf("Expected the full batch to replace the partial results, got %+v", done)
+	}
+	if done.StartedAt == nil || done.FinishedAt == nil {
+		t.Errorf("Expected start and finish times, got %+v", done)
+	}
+}
+
+func TestJobManager_Cancel(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	jobs := NewJobManager(service, NewMemoryJobStore(time.Hour), 1, 2)
+	defer jobs.Shutdown(context.Background())
+
+	running, err := jobs.Submit(batchJob("http://a.example.test/"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	queued, err := jobs.Submit(batchJob("http://b.example.test/"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	waitForJob(t, jobs, running.ID, func(j models.Job) bool { return j.Status == models.JobStatusRunning })
+
+	third, err := jobs.Submit(batchJob("http://c.example.test/"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer jobs.Cancel(third.ID)
+	if _, err := jobs.Submit(batchJob("http://d.example.test/")); !errors.Is(err, ErrJobQueueFull) {
+		t.Errorf("Expected ErrJobQueueFull, got %v", err)
+	}
+
+	job, err := jobs.Cancel(queued.ID)
+	if err != nil || job.Status != models.JobStatusCancelled {
+		t.Errorf("Expected the queued job to be cancelled at once, got %+v, %v", job, err)
+	}
+	if _, err := jobs.Cancel(queued.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("Expected ErrJobFinished, got %v", err)
+	}
+
+	if _, err := jobs.Cancel(running.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	job = waitForJob(t, jobs, running.ID, func(j models.Job) bool { return j.FinishedAt != nil })
+	if job.Status != models.JobStatusCancelled || len(job.Results) != 1 {
+		t.Errorf("Expected the running job to stop with its partial result, got %+v", job)
+	}
+
+	if _, err := jobs.Get("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestJobManager_Shutdown(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host == "slow.example.test" {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, `<html><head><title>Fast</title></head></html>`)
+	}))
+
+	t.Run("drains queued jobs", func(t *testing.T) {
+		jobs := NewJobManager(service, NewMemoryJobStore(time.Hour), 1, 5)
+		ids := []string{}
+		for i := 0; i < 3; i++ {
+			job, err := jobs.Submit(batchJob(fmt.Sprintf("http://fast.example.test/%d", i)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			ids = append(ids, job.ID)
+		}
+
+		if err := jobs.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, id := range ids {
+			if job, _ := jobs.Get(id); job.Status != models.JobStatusSucceeded {
+				t.Errorf("Expected job %s to finish before shutdown returned, got %s", id, job.Status)
+			}
+		}
+		if _, err := jobs.Submit(batchJob("http://fast.example.test/")); !errors.Is(err, ErrJobsClosed) {
+			t.Errorf("Expected ErrJobsClosed, got %v", err)
+		}
+	})
+
+	t.Run("cancels jobs after the deadline", func(t *testing.T) {
+		jobs := NewJobManager(service, NewMemoryJobStore(time.Hour), 1, 5)
+		running, _ := jobs.Submit(batchJob("http://slow.example.test/"))
+		queued, _ := jobs.Submit(batchJob("http://fast.example.test/"))
+		waitForJob(t, jobs, running.ID, func(j models.Job) bool { return j.Status == models.JobStatusRunning })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := jobs.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected DeadlineExceeded, got %v", err)
+		}
+		for _, id := range []string{running.ID, queued.ID} {
+			if job, _ := jobs.Get(id); job.Status != models.JobStatusCancelled {
+				t.Errorf("Expected job %s to be cancelled, got %s", id, job.Status)
+			}
+		}
+	})
+}
+
+func TestOGPService_ValidateJob(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name    string
+		req     models.JobRequest
+		wantErr string
+	}{
+		{"batch", batchJob("https://example.com/"), ""},
+		{"crawl", models.JobRequest{Type: models.JobTypeCrawl, Crawl: &models.CrawlRequest{StartURL: "https://example.com/"}}, ""},
+		{"unknown type", models.JobRequest{Type: "render"}, `invalid job type "render"`},
+		{"missing batch", models.JobRequest{Type: models.JobTypeBatch}, "batch is required for batch jobs"},
+		{"empty batch", batchJob(), "urls is required"},
+		{"invalid crawl", models.JobRequest{Type: models.JobTypeCrawl, Crawl: &models.CrawlRequest{}}, "exactly one of sitemap_url and start_url is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateJob(tt.req)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMemoryJobStore_Retention(t *testing.T) {
+	store := NewMemoryJobStore(time.Minute)
+	finished := time.Now().Add(-2 * time.Minute)
+
+	store.Save(models.Job{ID: "old", Status: models.JobStatusSucceeded, FinishedAt: &finished})
+	store.Save(models.Job{ID: "running", Status: models.JobStatusRunning})
+
+	if _, err := store.Get("old"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected the expired job to be gone, got %v", err)
+	}
+	if _, err := store.Get("running"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// MaxAlternateFetches caps how many og:locale:alternate pages are fetched
+	// for a single request.
+	MaxAlternateFetches = 10
+	// alternatesTimeout bounds the time spent on all alternates together.
+	alternatesTimeout = 20 * time.Second
+)
+
+var ogLocaleRegex = regexp.MustCompile(`^[a-z]{2,3}_[A-Z]{2}$`)
+
+// localeLanguage returns the primary language of an og:locale or BCP 47 tag.
+func (s *OGPService) localeLanguage(locale string) string {
+	subtags := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' })
+	if len(subtags) == 0 {
+		return ""
+	}
+	return strings.ToLower(subtags[0])
+}
+
+// hreflangForLocale returns the hreflang link for an og:locale value,
+// preferring an exact language-territory match over a language-only one.
+func (s *OGPService) hreflangForLocale(locale string, hreflangs []models.HreflangLink) (models.HreflangLink, bool) {
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	language := s.localeLanguage(locale)
+
+	var fallback *models.HreflangLink
+	for i, link := range hreflangs {
+		lang := strings.ToLower(link.Lang)
+		if lang == tag {
+			return link, true
+		}
+		if lang == language && fallback == nil {
+			fallback = &hreflangs[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return models.HreflangLink{}, false
+}
+
+func (s *OGPService) localeRules() []Rule {
+	return []Rule{
+		NewRule("OG_LOCALE_INVALID", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			if locale := input.OGPData.Locale; locale != "" && !ogLocaleRegex.MatchString(locale) {
+				issues = append(issues, s.newIssue("OG_LOCALE_INVALID", "og:locale", locale, locale))
+			}
+			for _, alternate := range input.OGPData.LocaleAlternates {
+				if !ogLocaleRegex.MatchString(alternate) {
+					issues = append(issues, s.newIssue("OG_LOCALE_INVALID", "og:locale:alternate", alternate, alternate))
+				}
+			}
+			return issues
+		}),
+		NewRule("OG_LOCALE_MISSING", func(input RuleInput) []models.ValidationIssue {
+			htmlLang := input.seo.htmlLang
+			if input.OGPData.Locale != "" || htmlLang == "" || s.localeLanguage(htmlLang) == "en" {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("OG_LOCALE_MISSING", "og:locale", "", htmlLang)}
+		}),
+		NewRule("OG_LOCALE_HTML_LANG_MISMATCH", func(input RuleInput) []models.ValidationIssue {
+			locale, htmlLang := input.OGPData.Locale, input.seo.htmlLang
+			if locale == "" || htmlLang == "" || s.localeLanguage(locale) == s.localeLanguage(htmlLang) {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("OG_LOCALE_HTML_LANG_MISMATCH", "og:locale", locale, locale, htmlLang)}
+		}),
+		NewRule("OG_LOCALE_ALTERNATE_SELF", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			for _, alternate := range input.OGPData.LocaleAlternates {
+				if input.OGPData.Locale != "" && alternate == input.OGPData.Locale {
+					issues = append(issues, s.newIssue("OG_LOCALE_ALTERNATE_SELF", "og:locale:alternate", alternate, alternate))
+				}
+			}
+			return issues
+		}),
+		NewRule("OG_LOCALE_NO_HREFLANG", func(input RuleInput) []models.ValidationIssue {
+			issues := []models.ValidationIssue{}
+			for _, alternate := range input.OGPData.LocaleAlternates {
+				if _, ok := s.hreflangForLocale(alternate, input.seo.hreflangs); !ok && ogLocaleRegex.MatchString(alternate) {
+					issues = append(issues, s.newIssue("OG_LOCALE_NO_HREFLANG", "og:locale:alternate", alternate, alternate))
+				}
+			}
+			return issues
+		}),
+	}
+}
+
+// checkAlternateLocales fetches the page linked by hreflang for each
+// og:locale:alternate, in parallel and within alternatesTimeout, and verifies
+// its cards with the same options. Alternates are not passed to onVerified;
+// they are part of the page's own verification.
+func (s *OGPService) checkAlternateLocales(ctx context.Context, pageURL *url.URL, ogpData models.OGPData, hreflangs []models.HreflangLink, opts models.VerifyOptions) []models.AlternateLocale {
+	alternates := []models.AlternateLocale{}
+	opts.CheckAlternates = false
+
+	for _, locale := range ogpData.LocaleAlternates {
+		if len(alternates) == MaxAlternateFetches {
+			break
+		}
+		alternate := models.AlternateLocale{Locale: locale}
+
+		link, ok := s.hreflangForLocale(locale, hreflangs)
+		if !ok {
+			alternate.Error = "no hreflang link for this locale"
+		} else if resolved, err := s.resolveURL(pageURL, link.URL); err != nil {
+			alternate.Error = fmt.Sprintf("invalid hreflang URL: %v", err)
+		} else {
+			alternate.URL = resolved.String()
+		}
+		alternates = append(alternates, alternate)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, alternatesTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range alternates {
+		if alternates[i].URL == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(alternate *models.AlternateLocale) {
+			defer wg.Done()
+			s.verifyAlternate(ctx, alternate, opts)
+		}(&alternates[i])
+	}
+	wg.Wait()
+
+	return alternates
+}
+
+// verifyAlternate fetches and verifies an alternate's URL and fills in the
+// result.
+func (s *OGPService) verifyAlternate(ctx context.Context, alternate *models.AlternateLocale, opts models.VerifyOptions) {
+	page, err := s.fetchPage(ctx, alternate.URL)
+	if err != nil {
+		alternate.Error = err.Error()
+		return
+	}
+	response := s.verifyDocument(ctx, alternate.URL, page.url, page.body, page.robotsHeader, true, opts, nil)
+
+	alternate.Fetched = true
+	alternate.OGPLocale = response.OGPData.Locale
+	alternate.LocaleMatches = response.OGPData.Locale == alternate.Locale
+	alternate.Title = response.OGPData.Title
+	alternate.IsValid = response.Validation.IsValid
+	alternate.Score = response.Score.Score
+	alternate.Issues = response.Validation.Issues
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_parseLocaleTags(t *testing.T) {
+	service := NewOGPService()
+	data := service.parseOGPTags(`<html><head>
+		<meta property="og:locale" content="ja_JP" />
+		<meta property="og:locale:alternate" content="en_US" />
+		<meta property="og:locale:alternate" content="fr_FR" />
+	</head></html>`)
+
+	if data.Locale != "ja_JP" {
+		t.Errorf("Expected locale ja_JP, got %q", data.Locale)
+	}
+	if !reflect.DeepEqual(data.LocaleAlternates, []string{"en_US", "fr_FR"}) {
+		t.Errorf("Unexpected alternates: %v", data.LocaleAlternates)
+	}
+}
+
+func TestOGPService_localeRules(t *testing.T) {
+	service := NewOGPService()
+	hreflangs := []models.HreflangLink{
+		{Lang: "ja", URL: "https://example.com/ja/"},
+		{Lang: "en-US", URL: "https://example.com/en/"},
+	}
+
+	tests := []struct {
+		name     string
+		data     models.OGPData
+		htmlLang string
+		expected []string
+	}{
+		{
+			name:     "consistent",
+			data:     models.OGPData{Locale: "ja_JP", LocaleAlternates: []string{"en_US"}},
+			htmlLang: "ja",
+			expected: []string{},
+		},
+		{
+			name:     "invalid format",
+			data:     models.OGPData{Locale: "ja-JP", LocaleAlternates: []string{"english"}},
+			expected: []string{"OG_LOCALE_INVALID", "OG_LOCALE_INVALID"},
+		},
+		{
+			name:     "missing with japanese page",
+			data:     models.OGPData{},
+			htmlLang: "ja",
+			expected: []string{"OG_LOCALE_MISSING"},
+		},
+		{
+			name:     "html lang mismatch",
+			data:     models.OGPData{Locale: "en_US"},
+			htmlLang: "ja-JP",
+			expected: []string{"OG_LOCALE_HTML_LANG_MISMATCH"},
+		},
+		{
+			name:     "alternate repeats locale and lacks hreflang",
+			data:     models.OGPData{Locale: "ja_JP", LocaleAlternates: []string{"ja_JP", "fr_FR"}},
+			expected: []string{"OG_LOCALE_ALTERNATE_SELF", "OG_LOCALE_NO_HREFLANG"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := RuleInput{OGPData: tt.data, seo: seoTags{htmlLang: tt.htmlLang, hreflangs: hreflangs}}
+			codes := []string{}
+			for _, issue := range service.runRules(input, models.RuleConfig{}) {
+				if strings.HasPrefix(issue.Code, "OG_LOCALE_") {
+					codes = append(codes, issue.Code)
+				}
+			}
+			sort.Strings(codes)
+			if !reflect.DeepEqual(codes, tt.expected) {
+				t.Errorf("codes = %v, want %v", codes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOGPService_checkAlternateLocales(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ja/":
+			w.Write([]byte(`<html lang="ja"><head>
+				<meta property="og:title" content="日本語" />
+				<meta property="og:locale" content="ja_JP" />
+				<meta property="og:locale:alternate" content="en_US" />
+				<link rel="alternate" hreflang="ja" href="/ja/" />
+				<link rel="alternate" hreflang="en" href="/en/" />
+			</head></html>`))
+		case "/en/":
+			w.Write([]byte(`<html lang="en"><head>
+				<meta property="og:title" content="English" />
+				<meta property="og:locale" content="en_GB" />
+			</head></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	verified := []string{}
+	service.OnVerified(func(response *models.OGPResponse) {
+		verified = append(verified, response.URL)
+	})
+
+	resp, err := service.FetchOGPDataWithOptions(context.Background(), "http://example.test/ja/", models.VerifyOptions{CheckAlternates: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(verified, []string{"http://example.test/ja/"}) {
+		t.Errorf("Expected only the requested page to reach OnVerified, got %v", verified)
+	}
+	if len(resp.Alternates) != 1 {
+		t.Fatalf("Expected 1 alternate, got %+v", resp.Alternates)
+	}
+	alternate := resp.Alternates[0]
+	if alternate.URL != "http://example.test/en/" || !alternate.Fetched || alternate.Title != "English" {
+		t.Errorf("Unexpected alternate: %+v", alternate)
+	}
+	if alternate.LocaleMatches || alternate.OGPLocale != "en_GB" {
+		t.Errorf("Expected en_GB page not to match en_US, got %+v", alternate)
+	}
+}
+
+func TestOGPService_checkAlternateLocalesParallel(t *testing.T) {
+	// Each alternate page waits for the other to be requested, so the
+	// check only succeeds when they are fetched at the same time.
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ja/":
+			w.Write([]byte(`<html lang="ja"><head>
+				<meta property="og:locale" content="ja_JP" />
+				<meta property="og:locale:alternate" content="en_US" />
+				<meta property="og:locale:alternate" content="fr_FR" />
+				<link rel="alternate" hreflang="en" href="/en/" />
+				<link rel="alternate" hreflang="fr" href="/fr/" />
+			</head></html>`))
+		case "/en/", "/fr/":
+			arrived.Done()
+			done := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				http.Error(w, "alternates were fetched one at a time", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`<html><head><meta property="og:title" content="Alternate" /></head></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	resp, err := service.FetchOGPDataWithOptions(context.Background(), "http://example.test/ja/", models.VerifyOptions{CheckAlternates: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.Alternates) != 2 {
+		t.Fatalf("Expected 2 alternates, got %+v", resp.Alternates)
+	}
+	for i, locale := range []string{"en_US", "fr_FR"} {
+		alternate := resp.Alternates[i]
+		if alternate.Locale != locale || !alternate.Fetched {
+			t.Errorf("Expected %s to be fetched, got %+v", locale, alternate)
+		}
+	}
+}
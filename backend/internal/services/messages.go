@@ -0,0 +1,162 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	LangEnglish  = "en"
+	LangJapanese = "ja"
+)
+
+// DefaultLang is used when neither the request nor Accept-Language selects a
+// supported language.
+const DefaultLang = LangEnglish
+
+// issueTranslations holds issue messages by language and code. English is
+// the catalog message itself, so only other languages are listed here.
+var issueTranslations = map[string]map[string]string{
+	LangJapanese: {
+		"OG_TITLE_MISSING":             "og:title タグがありません",
+		"OG_DESCRIPTION_MISSING":       "og:description タグがありません",
+		"OG_IMAGE_MISSING":             "og:image タグがありません",
+		"OG_IMAGE_INVALID":             "画像URLが不正です",
+		"OG_DUPLICATE_PROPERTY":        "%s が%s回指定されています。各プラットフォームは最初の値 %q を使用します",
+		"OG_TAG_IN_BODY":               "%s が <body> 内にあります。<head> のみを読むクローラーには認識されません",
+		"OG_NAME_ATTRIBUTE":            "%s が property= ではなく name= で指定されています。Facebookでは無視されます",
+		"OG_LOCALE_INVALID":            "%q は language_TERRITORY 形式のロケールではありません",
+		"OG_LOCALE_MISSING":            "og:locale がありません。プラットフォームは en_US とみなしますが <html lang> は %q です",
+		"OG_LOCALE_HTML_LANG_MISMATCH": "og:locale (%s) が <html lang> (%s) と一致しません",
+		"OG_LOCALE_ALTERNATE_SELF":     "og:locale:alternate %q が og:locale と同じです",
+		"OG_LOCALE_NO_HREFLANG":        "og:locale:alternate %q に対応する hreflang リンクがありません",
+		"OG_EMPTY_CONTENT":             "%s の content 属性が空です",
+
+		"FB_APP_ID_MISSING":              "fb:app_id がありません",
+		"FB_APP_ID_INVALID":              "fb:app_id %q は数値のアプリIDではありません",
+		"FB_OG_TYPE_UNKNOWN":             "og:type %q はFacebookが認識するタイプではありません",
+		"FB_INFERRED_PROPERTY":           "%s はFacebookが推測できる場合でも明示的に指定してください",
+		"FB_IMAGE_TOO_SMALL":             "og:image は %s です。Facebookでは 200x200 以上が必要です",
+		"FB_IMAGE_SMALL_PREVIEW":         "og:image は %s です。600x315 未満の画像はFacebookで小さなサムネイルとして表示されます",
+		"FB_IMAGE_ASPECT_RATIO":          "og:image のアスペクト比は %s:1 です。Facebookではリンク画像が 1.91:1 にトリミングされます",
+		"FB_IMAGE_DIMENSIONS_UNDECLARED": "og:image:width と og:image:height がありません。最初のシェア時に画像が表示されない場合があります",
+
+		"A11Y_IMAGE_ALT_MISSING":     "og:image:alt がありません。スクリーンリーダーの利用者に画像の内容が伝わりません",
+		"A11Y_IMAGE_ALT_PLACEHOLDER": "%s %q は画像の内容を説明していません",
+		"A11Y_IMAGE_ALT_TOO_LONG":    "%s は%s文字です。Xでは画像の説明が420文字で切り詰められます",
+		"A11Y_TWITTER_ALT_MISMATCH":  "twitter:image:alt %q が og:image:alt %q と異なります。Xと他のプラットフォームで画像の説明が変わります",
+		"A11Y_IMAGE_TEXT":            "og:image に文字が含まれているようです。その内容を og:image:alt にも記載してください",
+		"A11Y_IMAGE_LOW_CONTRAST":    "og:image 内の文字が読みにくい可能性があります（コントラスト比 %s:1、推奨は 4.5:1 以上）",
+
+		"ICON_MISSING":             "ファビコンが見つかりません。プラットフォームでは汎用アイコンが表示されます",
+		"ICON_FETCH_FAILED":        "アイコン %s を取得できません: %s",
+		"ICON_NOT_SQUARE":          "アイコン %s が正方形ではありません (%s)",
+		"ICON_SIZE_MISMATCH":       "アイコン %s の sizes は %s ですが実際は %s です",
+		"ICON_TOO_SMALL":           "アイコン %s は %s です。%spx 以上を推奨します",
+		"APPLE_TOUCH_ICON_MISSING": "apple-touch-icon がありません。og:image がない場合に iMessage で表示されます",
+		"MANIFEST_INVALID":         "Webアプリマニフェスト %s を読み込めません: %s",
+		"THEME_COLOR_INVALID":      "theme-color %q は16進カラーではないため、Discordの埋め込みの色に使われません",
+
+		"SEO_TITLE_MISSING":         "<title> タグがありません",
+		"SEO_TITLE_MULTIPLE":        "<title> タグが複数あります",
+		"SEO_DESCRIPTION_MISSING":   "meta description がありません",
+		"SEO_NOINDEX":               "noindex が指定されているため検索結果に表示されません",
+		"SEO_CANONICAL_MISSING":     "canonical リンクがありません",
+		"SEO_CANONICAL_MULTIPLE":    "canonical リンクが複数あります",
+		"SEO_CANONICAL_RELATIVE":    "canonical URL は絶対URLで指定してください",
+		"OG_URL_CANONICAL_MISMATCH": "og:url (%s) が canonical URL (%s) と一致しません",
+		"HREFLANG_INVALID":          "hreflang の値 %q が不正です",
+		"HREFLANG_RELATIVE":         "hreflang %q のURLは絶対URLで指定してください",
+		"HREFLANG_CONFLICT":         "hreflang %q が複数のURLを指しています",
+		"HREFLANG_NO_SELF":          "hreflang リンクにこのページ自身が含まれていません",
+
+		"PREVIEW_TITLE_TOO_LONG":       "タイトルが %s の上限（%s文字）を超えています",
+		"PREVIEW_DESCRIPTION_TOO_LONG": "説明文が %s の上限（%s文字）を超えています",
+		"PREVIEW_TITLE_CUT_OFF":        "タイトルは %s で%s文字目以降が切り詰められる見込みです",
+		"PREVIEW_DESCRIPTION_CUT_OFF":  "説明文は %s で%s文字目以降が切り詰められる見込みです",
+		"PREVIEW_IMAGE_NOT_FETCHED":    "%s のサイズ確認のための画像を取得できませんでした",
+		"PREVIEW_IMAGE_NOT_PROBED":     "画像の取得が無効なため、%s 向けの画像の確認を行っていません",
+
+		"DISCORD_IMAGE_LARGE":           "Discordでは画像が説明文の下に全幅で表示されます",
+		"DISCORD_IMAGE_THUMBNAIL":       "Discordでは画像がサムネイルで表示されます。大きく表示するには twitter:card を summary_large_image にしてください",
+		"MASTODON_CREATOR_INVALID":      "fediverse:creator %q は @user@domain 形式のハンドルではありません",
+		"MASTODON_ONE_CARD":             "Mastodonでは1投稿につき最初のリンクのみカードが表示され、メディアや投票付きの投稿には表示されません",
+		"BLUESKY_IMAGE_TOO_LARGE":       "画像がBlueskyのサイズ上限（%sバイト）を超えています",
+		"BLUESKY_IMAGE_CROPPED":         "Blueskyでは画像が 1.91:1 にトリミングされます",
+		"TELEGRAM_CHANNEL_INVALID":      "telegram:channel %q はチャンネルのユーザー名として不正です",
+		"TELEGRAM_INSTANT_VIEW_CHANNEL": "Instant View は %s にリンクします",
+		"TELEGRAM_INSTANT_VIEW_ARTICLE": "サイトのテンプレートがあれば、記事ページは Instant View で開けます",
+		"TELEGRAM_IMAGE_TOO_LARGE":      "画像がTelegramの上限（5MB）を超えているため、プレビューは画像なしで表示されます",
+		"TELEGRAM_IMAGE_LARGE":          "Telegramでは画像が大きな写真として表示されます",
+		"TELEGRAM_IMAGE_THUMBNAIL":      "Telegramでは画像が小さなサムネイルで表示されます",
+		"WHATSAPP_IMAGE_TOO_LARGE":      "画像がWhatsAppの上限（300KB）を超えているため、プレビューは画像なしで表示されます",
+		"WHATSAPP_IMAGE_TOO_NARROW":     "画像の幅が300px未満のため、WhatsAppで表示されない場合があります",
+		"IMESSAGE_NO_DESCRIPTION":       "iMessage では og:description は表示されません",
+		"IMESSAGE_APPLE_TOUCH_ICON":     "iMessage では代わりに apple-touch-icon が表示されます",
+		"IMESSAGE_NO_IMAGE":             "使用できる og:image も apple-touch-icon もないため、iMessage ではテキストのリンクとして表示されます",
+		"SEARCH_TITLE_TOO_WIDE":         "タイトルがGoogleの上限（600px）を超えています",
+		"SEARCH_DESCRIPTION_TOO_WIDE":   "説明文がGoogleの上限（920px）を超えています",
+		"SEARCH_NOINDEX":                "noindex が指定されているため検索結果に表示されません",
+	},
+}
+
+func (s *OGPService) isSupportedLang(lang string) bool {
+	return lang == LangEnglish || issueTranslations[lang] != nil
+}
+
+// NegotiateLanguage picks the supported language with the highest quality
+// in an Accept-Language header, falling back to DefaultLang.
+func NegotiateLanguage(acceptLanguage string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+	}
+
+	candidates := []candidate{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		// Match on the primary subtag so ja-JP selects ja
+		lang := strings.SplitN(tag, "-", 2)[0]
+		if quality > 0 && (lang == LangEnglish || issueTranslations[lang] != nil) {
+			candidates = append(candidates, candidate{lang, quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	if len(candidates) == 0 {
+		return DefaultLang
+	}
+	return candidates[0].lang
+}
+
+// localizeIssues rewrites catalog messages in lang. Issues from custom rules
+// keep the message they were created with.
+func (s *OGPService) localizeIssues(issues []models.ValidationIssue, lang string) {
+	translations := issueTranslations[lang]
+	if translations == nil {
+		return
+	}
+	for i := range issues {
+		if format, ok := translations[issues[i].Code]; ok {
+			issues[i].Message = s.formatMessage(format, issues[i].Params)
+		}
+	}
+}
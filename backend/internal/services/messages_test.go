@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestIssueTranslationsCoverCatalog(t *testing.T) {
+	for lang, translations := range issueTranslations {
+		for code, definition := range issueCatalog {
+			translated, ok := translations[code]
+			if !ok {
+				t.Errorf("%s: missing translation for %s", lang, code)
+				continue
+			}
+			if strings.Count(translated, "%") != strings.Count(definition.Message, "%") {
+				t.Errorf("%s: %s translation has different parameters than %q", lang, code, definition.Message)
+			}
+		}
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"ja", "ja"},
+		{"ja-JP,ja;q=0.9,en-US;q=0.8", "ja"},
+		{"en-US,en;q=0.9,ja;q=0.8", "en"},
+		{"fr-FR, ja;q=0.5", "ja"},
+		{"en;q=0.3, ja;q=0.7", "ja"},
+		{"ja;q=0, de", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := NegotiateLanguage(tt.header); got != tt.expected {
+				t.Errorf("NegotiateLanguage(%q) = %q, want %q", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOGPService_localizeIssues(t *testing.T) {
+	service := NewOGPService()
+	issues := []models.ValidationIssue{
+		service.newIssue("OG_TITLE_MISSING", "og:title", ""),
+		service.newIssue("HREFLANG_INVALID", "hreflang", "https://example.com/", "en_US"),
+		{Code: "ACME_SITE_NAME", Severity: models.SeverityWarning, Message: "og:site_name must be 'Acme'"},
+	}
+
+	service.localizeIssues(issues, LangJapanese)
+
+	expected := []string{
+		"og:title タグがありません",
+		`hreflang の値 "en_US" が不正です`,
+		"og:site_name must be 'Acme'",
+	}
+	for i, issue := range issues {
+		if issue.Message != expected[i] {
+			t.Errorf("issue %s message = %q, want %q", issue.Code, issue.Message, expected[i])
+		}
+	}
+}
+
+func TestOGPService_FetchOGPData_Lang(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><meta property="og:description" content="` + strings.Repeat("a", 170) + `" /><meta name="twitter:card" content="summary" /><meta property="og:image" content="https://cdn.example.test/image.png" /></head></html>`))
+	}))
+
+	resp, err := service.FetchOGPDataWithOptions(context.Background(), "http://example.test/", models.VerifyOptions{Lang: LangJapanese})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Lang != LangJapanese {
+		t.Errorf("Expected lang ja, got %q", resp.Lang)
+	}
+	if len(resp.Validation.Warnings) == 0 || resp.Validation.Warnings[0] != "og:title タグがありません" {
+		t.Errorf("Expected Japanese validation warnings, got %v", resp.Validation.Warnings)
+	}
+	if len(resp.SEO.Warnings) == 0 || resp.SEO.Warnings[0] != "<title> タグがありません" {
+		t.Errorf("Expected Japanese SEO warnings, got %v", resp.SEO.Warnings)
+	}
+
+	discord := resp.Previews.Discord
+	if len(discord.Hints) != 1 || discord.Hints[0] != "Discordでは画像がサムネイルで表示されます。大きく表示するには twitter:card を summary_large_image にしてください" {
+		t.Errorf("Expected Japanese Discord hints, got %v", discord.Hints)
+	}
+	whatsApp := resp.Previews.WhatsApp
+	if len(whatsApp.Warnings) == 0 || whatsApp.Warnings[0] != "説明文が WhatsApp の上限（160文字）を超えています" {
+		t.Errorf("Expected Japanese WhatsApp warnings, got %v", whatsApp.Warnings)
+	}
+	if len(whatsApp.Issues) != len(whatsApp.Warnings) || whatsApp.Issues[0].Code != "PREVIEW_DESCRIPTION_TOO_LONG" || whatsApp.Issues[0].Message != whatsApp.Warnings[0] {
+		t.Errorf("Expected the warnings as coded issues, got %+v", whatsApp.Issues)
+	}
+}
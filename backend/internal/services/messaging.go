@@ -0,0 +1,142 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// whatsAppMaxImageBytes is the approximate size above which WhatsApp drops the thumbnail.
+	whatsAppMaxImageBytes = 300 * 1024
+	// whatsAppMinImageWidth is the smallest image WhatsApp renders as a thumbnail.
+	whatsAppMinImageWidth = 300
+	// telegramMaxImageBytes is the largest image Telegram attaches to a link preview.
+	telegramMaxImageBytes = 5 * 1024 * 1024
+	// telegramLargePhotoWidth is the width from which Telegram shows a full-width photo.
+	telegramLargePhotoWidth = 600
+)
+
+var telegramChannelRegex = regexp.MustCompile(`^@?[A-Za-z][A-Za-z0-9_]{4,31}$`)
+
+func (s *OGPService) generateTelegramPreview(ogpData models.OGPData, imageInfo models.ImageMetadata) models.PlatformPreview {
+	preview := s.newPlatformPreview("telegram", ogpData, 256, 500)
+	s.checkPreviewLengths(&preview, "Telegram")
+	preview.Hints = []string{}
+
+	if channel := strings.TrimSpace(ogpData.TelegramChannel); channel != "" {
+		if telegramChannelRegex.MatchString(channel) {
+			preview.Author = "@" + strings.TrimPrefix(channel, "@")
+			s.addPreviewIssue(&preview, "TELEGRAM_INSTANT_VIEW_CHANNEL", preview.Author)
+		} else {
+			s.addPreviewIssue(&preview, "TELEGRAM_CHANNEL_INVALID", channel)
+		}
+	}
+
+	if ogpData.Type == "article" {
+		s.addPreviewIssue(&preview, "TELEGRAM_INSTANT_VIEW_ARTICLE")
+	}
+
+	if ogpData.Image == "" {
+		return preview
+	}
+	preview.ImageSource = "og:image"
+
+	if !imageProbed(imageInfo) {
+		s.addPreviewIssue(&preview, "PREVIEW_IMAGE_NOT_PROBED", "Telegram")
+		return preview
+	}
+	if !imageInfo.Fetched {
+		s.addPreviewIssue(&preview, "PREVIEW_IMAGE_NOT_FETCHED", "Telegram")
+		return preview
+	}
+
+	if imageInfo.Size > telegramMaxImageBytes {
+		preview.Image = ""
+		preview.ImageSource = ""
+		s.addPreviewIssue(&preview, "TELEGRAM_IMAGE_TOO_LARGE")
+		return preview
+	}
+
+	if imageInfo.Width >= telegramLargePhotoWidth && imageInfo.Width > imageInfo.Height {
+		s.addPreviewIssue(&preview, "TELEGRAM_IMAGE_LARGE")
+	} else if imageInfo.Width > 0 {
+		s.addPreviewIssue(&preview, "TELEGRAM_IMAGE_THUMBNAIL")
+	}
+
+	return preview
+}
+
+func (s *OGPService) generateWhatsAppPreview(ogpData models.OGPData, imageInfo models.ImageMetadata) models.PlatformPreview {
+	preview := s.newPlatformPreview("whatsapp", ogpData, 80, 160)
+	s.checkPreviewLengths(&preview, "WhatsApp")
+
+	if ogpData.Image == "" {
+		return preview
+	}
+	preview.ImageSource = "og:image"
+
+	if !imageProbed(imageInfo) {
+		s.addPreviewIssue(&preview, "PREVIEW_IMAGE_NOT_PROBED", "WhatsApp")
+		return preview
+	}
+	if !imageInfo.Fetched {
+		s.addPreviewIssue(&preview, "PREVIEW_IMAGE_NOT_FETCHED", "WhatsApp")
+		return preview
+	}
+
+	if imageInfo.Size > whatsAppMaxImageBytes {
+		preview.Image = ""
+		preview.ImageSource = ""
+		s.addPreviewIssue(&preview, "WHATSAPP_IMAGE_TOO_LARGE")
+		return preview
+	}
+
+	if imageInfo.Width > 0 && imageInfo.Width < whatsAppMinImageWidth {
+		s.addPreviewIssue(&preview, "WHATSAPP_IMAGE_TOO_NARROW")
+	}
+
+	return preview
+}
+
+func (s *OGPService) generateIMessagePreview(pageURL *url.URL, ogpData models.OGPData, imageInfo models.ImageMetadata) models.PlatformPreview {
+	preview := s.newPlatformPreview("imessage", ogpData, 100, 300)
+	if preview.TitleLength > preview.MaxTitleLen {
+		s.addPreviewIssue(&preview, "PREVIEW_TITLE_TOO_LONG", "iMessage", strconv.Itoa(preview.MaxTitleLen))
+	}
+
+	// Messages shows the title and domain only. With no description to cut
+	// off, MaxDescLen 0 also leaves it out of the text length score.
+	preview.Description = ""
+	preview.DisplayDescription = ""
+	preview.DescLength = 0
+	preview.MaxDescLen = 0
+	preview.DescPixelWidth = 0
+	preview.DescWithinCharLimit = true
+	preview.DescFitsWidth = true
+	preview.Hints = []string{}
+	s.addPreviewIssue(&preview, "IMESSAGE_NO_DESCRIPTION")
+
+	// An og:image that was not probed is kept; only a failed probe falls
+	// back to the icon
+	if ogpData.Image != "" && (!imageProbed(imageInfo) || (imageInfo.Fetched && imageInfo.Error == "")) {
+		preview.ImageSource = "og:image"
+		return preview
+	}
+
+	preview.Image = ""
+	if ogpData.AppleTouchIcon != "" {
+		if icon, err := s.resolveURL(pageURL, ogpData.AppleTouchIcon); err == nil {
+			preview.Image = icon.String()
+			preview.ImageSource = "apple-touch-icon"
+			s.addPreviewIssue(&preview, "IMESSAGE_APPLE_TOUCH_ICON")
+			return preview
+		}
+	}
+
+	s.addPreviewIssue(&preview, "IMESSAGE_NO_IMAGE")
+	return preview
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_parseMessagingTags(t *testing.T) {
+	service := NewOGPService()
+
+	html := `<html><head>
+		<meta property="telegram:channel" content="@ogpnews" />
+		<link rel="icon" href="/favicon.ico" />
+		<link rel="apple-touch-icon" href="/apple-touch-icon.png" />
+	</head></html>`
+
+	result := service.parseOGPTags(html)
+	if result.TelegramChannel != "@ogpnews" {
+		t.Errorf("Expected telegram channel, got %q", result.TelegramChannel)
+	}
+	if result.AppleTouchIcon != "/apple-touch-icon.png" {
+		t.Errorf("Expected apple-touch-icon, got %q", result.AppleTouchIcon)
+	}
+}
+
+func TestOGPService_generateTelegramPreview(t *testing.T) {
+	service := NewOGPService()
+
+	data := models.OGPData{
+		Title:           "Title",
+		Type:            "article",
+		Image:           "https://example.com/image.jpg",
+		TelegramChannel: "ogpnews",
+	}
+	result := service.generateTelegramPreview(data, models.ImageMetadata{Fetched: true, Size: 100000, Width: 1200, Height: 630})
+
+	if result.Author != "@ogpnews" {
+		t.Errorf("Expected author @ogpnews, got %q", result.Author)
+	}
+	if len(result.Hints) != 3 {
+		t.Errorf("Expected channel, instant view and photo hints, got %v", result.Hints)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", result.Warnings)
+	}
+
+	// Probing turned off is a hint, not a warning
+	result = service.generateTelegramPreview(models.OGPData{Title: "Title", Image: "https://example.com/image.jpg"}, models.ImageMetadata{})
+	if len(result.Warnings) != 0 || len(result.Hints) != 1 || result.Issues[0].Code != "PREVIEW_IMAGE_NOT_PROBED" {
+		t.Errorf("Expected only the not probed hint, got %v and %v", result.Warnings, result.Hints)
+	}
+}
+
+func TestOGPService_generateWhatsAppPreview(t *testing.T) {
+	service := NewOGPService()
+	data := models.OGPData{Title: "Title", Image: "https://example.com/image.jpg"}
+
+	tests := []struct {
+		name        string
+		image       models.ImageMetadata
+		expectImage bool
+		warnings    int
+	}{
+		{"small image", models.ImageMetadata{Fetched: true, Size: 100 * 1024, Width: 1200, Height: 630}, true, 0},
+		{"heavy image", models.ImageMetadata{Fetched: true, Size: 500 * 1024, Width: 1200, Height: 630}, false, 1},
+		{"narrow image", models.ImageMetadata{Fetched: true, Size: 10 * 1024, Width: 200, Height: 200}, true, 1},
+		{"image not fetched", models.ImageMetadata{Error: "HTTP error: 404"}, true, 1},
+		{"image not probed", models.ImageMetadata{}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.generateWhatsAppPreview(data, tt.image)
+
+			if (result.Image != "") != tt.expectImage {
+				t.Errorf("Expected image %v, got %q", tt.expectImage, result.Image)
+			}
+			if len(result.Warnings) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %v", tt.warnings, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestOGPService_generateIMessagePreview(t *testing.T) {
+	service := NewOGPService()
+	pageURL, _ := url.Parse("https://example.com/page")
+
+	tests := []struct {
+		name           string
+		data           models.OGPData
+		image          models.ImageMetadata
+		expectedImage  string
+		expectedSource string
+	}{
+		{
+			name:           "og:image",
+			data:           models.OGPData{Image: "https://example.com/image.jpg", AppleTouchIcon: "/icon.png"},
+			image:          models.ImageMetadata{Fetched: true, Width: 1200, Height: 630},
+			expectedImage:  "https://example.com/image.jpg",
+			expectedSource: "og:image",
+		},
+		{
+			name:           "og:image not probed",
+			data:           models.OGPData{Image: "https://example.com/image.jpg", AppleTouchIcon: "/icon.png"},
+			expectedImage:  "https://example.com/image.jpg",
+			expectedSource: "og:image",
+		},
+		{
+			name:           "broken og:image falls back to icon",
+			data:           models.OGPData{Image: "https://example.com/missing.jpg", AppleTouchIcon: "/icon.png"},
+			image:          models.ImageMetadata{Error: "HTTP error: 404"},
+			expectedImage:  "https://example.com/icon.png",
+			expectedSource: "apple-touch-icon",
+		},
+		{
+			name: "no image at all",
+			data: models.OGPData{Description: "Hidden"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.generateIMessagePreview(pageURL, tt.data, tt.image)
+
+			if result.Image != tt.expectedImage || result.ImageSource != tt.expectedSource {
+				t.Errorf("Expected image %q from %q, got %q from %q", tt.expectedImage, tt.expectedSource, result.Image, result.ImageSource)
+			}
+			if result.Description != "" {
+				t.Errorf("Expected description to be hidden, got %q", result.Description)
+			}
+		})
+	}
+}
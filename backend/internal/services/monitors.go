@@ -0,0 +1,523 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// DefaultMonitorInterval is how often a monitor checks its page, in
+	// minutes, when no interval is given.
+	DefaultMonitorInterval = 60
+	minMonitorInterval     = 5
+	maxMonitorInterval     = 24 * 60
+	// MaxMonitors bounds the monitors that can be registered at once.
+	MaxMonitors = 100
+	// maxChecksPerMonitor bounds each monitor's check history.
+	maxChecksPerMonitor = 100
+	// maxConcurrentChecks bounds the checks running at once across monitors.
+	maxConcurrentChecks = 4
+)
+
+var (
+	ErrMonitorNotFound = errors.New("monitor not found")
+	ErrTooManyMonitors = errors.New("too many monitors")
+	ErrMonitorsClosed  = errors.New("monitor manager is shutting down")
+)
+
+// MonitorStore keeps registered monitors and their checks.
+// storage.SQLiteMonitorStore keeps them across restarts; MemoryMonitorStore
+// is enough for tests and development.
+type MonitorStore interface {
+	// Save stores a newly registered monitor.
+	Save(monitor models.Monitor) error
+	// Delete removes a monitor and its checks.
+	Delete(id string) error
+	// AddCheck records a check of a monitor and keeps only its latest keep
+	// checks.
+	AddCheck(id string, check models.MonitorCheck, keep int) error
+	// List returns the monitors in registration order.
+	List() ([]models.Monitor, error)
+	// Checks returns a monitor's checks, oldest first.
+	Checks(id string) ([]models.MonitorCheck, error)
+}
+
+// MemoryMonitorStore keeps monitors in memory.
+type MemoryMonitorStore struct {
+	mu       sync.Mutex
+	monitors map[string]models.Monitor
+	order    []string
+	checks   map[string][]models.MonitorCheck
+}
+
+func NewMemoryMonitorStore() *MemoryMonitorStore {
+	return &MemoryMonitorStore{
+		monitors: map[string]models.Monitor{},
+		checks:   map[string][]models.MonitorCheck{},
+	}
+}
+
+func (s *MemoryMonitorStore) Save(monitor models.Monitor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.monitors[monitor.ID] = monitor
+	s.order = append(s.order, monitor.ID)
+	return nil
+}
+
+func (s *MemoryMonitorStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.monitors, id)
+	delete(s.checks, id)
+	s.order = slices.DeleteFunc(s.order, func(registered string) bool { return registered == id })
+	return nil
+}
+
+func (s *MemoryMonitorStore) AddCheck(id string, check models.MonitorCheck, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checks := append(s.checks[id], check)
+	if len(checks) > keep {
+		checks = checks[len(checks)-keep:]
+	}
+	s.checks[id] = checks
+	return nil
+}
+
+func (s *MemoryMonitorStore) List() ([]models.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	monitors := make([]models.Monitor, 0, len(s.order))
+	for _, id := range s.order {
+		monitors = append(monitors, s.monitors[id])
+	}
+	return monitors, nil
+}
+
+func (s *MemoryMonitorStore) Checks(id string) ([]models.MonitorCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.checks[id]), nil
+}
+
+// MonitorManager re-verifies registered URLs on a schedule and raises an
+// alert when a check is worse than the one before it: the page can no
+// longer be fetched, its score dropped, new errors appeared, or its
+// og:image changed or stopped loading. Monitors and their checks are kept
+// in a MonitorStore so that Restore can resume them after a restart.
+type MonitorManager struct {
+	service *OGPService
+	store   MonitorStore
+	// minute is the unit of a monitor's interval; tests shorten it.
+	minute time.Duration
+	slots  chan struct{}
+
+	mu           sync.Mutex
+	monitors     map[string]*monitorRun
+	order        []string
+	closed       bool
+	onRegression func(alert models.MonitorAlert)
+
+	// ctx is cancelled by Shutdown to stop every schedule.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// monitorRun is a registered monitor and its checks, oldest first. Its
+// fields are guarded by the manager's mutex.
+type monitorRun struct {
+	monitor models.Monitor
+	checks  []models.MonitorCheck
+	cancel  context.CancelFunc
+}
+
+func NewMonitorManager(service *OGPService, store MonitorStore) *MonitorManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MonitorManager{
+		service:  service,
+		store:    store,
+		minute:   time.Minute,
+		slots:    make(chan struct{}, maxConcurrentChecks),
+		monitors: map[string]*monitorRun{},
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// OnRegression sets fn to be called with every alert. It is called with the
+// manager locked, so it must not block or call back into the manager. It
+// must be set before monitors are registered.
+func (m *MonitorManager) OnRegression(fn func(alert models.MonitorAlert)) {
+	m.onRegression = fn
+}
+
+// ValidateMonitor checks a monitor's URL, interval and verification options.
+func (s *OGPService) ValidateMonitor(req models.MonitorRequest) error {
+	if req.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(req.URL)
+	if err != nil || !parsed.IsAbs() || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if s.isPrivateIP(parsed.Hostname()) {
+		return fmt.Errorf("private IP addresses are not allowed")
+	}
+	if req.IntervalMinutes != 0 && (req.IntervalMinutes < minMonitorInterval || req.IntervalMinutes > maxMonitorInterval) {
+		return fmt.Errorf("interval_minutes must be between %d and %d", minMonitorInterval, maxMonitorInterval)
+	}
+	return s.ValidateOptions(req.VerifyOptions)
+}
+
+// Register adds a monitor and starts its schedule with a first check, which
+// the later checks are compared against.
+func (m *MonitorManager) Register(req models.MonitorRequest) (models.Monitor, error) {
+	if err := m.service.ValidateMonitor(req); err != nil {
+		return models.Monitor{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	if req.IntervalMinutes == 0 {
+		req.IntervalMinutes = DefaultMonitorInterval
+	}
+	// Alternates would multiply the requests of every check
+	req.CheckAlternates = false
+
+	run := &monitorRun{
+		monitor: models.Monitor{
+			ID:              id,
+			URL:             req.URL,
+			IntervalMinutes: req.IntervalMinutes,
+			Options:         req.VerifyOptions,
+			TokenHash:       tokenHash,
+			CreatedAt:       time.Now(),
+		},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return models.Monitor{}, ErrMonitorsClosed
+	}
+	if len(m.monitors) >= MaxMonitors {
+		return models.Monitor{}, ErrTooManyMonitors
+	}
+	if err := m.store.Save(run.monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	m.start(run, 0)
+
+	created := run.monitor
+	created.Token = token
+	return created, nil
+}
+
+// Restore resumes the monitors kept in the store, each due an interval
+// after its last check. It must be called before monitors are registered.
+func (m *MonitorManager) Restore() error {
+	monitors, err := m.store.List()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, monitor := range monitors {
+		checks, err := m.store.Checks(monitor.ID)
+		if err != nil {
+			return err
+		}
+		run := &monitorRun{monitor: monitor, checks: checks}
+
+		var delay time.Duration
+		if len(checks) > 0 {
+			last := checks[len(checks)-1]
+			run.monitor.LastCheck = &last
+			delay = max(time.Until(last.CheckedAt.Add(time.Duration(monitor.IntervalMinutes)*m.minute)), 0)
+		}
+		next := time.Now().Add(delay)
+		run.monitor.NextCheckAt = &next
+		m.start(run, delay)
+	}
+	return nil
+}
+
+// start adds a monitor and schedules its first check after delay. The
+// caller holds m.mu.
+func (m *MonitorManager) start(run *monitorRun, delay time.Duration) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	run.cancel = cancel
+	m.monitors[run.monitor.ID] = run
+	m.order = append(m.order, run.monitor.ID)
+
+	m.wg.Add(1)
+	go m.watch(ctx, run, delay)
+}
+
+// Authorize checks a monitor's management token.
+func (m *MonitorManager) Authorize(id, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.monitors[id]
+	if !ok {
+		return ErrMonitorNotFound
+	}
+	if !tokenMatches(run.monitor.TokenHash, token) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (m *MonitorManager) Get(id string) (models.Monitor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.monitors[id]
+	if !ok {
+		return models.Monitor{}, ErrMonitorNotFound
+	}
+	return run.monitor, nil
+}
+
+// Checks returns a monitor's recent checks, newest first.
+func (m *MonitorManager) Checks(id string) ([]models.MonitorCheck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.monitors[id]
+	if !ok {
+		return nil, ErrMonitorNotFound
+	}
+	checks := make([]models.MonitorCheck, 0, len(run.checks))
+	for i := len(run.checks) - 1; i >= 0; i-- {
+		checks = append(checks, run.checks[i])
+	}
+	return checks, nil
+}
+
+// Delete stops a monitor and forgets its checks. A check in progress is
+// cancelled.
+func (m *MonitorManager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run, ok := m.monitors[id]
+	if !ok {
+		return ErrMonitorNotFound
+	}
+	if err := m.store.Delete(id); err != nil {
+		return err
+	}
+	run.cancel()
+	delete(m.monitors, id)
+	for i, registered := range m.order {
+		if registered == id {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Shutdown stops every schedule, cancelling the checks in progress, and
+// waits for them to return until ctx ends.
+func (m *MonitorManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watch checks a monitor's page after delay and then every interval until
+// ctx is cancelled.
+func (m *MonitorManager) watch(ctx context.Context, run *monitorRun, delay time.Duration) {
+	defer m.wg.Done()
+
+	interval := time.Duration(run.monitor.IntervalMinutes) * m.minute
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+		m.check(ctx, run)
+
+		m.mu.Lock()
+		next := time.Now().Add(interval)
+		run.monitor.NextCheckAt = &next
+		m.mu.Unlock()
+		timer.Reset(interval)
+	}
+}
+
+// check verifies a monitor's page once and records the result. Checks
+// cancelled by Delete or Shutdown are not recorded.
+func (m *MonitorManager) check(ctx context.Context, run *monitorRun) {
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	response, err := m.service.FetchOGPDataWithOptions(ctx, run.monitor.URL, run.monitor.Options)
+	<-m.slots
+	if ctx.Err() != nil {
+		return
+	}
+
+	check := models.MonitorCheck{CheckedAt: time.Now()}
+	if err != nil {
+		check.ErrorCodes = []string{}
+		check.Error = err.Error()
+	} else {
+		check.ValidationState = m.service.validationState(response)
+		check.Image = response.OGPData.Image
+		// A URL that answers with something other than an image is broken
+		check.ImageOK = response.ImageInfo.Fetched && response.ImageInfo.Error == ""
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous, ok := m.baseline(run, check)
+	if ok {
+		check.Regressions = m.regressions(previous, check)
+	}
+	if m.monitors[run.monitor.ID] != run {
+		return
+	}
+	run.checks = append(run.checks, check)
+	if len(run.checks) > maxChecksPerMonitor {
+		run.checks = run.checks[len(run.checks)-maxChecksPerMonitor:]
+	}
+	last := check
+	run.monitor.LastCheck = &last
+	if err := m.store.AddCheck(run.monitor.ID, check, maxChecksPerMonitor); err != nil {
+		log.Printf("Monitor %s: check not saved: %v", run.monitor.ID, err)
+	}
+
+	if len(check.Regressions) == 0 {
+		return
+	}
+	reasons := make([]string, len(check.Regressions))
+	for i, regression := range check.Regressions {
+		reasons[i] = regression.Message
+	}
+	log.Printf("Monitor %s: %s regressed: %s", run.monitor.ID, run.monitor.URL, strings.Join(reasons, "; "))
+	if m.onRegression != nil {
+		m.onRegression(models.MonitorAlert{
+			MonitorID: run.monitor.ID,
+			URL:       run.monitor.URL,
+			Previous:  previous,
+			Current:   check,
+		})
+	}
+}
+
+// baseline returns the check that check is compared against: the previous
+// check when check failed, so that only the first failure is reported, and
+// otherwise the last check that fetched the page. The caller holds m.mu.
+func (m *MonitorManager) baseline(run *monitorRun, check models.MonitorCheck) (models.MonitorCheck, bool) {
+	if check.Error != "" {
+		if len(run.checks) == 0 {
+			return models.MonitorCheck{}, false
+		}
+		return run.checks[len(run.checks)-1], true
+	}
+	for i := len(run.checks) - 1; i >= 0; i-- {
+		if run.checks[i].Error == "" {
+			return run.checks[i], true
+		}
+	}
+	return models.MonitorCheck{}, false
+}
+
+// regressions lists the ways current is worse than previous.
+func (m *MonitorManager) regressions(previous, current models.MonitorCheck) []models.Regression {
+	if current.Error != "" {
+		if previous.Error != "" {
+			return nil
+		}
+		return []models.Regression{{
+			Type:    models.RegressionFetchFailed,
+			Message: "page could not be fetched: " + current.Error,
+		}}
+	}
+
+	var regressions []models.Regression
+	if current.Score < previous.Score {
+		regressions = append(regressions, models.Regression{
+			Type:    models.RegressionScoreDropped,
+			Message: fmt.Sprintf("score dropped from %d to %d", previous.Score, current.Score),
+		})
+	}
+	var added []string
+	for _, code := range current.ErrorCodes {
+		if !slices.Contains(previous.ErrorCodes, code) {
+			added = append(added, code)
+		}
+	}
+	if len(added) > 0 {
+		regressions = append(regressions, models.Regression{
+			Type:    models.RegressionErrorsAdded,
+			Message: "new errors: " + strings.Join(added, ", "),
+		})
+	}
+	switch {
+	case current.Image == previous.Image:
+	case current.Image == "":
+		regressions = append(regressions, models.Regression{
+			Type:    models.RegressionImageChanged,
+			Message: "og:image was removed",
+		})
+	default:
+		regressions = append(regressions, models.Regression{
+			Type:    models.RegressionImageChanged,
+			Message: fmt.Sprintf("og:image changed from %q to %q", previous.Image, current.Image),
+		})
+	}
+	if current.Image != "" && !current.ImageOK && (previous.ImageOK || previous.Image != current.Image) {
+		regressions = append(regressions, models.Regression{
+			Type:    models.RegressionImageBroken,
+			Message: fmt.Sprintf("og:image %s could not be loaded", current.Image),
+		})
+	}
+	return regressions
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+// waitForChecks waits until the monitor has recorded n checks.
+func waitForChecks(t *testing.T, monitors *MonitorManager, id string, n int) []models.MonitorCheck {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		checks, err := monitors.Checks(id)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(checks) >= n {
+			return checks
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d checks", n)
+	return nil
+}
+
+func regressionTypes(check models.MonitorCheck) map[string]bool {
+	types := map[string]bool{}
+	for _, regression := range check.Regressions {
+		types[regression.Type] = true
+	}
+	return types
+}
+
+func TestMonitorManager_Regressions(t *testing.T) {
+	imageData := testPNG(t, 1200, 630)
+	var mu sync.Mutex
+	page, status := `<html><head><meta property="og:title" content="Monitored" /><meta property="og:description" content="A page that is checked on a schedule" /><meta property="og:image" content="/image.png" /></head></html>`, http.StatusOK
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(imageData)
+			return
+		}
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(page))
+	}))
+
+	monitors := NewMonitorManager(service, NewMemoryMonitorStore())
+	monitors.minute = 2 * time.Millisecond
+	alerts := make(chan models.MonitorAlert, 10)
+	monitors.OnRegression(func(alert models.MonitorAlert) { alerts <- alert })
+	defer monitors.Shutdown(context.Background())
+
+	monitor, err := monitors.Register(models.MonitorRequest{
+		URL:             "http://example.test/",
+		IntervalMinutes: 5,
+		VerifyOptions:   models.VerifyOptions{Rules: models.RuleConfig{Severity: map[string]string{"OG_TITLE_MISSING": models.SeverityError}}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	waitForChecks(t, monitors, monitor.ID, 2)
+	if len(alerts) != 0 {
+		t.Fatalf("Expected no alert while the page is unchanged, got %+v", <-alerts)
+	}
+
+	mu.Lock()
+	page = `<html><head><meta property="og:image" content="/missing.png" /></head></html>`
+	mu.Unlock()
+
+	var alert models.MonitorAlert
+	select {
+	case alert = <-alerts:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an alert")
+	}
+	if alert.MonitorID != monitor.ID || alert.Previous.Image != "/image.png" {
+		t.Errorf("Expected the alert to compare against the previous check, got %+v", alert)
+	}
+	types := regressionTypes(alert.Current)
+	for _, expected := range []string{models.RegressionScoreDropped, models.RegressionErrorsAdded, models.RegressionImageChanged, models.RegressionImageBroken} {
+		if !types[expected] {
+			t.Errorf("Expected a %s regression, got %+v", expected, alert.Current.Regressions)
+		}
+	}
+
+	// The same broken page is not reported again
+	checks, _ := monitors.Checks(monitor.ID)
+	waitForChecks(t, monitors, monitor.ID, len(checks)+2)
+	if len(alerts) != 0 {
+		t.Fatalf("Expected a single alert for one regression, got %+v", <-alerts)
+	}
+
+	mu.Lock()
+	status = http.StatusInternalServerError
+	mu.Unlock()
+
+	select {
+	case alert = <-alerts:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an alert")
+	}
+	if types := regressionTypes(alert.Current); len(types) != 1 || !types[models.RegressionFetchFailed] {
+		t.Errorf("Expected a fetch_failed regression, got %+v", alert.Current.Regressions)
+	}
+
+	got, err := monitors.Get(monitor.ID)
+	if err != nil || got.LastCheck == nil || got.NextCheckAt == nil {
+		t.Errorf("Expected the last check and next check time, got %+v (%v)", got, err)
+	}
+	if err := monitors.Delete(monitor.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := monitors.Checks(monitor.ID); !errors.Is(err, ErrMonitorNotFound) {
+		t.Errorf("Expected ErrMonitorNotFound after Delete, got %v", err)
+	}
+}
+
+func TestMonitorManager_ImageNotAnImage(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image.png" {
+			// A CDN error page served with a 200
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body>Not found</body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><head><meta property="og:title" content="Monitored" /><meta property="og:image" content="/image.png" /></head></html>`))
+	}))
+
+	monitors := NewMonitorManager(service, NewMemoryMonitorStore())
+	defer monitors.Shutdown(context.Background())
+
+	monitor, err := monitors.Register(models.MonitorRequest{URL: "http://example.test/"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	check := waitForChecks(t, monitors, monitor.ID, 1)[0]
+	if check.Error != "" || check.Image != "/image.png" {
+		t.Fatalf("Expected the page to be checked, got %+v", check)
+	}
+	if check.ImageOK {
+		t.Error("Expected an og:image that is not an image to be reported as broken")
+	}
+}
+
+func TestMonitorManager_Restore(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><meta property="og:title" content="Monitored" /></head></html>`))
+	}))
+	store := NewMemoryMonitorStore()
+
+	monitors := NewMonitorManager(service, store)
+	monitors.minute = 2 * time.Millisecond
+	monitor, err := monitors.Register(models.MonitorRequest{URL: "http://example.test/", IntervalMinutes: 5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deleted, err := monitors.Register(models.MonitorRequest{URL: "http://example.test/deleted"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := monitors.Delete(deleted.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	waitForChecks(t, monitors, monitor.ID, 2)
+	if err := monitors.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saved, _ := store.Checks(monitor.ID)
+
+	// A new manager picks up where the first one stopped
+	restored := NewMonitorManager(service, store)
+	restored.minute = 2 * time.Millisecond
+	defer restored.Shutdown(context.Background())
+	if err := restored.Restore(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := restored.Get(monitor.ID)
+	if err != nil || got.URL != monitor.URL || got.IntervalMinutes != 5 {
+		t.Fatalf("Expected the registered monitor to be restored, got %+v (%v)", got, err)
+	}
+	if got.LastCheck == nil || !got.LastCheck.CheckedAt.Equal(saved[len(saved)-1].CheckedAt) {
+		t.Errorf("Expected the last saved check, got %+v", got.LastCheck)
+	}
+	if err := restored.Authorize(monitor.ID, monitor.Token); err != nil {
+		t.Errorf("Expected the token to survive a restart, got %v", err)
+	}
+	waitForChecks(t, restored, monitor.ID, len(saved)+1)
+}
+
+func TestMonitorManager_Register(t *testing.T) {
+	monitors := NewMonitorManager(NewOGPService(), NewMemoryMonitorStore())
+	// Keep the first checks from reaching the network
+	monitors.slots = make(chan struct{})
+
+	tests := []struct {
+		name    string
+		req     models.MonitorRequest
+		wantErr string
+	}{
+		{"valid", models.MonitorRequest{URL: "https://example.com/"}, ""},
+		{"missing url", models.MonitorRequest{}, "url is required"},
+		{"relative url", models.MonitorRequest{URL: "/blog"}, "url must be an absolute http or https URL"},
+		{"private url", models.MonitorRequest{URL: "http://10.0.0.1/"}, "private IP addresses are not allowed"},
+		{"interval too short", models.MonitorRequest{URL: "https://example.com/", IntervalMinutes: 1}, "interval_minutes must be between 5 and 1440"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := monitors.Register(tt.req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if monitor.IntervalMinutes != DefaultMonitorInterval {
+				t.Errorf("Expected the default interval, got %d", monitor.IntervalMinutes)
+			}
+			if err := monitors.Authorize(monitor.ID, monitor.Token); err != nil {
+				t.Errorf("Expected the returned token to be accepted, got %v", err)
+			}
+			if err := monitors.Authorize(monitor.ID, "wrong"); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected ErrInvalidToken for a wrong token, got %v", err)
+			}
+			if got, _ := monitors.Get(monitor.ID); got.Token != "" {
+				t.Errorf("Expected the token only on registration, got %q", got.Token)
+			}
+		})
+	}
+
+	if err := monitors.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := monitors.Register(models.MonitorRequest{URL: "https://example.com/"}); !errors.Is(err, ErrMonitorsClosed) {
+		t.Errorf("Expected ErrMonitorsClosed after Shutdown, got %v", err)
+	}
+}
@@ -1,31 +1,91 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"ogp-verification-service/internal/models"
 	"golang.org/x/net/html"
 )
 
 type OGPService struct {
-	client *http.Client
+	client     *http.Client
+	rules      *RuleRegistry
+	onVerified func(response *models.OGPResponse)
 }
 
 func NewOGPService() *OGPService {
-	return &OGPService{
+	s := &OGPService{
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		rules: NewRuleRegistry(),
 	}
+	for _, rule := range s.builtinRules() {
+		s.rules.Register(rule)
+	}
+	return s
+}
+
+// OnVerified sets fn to be called with every page that is fetched and
+// verified, including the pages of batches and crawls. It must be set before
+// the service is used.
+func (s *OGPService) OnVerified(fn func(response *models.OGPResponse)) {
+	s.onVerified = fn
 }
 
 func (s *OGPService) FetchOGPData(targetURL string) (*models.OGPResponse, error) {
+	return s.FetchOGPDataWithOptions(context.Background(), targetURL, models.VerifyOptions{})
+}
+
+func (s *OGPService) FetchOGPDataWithOptions(ctx context.Context, targetURL string, opts models.VerifyOptions) (*models.OGPResponse, error) {
+	return s.FetchOGPDataWithEvents(ctx, targetURL, opts, nil)
+}
+
+// EventFunc receives the stages of a verification as they complete.
+type EventFunc func(event models.VerifyEvent)
+
+// FetchOGPDataWithEvents is FetchOGPDataWithOptions sending an event to emit
+// as each stage completes, ending with a done event holding the response.
+// Failures are returned, not emitted.
+func (s *OGPService) FetchOGPDataWithEvents(ctx context.Context, targetURL string, opts models.VerifyOptions, emit EventFunc) (*models.OGPResponse, error) {
+	if err := s.ValidateOptions(opts); err != nil {
+		return nil, err
+	}
+	if emit == nil {
+		emit = func(models.VerifyEvent) {}
+	}
+
+	page, err := s.fetchPage(ctx, targetURL)
+	if err != nil {
+		return nil, err
+	}
+	emit(models.VerifyEvent{Stage: models.VerifyStageFetched, URL: page.url.String()})
+
+	response := s.verifyDocument(ctx, targetURL, page.url, page.body, page.robotsHeader, true, opts, emit)
+	if s.onVerified != nil {
+		s.onVerified(response)
+	}
+	emit(models.VerifyEvent{Stage: models.VerifyStageDone, Response: response})
+	return response, nil
+}
+
+// fetchedPage is a downloaded HTML page.
+type fetchedPage struct {
+	url          *url.URL
+	body         string
+	robotsHeader string
+}
+
+func (s *OGPService) fetchPage(ctx context.Context, targetURL string) (*fetchedPage, error) {
 	parsedURL, err := url.Parse(targetURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
@@ -35,7 +95,7 @@ func (s *OGPService) FetchOGPData(targetURL string) (*models.OGPResponse, error)
 		return nil, fmt.Errorf("private IP addresses are not allowed")
 	}
 
-	req, err := http.NewRequest("GET", targetURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -57,17 +117,122 @@ func (s *OGPService) FetchOGPData(targetURL string) (*models.OGPResponse, error)
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	ogpData := s.parseOGPTags(string(body))
-	validation := s.validateOGPData(ogpData)
-	previews := s.generatePlatformPreviews(ogpData)
+	return &fetchedPage{url: parsedURL, body: string(body), robotsHeader: resp.Header.Get("X-Robots-Tag")}, nil
+}
+
+// VerifyHTML runs the same checks as FetchOGPDataWithOptions on an HTML
+// document that has not been published. Relative URLs are resolved against
+// baseURL when it is given; without it the checks that need the page's own
+// URL are skipped. The og:image, icons and manifest are only downloaded when
+// probeImages is set.
+func (s *OGPService) VerifyHTML(ctx context.Context, htmlContent, baseURL string, probeImages bool, opts models.VerifyOptions) (*models.OGPResponse, error) {
+	if err := s.ValidateOptions(opts); err != nil {
+		return nil, err
+	}
+
+	var pageURL *url.URL
+	if baseURL != "" {
+		parsed, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base URL: %w", err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return nil, fmt.Errorf("base URL must use http or https")
+		}
+		pageURL = parsed
+	}
+
+	return s.verifyDocument(ctx, baseURL, pageURL, htmlContent, "", probeImages, opts, nil), nil
+}
+
+// verifyDocument parses, validates and previews a page. parsedURL resolves
+// relative URLs and is nil when the page's URL is unknown; probe downloads
+// the resources the page refers to.
+func (s *OGPService) verifyDocument(ctx context.Context, responseURL string, parsedURL *url.URL, htmlContent, robotsHeader string, probe bool, opts models.VerifyOptions, emit EventFunc) *models.OGPResponse {
+	if emit == nil {
+		emit = func(models.VerifyEvent) {}
+	}
+
+	ogpData := s.parseOGPTags(htmlContent)
+	sourceTags := s.scanSourceTags(htmlContent)
+	seoTags := s.parseSEOTags(htmlContent)
+	emit(models.VerifyEvent{Stage: models.VerifyStageParsed, OGPData: &ogpData})
+
+	var imageInfo models.ImageMetadata
+	if ogpData.Image != "" && probe {
+		imageInfo = s.probeImage(ctx, parsedURL, ogpData.Image)
+		emit(models.VerifyEvent{Stage: models.VerifyStageImageProbed, ImageInfo: &imageInfo})
+	}
+
+	validation := s.validateOGPData(RuleInput{OGPData: ogpData, ImageInfo: imageInfo, tags: sourceTags, seo: seoTags}, opts.Rules)
+	seo := s.validateSEO(parsedURL, ogpData, seoTags, robotsHeader)
+
+	s.locateIssues(validation.Issues, sourceTags)
+	s.locateIssues(validation.Accessibility.Issues, sourceTags)
+	s.locateIssues(seo.Issues, sourceTags)
 
-	return &models.OGPResponse{
-		URL:        targetURL,
+	lang := opts.Lang
+	if lang == "" {
+		lang = DefaultLang
+	}
+	s.localizeIssues(validation.Issues, lang)
+	s.localizeIssues(validation.Accessibility.Issues, lang)
+	validation.Warnings, validation.Errors = s.issueMessages(validation.Issues)
+	s.localizeIssues(seo.Issues, lang)
+	seo.Warnings, seo.Errors = s.issueMessages(seo.Issues)
+	emit(models.VerifyEvent{Stage: models.VerifyStageValidated, Validation: &validation, SEO: &seo})
+
+	previews := s.generatePlatformPreviews(parsedURL, ogpData, imageInfo, seo, opts)
+	s.applyFacebookDebugger(&previews.Facebook, validation.Issues)
+
+	icons := models.SiteIcons{Icons: []models.IconMetadata{}, Issues: []models.ValidationIssue{}}
+	if probe {
+		icons = s.inspectIcons(ctx, parsedURL, htmlContent)
+		s.locateIssues(icons.Issues, sourceTags)
+		s.localizeIssues(icons.Issues, lang)
+		s.applySiteIcons(&previews, icons)
+		emit(models.VerifyEvent{Stage: models.VerifyStageIcons, Icons: &icons})
+	}
+	s.localizePreviews(&previews, lang)
+	// Previews are final only once the icons are applied
+	for _, platform := range PlatformNames {
+		preview, _ := s.PlatformPreview(previews, platform)
+		emit(models.VerifyEvent{Stage: models.VerifyStagePreview, Preview: &preview})
+	}
+
+	response := &models.OGPResponse{
+		URL:        responseURL,
 		OGPData:    ogpData,
 		Validation: validation,
 		Previews:   previews,
+		ImageInfo:  imageInfo,
+		SEO:        seo,
+		Lang:       lang,
+		Icons:      icons,
 		Timestamp:  time.Now(),
-	}, nil
+	}
+	response.Score = s.scoreResponse(response, opts)
+	if opts.CheckAlternates {
+		response.Alternates = s.checkAlternateLocales(ctx, parsedURL, ogpData, seoTags.hreflangs, opts)
+	}
+
+	return response
+}
+
+// ValidateOptions reports request options the service does not understand.
+func (s *OGPService) ValidateOptions(opts models.VerifyOptions) error {
+	switch opts.TruncationMode {
+	case "", models.TruncationModeChars, models.TruncationModeWidth:
+	default:
+		return fmt.Errorf("invalid truncation_mode %q", opts.TruncationMode)
+	}
+	if opts.Lang != "" && !s.isSupportedLang(opts.Lang) {
+		return fmt.Errorf("unsupported lang %q", opts.Lang)
+	}
+	if opts.MinScore < 0 || opts.MinScore > 100 {
+		return fmt.Errorf("min_score must be between 0 and 100")
+	}
+	return s.validateRuleConfig(opts.Rules)
 }
 
 func (s *OGPService) parseOGPTags(htmlContent string) models.OGPData {
@@ -84,15 +249,34 @@ func (s *OGPService) parseOGPTags(htmlContent string) models.OGPData {
 
 func (s *OGPService) extractOGPTags(n *html.Node, ogpData *models.OGPData) {
 	if n.Type == html.ElementNode && n.Data == "meta" {
-		var property, content string
+		var property, name, content string
 		for _, attr := range n.Attr {
 			if attr.Key == "property" {
 				property = attr.Val
+			} else if attr.Key == "name" {
+				name = attr.Val
 			} else if attr.Key == "content" {
 				content = attr.Val
 			}
 		}
 
+		// Mastodon only reads fediverse:creator from the name attribute
+		if name == "fediverse:creator" {
+			ogpData.FediverseCreator = content
+		}
+		if property == "telegram:channel" || name == "telegram:channel" {
+			ogpData.TelegramChannel = content
+		}
+		if property == "twitter:card" || name == "twitter:card" {
+			ogpData.TwitterCard = content
+		}
+		if property == "twitter:image:alt" || name == "twitter:image:alt" {
+			ogpData.TwitterImageAlt = content
+		}
+		if name == "author" {
+			ogpData.Author = content
+		}
+
 		switch property {
 		case "og:title":
 			ogpData.Title = content
@@ -112,6 +296,29 @@ func (s *OGPService) extractOGPTags(n *html.Node, ogpData *models.OGPData) {
 			ogpData.ImageHeight = content
 		case "og:image:alt":
 			ogpData.ImageAlt = content
+		case "fb:app_id":
+			ogpData.FBAppID = content
+		case "og:locale":
+			ogpData.Locale = content
+		case "og:locale:alternate":
+			ogpData.LocaleAlternates = append(ogpData.LocaleAlternates, content)
+		}
+	}
+
+	if n.Type == html.ElementNode && n.Data == "link" {
+		var rel, href string
+		for _, attr := range n.Attr {
+			if attr.Key == "rel" {
+				rel = strings.ToLower(attr.Val)
+			} else if attr.Key == "href" {
+				href = attr.Val
+			}
+		}
+
+		for _, r := range strings.Fields(rel) {
+			if (r == "apple-touch-icon" || r == "apple-touch-icon-precomposed") && ogpData.AppleTouchIcon == "" {
+				ogpData.AppleTouchIcon = href
+			}
 		}
 	}
 
@@ -120,39 +327,36 @@ func (s *OGPService) extractOGPTags(n *html.Node, ogpData *models.OGPData) {
 	}
 }
 
-func (s *OGPService) validateOGPData(ogpData models.OGPData) models.ValidationResult {
+func (s *OGPService) validateOGPData(input RuleInput, config models.RuleConfig) models.ValidationResult {
+	ogpData := input.OGPData
 	result := models.ValidationResult{
-		IsValid:  true,
-		Warnings: []string{},
-		Errors:   []string{},
+		Issues:    []models.ValidationIssue{},
+		Conflicts: s.findTagConflicts(input.tags),
 		Checks: models.ValidationChecks{
 			HasTitle:       ogpData.Title != "",
 			HasDescription: ogpData.Description != "",
 			HasImage:       ogpData.Image != "",
 			URLValid:       ogpData.URL != "",
 		},
+		Accessibility: models.AccessibilityResult{
+			Issues:         []models.ValidationIssue{},
+			HasImageAlt:    strings.TrimSpace(ogpData.ImageAlt) != "",
+			ImageAltLength: utf8.RuneCountInString(ogpData.ImageAlt),
+		},
 	}
-
-	if !result.Checks.HasTitle {
-		result.Warnings = append(result.Warnings, "Missing og:title tag")
-	}
-	if !result.Checks.HasDescription {
-		result.Warnings = append(result.Warnings, "Missing og:description tag")
-	}
-	if !result.Checks.HasImage {
-		result.Warnings = append(result.Warnings, "Missing og:image tag")
+	for _, issue := range s.runRules(input, config) {
+		if s.isAccessibilityIssue(issue.Code) {
+			result.Accessibility.Issues = append(result.Accessibility.Issues, issue)
+		} else {
+			result.Issues = append(result.Issues, issue)
+		}
 	}
-
 	if ogpData.Image != "" {
 		result.Checks.ImageValid = s.validateImageURL(ogpData.Image)
-		if !result.Checks.ImageValid {
-			result.Errors = append(result.Errors, "Invalid image URL")
-		}
 	}
 
-	if len(result.Errors) > 0 {
-		result.IsValid = false
-	}
+	result.Warnings, result.Errors = s.issueMessages(result.Issues)
+	result.IsValid = !s.hasErrors(result.Issues) && !s.hasErrors(result.Accessibility.Issues)
 
 	return result
 }
@@ -162,100 +366,139 @@ func (s *OGPService) validateImageURL(imageURL string) bool {
 	return err == nil
 }
 
-func (s *OGPService) generatePlatformPreviews(ogpData models.OGPData) models.PlatformPreviews {
-	return models.PlatformPreviews{
+func (s *OGPService) generatePlatformPreviews(pageURL *url.URL, ogpData models.OGPData, imageInfo models.ImageMetadata, seo models.SEOResult, opts models.VerifyOptions) models.PlatformPreviews {
+	previews := models.PlatformPreviews{
 		Twitter:  s.generateTwitterPreview(ogpData),
 		Facebook: s.generateFacebookPreview(ogpData),
 		Discord:  s.generateDiscordPreview(ogpData),
+		Mastodon: s.generateMastodonPreview(ogpData),
+		Misskey:  s.generateMisskeyPreview(ogpData),
+		Bluesky:  s.generateBlueskyPreview(ogpData, imageInfo),
+		Telegram: s.generateTelegramPreview(ogpData, imageInfo),
+		WhatsApp: s.generateWhatsAppPreview(ogpData, imageInfo),
+		IMessage: s.generateIMessagePreview(pageURL, ogpData, imageInfo),
+		Search:   s.generateSearchPreview(pageURL, ogpData, seo),
 	}
-}
 
-func (s *OGPService) generateTwitterPreview(ogpData models.OGPData) models.PlatformPreview {
-	maxTitleLen := 70
-	maxDescLen := 200
-	
-	preview := models.PlatformPreview{
-		Platform:    "twitter",
-		Title:       s.truncateString(ogpData.Title, maxTitleLen),
-		Description: s.truncateString(ogpData.Description, maxDescLen),
-		Image:       ogpData.Image,
-		MaxTitleLen: maxTitleLen,
-		MaxDescLen:  maxDescLen,
-		TitleLength: len(ogpData.Title),
-		DescLength:  len(ogpData.Description),
-		IsValid:     true,
-		Warnings:    []string{},
+	if opts.TruncationMode == models.TruncationModeWidth {
+		for _, preview := range s.previewList(&previews) {
+			// The search snippet is always cut by width
+			if preview.Platform != "search" {
+				s.applyWidthTruncation(preview)
+			}
+		}
 	}
 
-	if preview.TitleLength > maxTitleLen {
-		preview.Warnings = append(preview.Warnings, "Title exceeds Twitter limit (70 characters)")
-	}
-	if preview.DescLength > maxDescLen {
-		preview.Warnings = append(preview.Warnings, "Description exceeds Twitter limit (200 characters)")
+	return previews
+}
+
+// PlatformNames lists the preview platforms in the order of models.PlatformPreviews.
+var PlatformNames = []string{"twitter", "facebook", "discord", "mastodon", "misskey", "bluesky", "telegram", "whatsapp", "imessage", "search"}
+
+// PlatformPreview returns the preview for the named platform.
+func (s *OGPService) PlatformPreview(previews models.PlatformPreviews, platform string) (models.PlatformPreview, bool) {
+	for i, preview := range s.previewList(&previews) {
+		if PlatformNames[i] == platform {
+			found := *preview
+			found.Platform = platform
+			return found, true
+		}
 	}
+	return models.PlatformPreview{}, false
+}
 
-	return preview
+func (s *OGPService) previewList(previews *models.PlatformPreviews) []*models.PlatformPreview {
+	return []*models.PlatformPreview{
+		&previews.Twitter,
+		&previews.Facebook,
+		&previews.Discord,
+		&previews.Mastodon,
+		&previews.Misskey,
+		&previews.Bluesky,
+		&previews.Telegram,
+		&previews.WhatsApp,
+		&previews.IMessage,
+		&previews.Search,
+	}
 }
 
-func (s *OGPService) generateFacebookPreview(ogpData models.OGPData) models.PlatformPreview {
-	maxTitleLen := 100
-	maxDescLen := 300
-	
+func (s *OGPService) newPlatformPreview(platform string, ogpData models.OGPData, maxTitleLen, maxDescLen int) models.PlatformPreview {
 	preview := models.PlatformPreview{
-		Platform:    "facebook",
+		Platform:    platform,
 		Title:       s.truncateString(ogpData.Title, maxTitleLen),
 		Description: s.truncateString(ogpData.Description, maxDescLen),
 		Image:       ogpData.Image,
 		MaxTitleLen: maxTitleLen,
 		MaxDescLen:  maxDescLen,
-		TitleLength: len(ogpData.Title),
-		DescLength:  len(ogpData.Description),
+		TitleLength: utf8.RuneCountInString(ogpData.Title),
+		DescLength:  utf8.RuneCountInString(ogpData.Description),
 		IsValid:     true,
 		Warnings:    []string{},
 	}
+	preview.TitleWithinCharLimit = preview.TitleLength <= maxTitleLen
+	preview.DescWithinCharLimit = preview.DescLength <= maxDescLen
+	s.measurePreviewText(&preview, ogpData.Title, ogpData.Description)
+	return preview
+}
 
-	if preview.TitleLength > maxTitleLen {
-		preview.Warnings = append(preview.Warnings, "Title exceeds Facebook limit (100 characters)")
+func (s *OGPService) checkPreviewLengths(preview *models.PlatformPreview, platformName string) {
+	if preview.TitleLength > preview.MaxTitleLen {
+		s.addPreviewIssue(preview, "PREVIEW_TITLE_TOO_LONG", platformName, strconv.Itoa(preview.MaxTitleLen))
 	}
-	if preview.DescLength > maxDescLen {
-		preview.Warnings = append(preview.Warnings, "Description exceeds Facebook limit (300 characters)")
+	if preview.DescLength > preview.MaxDescLen {
+		s.addPreviewIssue(preview, "PREVIEW_DESCRIPTION_TOO_LONG", platformName, strconv.Itoa(preview.MaxDescLen))
 	}
-
-	return preview
 }
 
-func (s *OGPService) generateDiscordPreview(ogpData models.OGPData) models.PlatformPreview {
-	maxTitleLen := 256
-	maxDescLen := 2048
-	
-	preview := models.PlatformPreview{
-		Platform:    "discord",
-		Title:       s.truncateString(ogpData.Title, maxTitleLen),
-		Description: s.truncateString(ogpData.Description, maxDescLen),
-		Image:       ogpData.Image,
-		MaxTitleLen: maxTitleLen,
-		MaxDescLen:  maxDescLen,
-		TitleLength: len(ogpData.Title),
-		DescLength:  len(ogpData.Description),
-		IsValid:     true,
-		Warnings:    []string{},
+// addPreviewIssue adds a catalog issue to a preview, with its message in
+// Hints for info issues and in Warnings otherwise.
+func (s *OGPService) addPreviewIssue(preview *models.PlatformPreview, code string, params ...string) {
+	issue := s.newIssue(code, "", "", params...)
+	preview.Issues = append(preview.Issues, issue)
+	if issue.Severity == models.SeverityInfo {
+		preview.Hints = append(preview.Hints, issue.Message)
+	} else {
+		preview.Warnings = append(preview.Warnings, issue.Message)
 	}
+}
 
-	if preview.TitleLength > maxTitleLen {
-		preview.Warnings = append(preview.Warnings, "Title exceeds Discord limit (256 characters)")
-	}
-	if preview.DescLength > maxDescLen {
-		preview.Warnings = append(preview.Warnings, "Description exceeds Discord limit (2048 characters)")
+// localizePreviews rewrites the preview issues, warnings and hints in lang.
+func (s *OGPService) localizePreviews(previews *models.PlatformPreviews, lang string) {
+	for _, preview := range s.previewList(previews) {
+		if len(preview.Issues) == 0 {
+			continue
+		}
+		s.localizeIssues(preview.Issues, lang)
+		preview.Warnings = preview.Warnings[:0]
+		preview.Hints = preview.Hints[:0]
+		for _, issue := range preview.Issues {
+			if issue.Severity == models.SeverityInfo {
+				preview.Hints = append(preview.Hints, issue.Message)
+			} else {
+				preview.Warnings = append(preview.Warnings, issue.Message)
+			}
+		}
 	}
+}
 
+func (s *OGPService) generateTwitterPreview(ogpData models.OGPData) models.PlatformPreview {
+	preview := s.newPlatformPreview("twitter", ogpData, 70, 200)
+	s.checkPreviewLengths(&preview, "Twitter")
+	return preview
+}
+
+func (s *OGPService) generateFacebookPreview(ogpData models.OGPData) models.PlatformPreview {
+	preview := s.newPlatformPreview("facebook", ogpData, 100, 300)
+	s.checkPreviewLengths(&preview, "Facebook")
 	return preview
 }
 
 func (s *OGPService) truncateString(str string, maxLen int) string {
-	if len(str) <= maxLen {
+	runes := []rune(str)
+	if len(runes) <= maxLen {
 		return str
 	}
-	return str[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 func (s *OGPService) isPrivateIP(host string) bool {
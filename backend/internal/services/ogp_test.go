@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"net/http"
+	"strings"
 	"testing"
 	"ogp-verification-service/internal/models"
 )
@@ -129,7 +132,7 @@ func TestOGPService_validateOGPData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := service.validateOGPData(tt.data)
+			result := service.validateOGPData(RuleInput{OGPData: tt.data}, models.RuleConfig{})
 			
 			if result.IsValid != tt.expected {
 				t.Errorf("Expected IsValid %v, got %v", tt.expected, result.IsValid)
@@ -205,6 +208,9 @@ func TestOGPService_truncateString(t *testing.T) {
 		{"this is a very long string", 10, "this is..."},
 		{"exactly10chars", 10, "exactly10chars"},
 		{"", 10, ""},
+		{"日本語のタイトル", 8, "日本語のタイトル"},
+		{"日本語のとても長いタイトルです", 10, "日本語のとても..."},
+		{"héllo wörld!", 12, "héllo wörld!"},
 	}
 	
 	for _, tt := range tests {
@@ -215,4 +221,175 @@ func TestOGPService_truncateString(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestOGPService_newPlatformPreviewCountsRunes(t *testing.T) {
+	service := NewOGPService()
+
+	preview := service.newPlatformPreview("test", models.OGPData{
+		Title:       strings.Repeat("あ", 10),
+		Description: strings.Repeat("é", 12),
+	}, 10, 11)
+
+	if preview.TitleLength != 10 || preview.DescLength != 12 {
+		t.Errorf("Expected lengths 10 and 12 in characters, got %d and %d", preview.TitleLength, preview.DescLength)
+	}
+	if !preview.TitleWithinCharLimit {
+		t.Error("Expected a 10-character title to fit a 10-character limit")
+	}
+	if preview.DescWithinCharLimit {
+		t.Error("Expected a 12-character description to exceed an 11-character limit")
+	}
+	if preview.Title != strings.Repeat("あ", 10) {
+		t.Errorf("Expected title untouched, got %q", preview.Title)
+	}
+	if want := strings.Repeat("é", 8) + "..."; preview.Description != want {
+		t.Errorf("Expected %q, got %q", want, preview.Description)
+	}
+}
+
+func TestOGPService_VerifyHTML(t *testing.T) {
+	imageData := testPNG(t, 1200, 630)
+	requests := 0
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/images/card.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(imageData)
+	}))
+
+	document := `<html><head>
+		<meta property="og:title" content="Draft title" />
+		<meta property="og:image" content="/images/card.png" />
+	</head></html>`
+
+	response, err := service.VerifyHTML(context.Background(), document, "", false, models.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests without probes, got %d", requests)
+	}
+	if response.OGPData.Title != "Draft title" || response.Previews.Twitter.Title != "Draft title" {
+		t.Errorf("Expected the document to be parsed and previewed, got %+v", response.OGPData)
+	}
+	if response.ImageInfo.Fetched {
+		t.Error("Expected the image not to be probed")
+	}
+
+	response, err = service.VerifyHTML(context.Background(), document, "http://example.test/blog/draft", true, models.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.URL != "http://example.test/blog/draft" {
+		t.Errorf("Expected base URL in response, got %q", response.URL)
+	}
+	if response.ImageInfo.URL != "http://example.test/images/card.png" || response.ImageInfo.Width != 1200 {
+		t.Errorf("Expected image resolved against the base URL and probed, got %+v", response.ImageInfo)
+	}
+
+	if _, err := service.VerifyHTML(context.Background(), document, "ftp://example.test/", false, models.VerifyOptions{}); err == nil {
+		t.Error("Expected error for a non-HTTP base URL")
+	}
+}
+
+func TestOGPService_VerifyHTMLWithoutBaseURL(t *testing.T) {
+	service := NewOGPService()
+
+	document := `<html><head>
+		<title>Draft title</title>
+		<meta property="og:title" content="Draft title" />
+		<meta property="og:url" content="/blog/draft" />
+		<link rel="canonical" href="/blog/other" />
+		<link rel="alternate" hreflang="en" href="/en/blog/draft" />
+	</head></html>`
+
+	response, err := service.VerifyHTML(context.Background(), document, "", false, models.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if response.Previews.Search.DisplayURL != "" {
+		t.Errorf("Expected no display URL without a base URL, got %q", response.Previews.Search.DisplayURL)
+	}
+	for _, issue := range response.SEO.Issues {
+		if issue.Code == "OG_URL_CANONICAL_MISMATCH" || issue.Code == "HREFLANG_NO_SELF" {
+			t.Errorf("Expected %s to be skipped without a base URL", issue.Code)
+		}
+	}
+
+	// Absolute URLs are still compared
+	document = `<html><head>
+		<meta property="og:url" content="https://example.test/blog/draft" />
+		<link rel="canonical" href="https://example.test/blog/other" />
+		<link rel="alternate" hreflang="en" href="https://example.test/en/blog/draft" />
+	</head></html>`
+
+	response, err = service.VerifyHTML(context.Background(), document, "", false, models.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	codes := map[string]bool{}
+	for _, issue := range response.SEO.Issues {
+		codes[issue.Code] = true
+	}
+	if !codes["OG_URL_CANONICAL_MISMATCH"] || !codes["HREFLANG_NO_SELF"] {
+		t.Errorf("Expected canonical checks against the absolute canonical, got %+v", response.SEO.Issues)
+	}
+}
+
+func TestOGPService_FetchOGPDataWithEvents(t *testing.T) {
+	imageData := testPNG(t, 1200, 630)
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(imageData)
+			return
+		}
+		w.Write([]byte(`<html><head><meta property="og:title" content="Streamed" /><meta property="og:image" content="/image.png" /></head></html>`))
+	}))
+
+	var events []models.VerifyEvent
+	response, err := service.FetchOGPDataWithEvents(context.Background(), "http://example.test/", models.VerifyOptions{}, func(event models.VerifyEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stages := []string{}
+	for _, event := range events {
+		if event.Stage != models.VerifyStagePreview || len(stages) == 0 || stages[len(stages)-1] != models.VerifyStagePreview {
+			stages = append(stages, event.Stage)
+		}
+	}
+	expected := []string{
+		models.VerifyStageFetched,
+		models.VerifyStageParsed,
+		models.VerifyStageImageProbed,
+		models.VerifyStageValidated,
+		models.VerifyStageIcons,
+		models.VerifyStagePreview,
+		models.VerifyStageDone,
+	}
+	if strings.Join(stages, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected stages %v, got %v", expected, stages)
+	}
+
+	if events[1].OGPData.Title != "Streamed" || events[2].ImageInfo.Width != 1200 {
+		t.Errorf("Expected the parsed data and image in their events, got %+v and %+v", events[1].OGPData, events[2].ImageInfo)
+	}
+	previews := events[len(events)-1-len(PlatformNames) : len(events)-1]
+	for i, event := range previews {
+		if event.Preview.Platform != PlatformNames[i] {
+			t.Errorf("Expected preview %d for %s, got %s", i, PlatformNames[i], event.Preview.Platform)
+		}
+	}
+	if events[len(events)-1].Response != response {
+		t.Error("Expected the done event to carry the response")
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// DefaultReportExpiry is how long shared reports stay available.
+	DefaultReportExpiry = 30 * 24 * time.Hour
+	// reportIDBytes is the randomness of a report ID: 96 bits, 16 characters
+	// once encoded.
+	reportIDBytes = 12
+	// reportPruneInterval is how often expired reports are deleted.
+	reportPruneInterval = time.Hour
+	// maxMemoryReports bounds the reports a MemoryReportStore keeps.
+	maxMemoryReports = 10000
+)
+
+var ErrReportNotFound = errors.New("report not found")
+
+// ReportStore keeps shared reports. storage.SQLiteReportStore keeps them
+// across restarts; MemoryReportStore is enough for tests and development.
+type ReportStore interface {
+	// Save stores a report under its ID.
+	Save(report models.Report) error
+	// Get returns a report, or ErrReportNotFound.
+	Get(id string) (models.Report, error)
+	// DeleteExpired removes the reports that expired before t and returns
+	// how many there were.
+	DeleteExpired(t time.Time) (int, error)
+}
+
+// MemoryReportStore keeps the most recent reports in memory.
+type MemoryReportStore struct {
+	mu      sync.RWMutex
+	reports map[string]models.Report
+	order   []string
+}
+
+func NewMemoryReportStore() *MemoryReportStore {
+	return &MemoryReportStore{reports: make(map[string]models.Report)}
+}
+
+func (s *MemoryReportStore) Save(report models.Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reports[report.ID] = report
+	s.order = append(s.order, report.ID)
+	for len(s.order) > maxMemoryReports {
+		delete(s.reports, s.order[0])
+		s.order = s.order[1:]
+	}
+	return nil
+}
+
+func (s *MemoryReportStore) Get(id string) (models.Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report, ok := s.reports[id]
+	if !ok {
+		return models.Report{}, ErrReportNotFound
+	}
+	return report, nil
+}
+
+func (s *MemoryReportStore) DeleteExpired(t time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if expiresAt := s.reports[id].ExpiresAt; expiresAt != nil && expiresAt.Before(t) {
+			delete(s.reports, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	deleted := len(s.order) - len(kept)
+	s.order = kept
+	return deleted, nil
+}
+
+// Reports stores verifications under short unguessable IDs so that results
+// can be shared as links, and deletes them once they expire.
+type Reports struct {
+	store  ReportStore
+	expiry time.Duration
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewReports keeps reports in store for expiry. An expiry of zero keeps
+// them forever.
+func NewReports(store ReportStore, expiry time.Duration) *Reports {
+	return &Reports{store: store, expiry: expiry}
+}
+
+// Create stores a verification as a new report and sets the response's
+// Permalink.
+func (r *Reports) Create(response *models.OGPResponse) (models.Report, error) {
+	id, err := newReportID()
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	now := time.Now()
+	report := models.Report{ID: id, CreatedAt: now, Response: response}
+	if r.expiry > 0 {
+		expiresAt := now.Add(r.expiry)
+		report.ExpiresAt = &expiresAt
+	}
+	response.Permalink = "/r/" + id
+	if err := r.store.Save(report); err != nil {
+		response.Permalink = ""
+		return models.Report{}, err
+	}
+	r.prune()
+	return report, nil
+}
+
+// Get returns a report that has not expired.
+func (r *Reports) Get(id string) (models.Report, error) {
+	report, err := r.store.Get(id)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if report.ExpiresAt != nil && !time.Now().Before(*report.ExpiresAt) {
+		return models.Report{}, ErrReportNotFound
+	}
+	return report, nil
+}
+
+// prune deletes expired reports at most once per reportPruneInterval.
+func (r *Reports) prune() {
+	r.mu.Lock()
+	if time.Since(r.lastPruned) < reportPruneInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastPruned = time.Now()
+	r.mu.Unlock()
+
+	if _, err := r.store.DeleteExpired(time.Now()); err != nil {
+		log.Printf("Expired reports not deleted: %v", err)
+	}
+}
+
+// newReportID returns a random URL-safe ID, shorter than newID so that
+// links stay readable.
+func newReportID() (string, error) {
+	b := make([]byte, reportIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate report ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestReports(t *testing.T) {
+	store := NewMemoryReportStore()
+	reports := NewReports(store, 24*time.Hour)
+
+	// A report past its expiry is deleted by the next Create
+	expired := time.Now().Add(-time.Hour)
+	store.Save(models.Report{ID: "expired", ExpiresAt: &expired, Response: &models.OGPResponse{}})
+
+	response := &models.OGPResponse{URL: "https://example.com/"}
+	report, err := reports.Create(response)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.ID) != 16 || strings.ContainsAny(report.ID, "+/=") {
+		t.Errorf("Expected a 16 character URL-safe ID, got %q", report.ID)
+	}
+	if response.Permalink != "/r/"+report.ID {
+		t.Errorf("Expected permalink /r/%s, got %q", report.ID, response.Permalink)
+	}
+	if report.ExpiresAt == nil || report.ExpiresAt.Sub(report.CreatedAt) != 24*time.Hour {
+		t.Errorf("Expected the report to expire after a day, got %v", report.ExpiresAt)
+	}
+	if other, _ := reports.Create(&models.OGPResponse{}); other.ID == report.ID {
+		t.Errorf("Expected unique IDs, got %q twice", report.ID)
+	}
+
+	got, err := reports.Get(report.ID)
+	if err != nil || got.Response.URL != "https://example.com/" {
+		t.Errorf("Expected the stored report, got %+v (%v)", got, err)
+	}
+	if _, err := store.Get("expired"); !errors.Is(err, ErrReportNotFound) {
+		t.Errorf("Expected the expired report to be deleted, got %v", err)
+	}
+
+	// Reports past their expiry are not served before they are deleted
+	store.Save(models.Report{ID: "stale", ExpiresAt: &expired, Response: &models.OGPResponse{}})
+	if _, err := reports.Get("stale"); !errors.Is(err, ErrReportNotFound) {
+		t.Errorf("Expected ErrReportNotFound for an expired report, got %v", err)
+	}
+
+	forever, err := NewReports(store, 0).Create(&models.OGPResponse{})
+	if err != nil || forever.ExpiresAt != nil {
+		t.Errorf("Expected a report without expiry, got %+v (%v)", forever, err)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"ogp-verification-service/internal/models"
+)
+
+// RuleInput is the page data a Rule inspects.
+type RuleInput struct {
+	OGPData models.OGPData
+	// ImageInfo is the probed og:image, zero when there is none.
+	ImageInfo models.ImageMetadata
+
+	// tags are the metadata elements as written in the source, for the
+	// built-in rules that check markup rather than parsed values.
+	tags []sourceTag
+	// seo is the search-engine metadata, for cross-checks against it.
+	seo seoTags
+}
+
+// Rule is a single validation check. Rules are identified by ID in rule
+// configuration; issues returned without a code or severity take the rule ID
+// and a warning severity.
+type Rule interface {
+	ID() string
+	Check(input RuleInput) []models.ValidationIssue
+}
+
+type ruleFunc struct {
+	id    string
+	check func(RuleInput) []models.ValidationIssue
+}
+
+func (r ruleFunc) ID() string { return r.id }
+
+func (r ruleFunc) Check(input RuleInput) []models.ValidationIssue { return r.check(input) }
+
+// NewRule adapts a function to the Rule interface.
+func NewRule(id string, check func(RuleInput) []models.ValidationIssue) Rule {
+	return ruleFunc{id: id, check: check}
+}
+
+// RuleRegistry holds the rules run by validateOGPData, in registration order,
+// and the service-wide rule configuration.
+type RuleRegistry struct {
+	mu     sync.RWMutex
+	rules  []Rule
+	ids    map[string]bool
+	config models.RuleConfig
+}
+
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{ids: make(map[string]bool)}
+}
+
+// Register adds a rule. IDs must be unique.
+func (r *RuleRegistry) Register(rule Rule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := rule.ID()
+	if id == "" {
+		return fmt.Errorf("rule ID is required")
+	}
+	if r.ids[id] {
+		return fmt.Errorf("rule %q is already registered", id)
+	}
+	r.ids[id] = true
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+func (r *RuleRegistry) Has(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ids[id]
+}
+
+func (r *RuleRegistry) list() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Rule{}, r.rules...)
+}
+
+func (r *RuleRegistry) setConfig(config models.RuleConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = config
+}
+
+func (r *RuleRegistry) serviceConfig() models.RuleConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config
+}
+
+// RegisterRule adds a custom rule that runs on every verification.
+func (s *OGPService) RegisterRule(rule Rule) error {
+	return s.rules.Register(rule)
+}
+
+// SetRuleConfig sets the service-wide rule configuration. Per-request
+// configuration is applied on top of it.
+func (s *OGPService) SetRuleConfig(config models.RuleConfig) error {
+	if err := s.validateRuleConfig(config); err != nil {
+		return err
+	}
+	s.rules.setConfig(config)
+	return nil
+}
+
+func (s *OGPService) validateRuleConfig(config models.RuleConfig) error {
+	enabled := map[string]bool{}
+	for _, id := range config.Enabled {
+		if !s.rules.Has(id) {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+		enabled[id] = true
+	}
+	for _, id := range config.Disabled {
+		if !s.rules.Has(id) {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+		if enabled[id] {
+			return fmt.Errorf("rule %q is both enabled and disabled", id)
+		}
+	}
+	for id, severity := range config.Severity {
+		if !s.rules.Has(id) {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+		switch severity {
+		case models.SeverityError, models.SeverityWarning, models.SeverityInfo:
+		default:
+			return fmt.Errorf("invalid severity %q for rule %q", severity, id)
+		}
+	}
+	return nil
+}
+
+// runRules runs every enabled rule. Request settings win over the service
+// configuration for the same rule, so a request can enable a rule the
+// service disabled and the other way round.
+func (s *OGPService) runRules(input RuleInput, config models.RuleConfig) []models.ValidationIssue {
+	disabled := map[string]bool{}
+	severity := map[string]string{}
+	for _, c := range []models.RuleConfig{s.rules.serviceConfig(), config} {
+		for _, id := range c.Enabled {
+			disabled[id] = false
+		}
+		for _, id := range c.Disabled {
+			disabled[id] = true
+		}
+		for id, level := range c.Severity {
+			severity[id] = level
+		}
+	}
+
+	issues := []models.ValidationIssue{}
+	for _, rule := range s.rules.list() {
+		id := rule.ID()
+		if disabled[id] {
+			continue
+		}
+		for _, issue := range rule.Check(input) {
+			if issue.Code == "" {
+				issue.Code = id
+			}
+			if issue.Severity == "" {
+				issue.Severity = models.SeverityWarning
+			}
+			if level, ok := severity[id]; ok {
+				issue.Severity = level
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (s *OGPService) builtinRules() []Rule {
+	return append([]Rule{
+		s.requiredPropertyRule("OG_TITLE_MISSING", "og:title", func(d models.OGPData) string { return d.Title }),
+		s.requiredPropertyRule("OG_DESCRIPTION_MISSING", "og:description", func(d models.OGPData) string { return d.Description }),
+		s.requiredPropertyRule("OG_IMAGE_MISSING", "og:image", func(d models.OGPData) string { return d.Image }),
+		NewRule("OG_IMAGE_INVALID", func(input RuleInput) []models.ValidationIssue {
+			if input.OGPData.Image == "" || s.validateImageURL(input.OGPData.Image) {
+				return nil
+			}
+			return []models.ValidationIssue{s.newIssue("OG_IMAGE_INVALID", "og:image", input.OGPData.Image)}
+		}),
+	}, s.packRules()...)
+}
+
+// packRules are the built-in rules grouped by concern, registered after the
+// core property checks.
+func (s *OGPService) packRules() []Rule {
+	rules := s.tagRules()
+	rules = append(rules, s.localeRules()...)
+	rules = append(rules, s.facebookRules()...)
+	rules = append(rules, s.accessibilityRules()...)
+	return rules
+}
+
+func (s *OGPService) requiredPropertyRule(code, property string, value func(models.OGPData) string) Rule {
+	return NewRule(code, func(input RuleInput) []models.ValidationIssue {
+		if value(input.OGPData) != "" {
+			return nil
+		}
+		return []models.ValidationIssue{s.newIssue(code, property, "")}
+	})
+}
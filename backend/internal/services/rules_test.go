@@ -0,0 +1,128 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+// onlyRules returns a config that disables every registered rule but ids.
+func onlyRules(service *OGPService, ids ...string) models.RuleConfig {
+	keep := map[string]bool{}
+	for _, id := range ids {
+		keep[id] = true
+	}
+	config := models.RuleConfig{}
+	for _, rule := range service.rules.list() {
+		if !keep[rule.ID()] {
+			config.Disabled = append(config.Disabled, rule.ID())
+		}
+	}
+	return config
+}
+
+func acmeSiteNameRule() Rule {
+	return NewRule("ACME_SITE_NAME", func(input RuleInput) []models.ValidationIssue {
+		if input.OGPData.SiteName == "Acme" {
+			return nil
+		}
+		return []models.ValidationIssue{{
+			Message:  "og:site_name must be 'Acme'",
+			Property: "og:site_name",
+			Value:    input.OGPData.SiteName,
+		}}
+	})
+}
+
+func TestOGPService_RegisterRule(t *testing.T) {
+	service := NewOGPService()
+	if err := service.RegisterRule(acmeSiteNameRule()); err != nil {
+		t.Fatalf("RegisterRule() error = %v", err)
+	}
+	if err := service.RegisterRule(acmeSiteNameRule()); err == nil {
+		t.Error("expected duplicate rule ID to be rejected")
+	}
+
+	data := models.OGPData{Title: "Title", Description: "Description", Image: "https://example.com/a.png", SiteName: "Other"}
+	result := service.validateOGPData(RuleInput{OGPData: data}, onlyRules(service, "ACME_SITE_NAME"))
+	if len(result.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %+v", result.Issues)
+	}
+	issue := result.Issues[0]
+	if issue.Code != "ACME_SITE_NAME" || issue.Severity != models.SeverityWarning || issue.Value != "Other" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+
+	data.SiteName = "Acme"
+	if result := service.validateOGPData(RuleInput{OGPData: data}, onlyRules(service, "ACME_SITE_NAME")); len(result.Issues) != 0 {
+		t.Errorf("expected no issues, got %+v", result.Issues)
+	}
+}
+
+func TestOGPService_ruleConfig(t *testing.T) {
+	service := NewOGPService()
+	if err := service.SetRuleConfig(models.RuleConfig{
+		Disabled: []string{"OG_IMAGE_MISSING"},
+		Severity: map[string]string{"OG_TITLE_MISSING": models.SeverityError, "OG_DESCRIPTION_MISSING": models.SeverityError},
+	}); err != nil {
+		t.Fatalf("SetRuleConfig() error = %v", err)
+	}
+
+	// The request overrides the service default for the same rule
+	request := onlyRules(service, "OG_TITLE_MISSING", "OG_DESCRIPTION_MISSING", "OG_IMAGE_MISSING")
+	request.Severity = map[string]string{"OG_DESCRIPTION_MISSING": models.SeverityInfo}
+	result := service.validateOGPData(RuleInput{}, request)
+
+	severities := map[string]string{}
+	for _, issue := range result.Issues {
+		severities[issue.Code] = issue.Severity
+	}
+	expected := map[string]string{
+		"OG_TITLE_MISSING":       models.SeverityError,
+		"OG_DESCRIPTION_MISSING": models.SeverityInfo,
+	}
+	if !reflect.DeepEqual(severities, expected) {
+		t.Errorf("severities = %v, want %v", severities, expected)
+	}
+	if result.IsValid {
+		t.Error("expected an error-severity override to make the result invalid")
+	}
+	if !reflect.DeepEqual(result.Errors, []string{"Missing og:title tag"}) || len(result.Warnings) != 0 {
+		t.Errorf("unexpected compat view: warnings=%v errors=%v", result.Warnings, result.Errors)
+	}
+
+	// A request can enable a rule the service disabled
+	request.Enabled = []string{"OG_IMAGE_MISSING"}
+	result = service.validateOGPData(RuleInput{}, request)
+	if !reflect.DeepEqual(result.Warnings, []string{"Missing og:image tag"}) {
+		t.Errorf("expected the re-enabled rule to run, got warnings=%v", result.Warnings)
+	}
+}
+
+func TestOGPService_validateRuleConfig(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name    string
+		config  models.RuleConfig
+		wantErr bool
+	}{
+		{"empty", models.RuleConfig{}, false},
+		{"known rule", models.RuleConfig{Disabled: []string{"OG_IMAGE_INVALID"}}, false},
+		{"unknown disabled rule", models.RuleConfig{Disabled: []string{"NOPE"}}, true},
+		{"unknown enabled rule", models.RuleConfig{Enabled: []string{"NOPE"}}, true},
+		{"enabled and disabled", models.RuleConfig{Enabled: []string{"OG_IMAGE_INVALID"}, Disabled: []string{"OG_IMAGE_INVALID"}}, true},
+		{"unknown severity rule", models.RuleConfig{Severity: map[string]string{"NOPE": "error"}}, true},
+		{"invalid severity", models.RuleConfig{Severity: map[string]string{"OG_TITLE_MISSING": "fatal"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.validateRuleConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRuleConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"math"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	ScoreCategoryCompleteness = "completeness"
+	ScoreCategoryImageQuality = "image_quality"
+	ScoreCategoryTextLength   = "text_length"
+	ScoreCategoryTechnical    = "technical"
+
+	// recommendedImageWidth and recommendedImageHeight are the size every
+	// platform renders as a large card without upscaling.
+	recommendedImageWidth  = 1200
+	recommendedImageHeight = 630
+	recommendedImageRatio  = 1.91
+	// largeImageBytes is the size above which some platforms drop the image.
+	largeImageBytes = 1 << 20
+
+	technicalErrorPenalty   = 25
+	technicalWarningPenalty = 10
+)
+
+// scoreWeights are the percentage each category contributes to the total.
+var scoreWeights = []struct {
+	name   string
+	weight int
+}{
+	{ScoreCategoryCompleteness, 30},
+	{ScoreCategoryImageQuality, 25},
+	{ScoreCategoryTextLength, 25},
+	{ScoreCategoryTechnical, 20},
+}
+
+// completenessCodes are scored under completeness and so are not penalized
+// again as technical issues.
+var completenessCodes = map[string]bool{
+	"OG_TITLE_MISSING":       true,
+	"OG_DESCRIPTION_MISSING": true,
+	"OG_IMAGE_MISSING":       true,
+}
+
+// scoreResponse rates the page from 0 to 100. When threshold is set, Passed
+// reports whether the score reaches it.
+func (s *OGPService) scoreResponse(response *models.OGPResponse, opts models.VerifyOptions) models.QualityScore {
+	scores := map[string]int{
+		ScoreCategoryCompleteness: s.scoreCompleteness(response.OGPData),
+		ScoreCategoryImageQuality: s.scoreImageQuality(response.OGPData, response.ImageInfo),
+		ScoreCategoryTextLength:   s.scoreTextLength(&response.Previews, opts),
+		ScoreCategoryTechnical:    s.scoreTechnical(response.Validation.Issues, response.SEO.Issues, response.Icons.Issues),
+	}
+
+	result := models.QualityScore{
+		Categories: []models.ScoreCategory{},
+		Threshold:  opts.MinScore,
+	}
+	total := 0
+	for _, category := range scoreWeights {
+		score := scores[category.name]
+		total += score * category.weight
+		result.Categories = append(result.Categories, models.ScoreCategory{
+			Name:   category.name,
+			Score:  score,
+			Weight: category.weight,
+		})
+	}
+	result.Score = int(math.Round(float64(total) / 100))
+	result.Passed = result.Score >= opts.MinScore
+
+	return result
+}
+
+func (s *OGPService) scoreCompleteness(ogpData models.OGPData) int {
+	properties := []struct {
+		value  string
+		points int
+	}{
+		{ogpData.Title, 20},
+		{ogpData.Description, 20},
+		{ogpData.Image, 20},
+		{ogpData.URL, 10},
+		{ogpData.Type, 10},
+		{ogpData.SiteName, 10},
+		{ogpData.ImageAlt, 10},
+	}
+
+	score := 0
+	for _, property := range properties {
+		if property.value != "" {
+			score += property.points
+		}
+	}
+	return score
+}
+
+func (s *OGPService) scoreImageQuality(ogpData models.OGPData, imageInfo models.ImageMetadata) int {
+	if ogpData.Image == "" || imageInfo.Error != "" || imageInfo.Width == 0 || imageInfo.Height == 0 {
+		return 0
+	}
+
+	score := 0
+	switch {
+	case imageInfo.Width >= recommendedImageWidth && imageInfo.Height >= recommendedImageHeight:
+		score += 50
+	case imageInfo.Width >= recommendedImageWidth/2 && imageInfo.Height >= recommendedImageHeight/2:
+		score += 30
+	default:
+		score += 10
+	}
+
+	ratio := float64(imageInfo.Width) / float64(imageInfo.Height)
+	switch deviation := math.Abs(ratio - recommendedImageRatio); {
+	case deviation <= 0.1:
+		score += 25
+	case deviation <= 0.4:
+		score += 10
+	}
+
+	switch {
+	case imageInfo.Size <= largeImageBytes:
+		score += 25
+	case imageInfo.Size <= telegramMaxImageBytes:
+		score += 10
+	}
+
+	return score
+}
+
+// scoreTextLength is the share of title and description checks that fit,
+// across every platform preview. Missing text fails its check, and the
+// description is not checked on platforms that never show one.
+func (s *OGPService) scoreTextLength(previews *models.PlatformPreviews, opts models.VerifyOptions) int {
+	checks, passed := 0, 0
+	for _, preview := range s.previewList(previews) {
+		titleFits, descFits := preview.TitleWithinCharLimit, preview.DescWithinCharLimit
+		if opts.TruncationMode == models.TruncationModeWidth {
+			titleFits, descFits = preview.TitleFitsWidth, preview.DescFitsWidth
+		}
+
+		checks++
+		if preview.TitleLength > 0 && titleFits {
+			passed++
+		}
+		if preview.MaxDescLen == 0 {
+			continue
+		}
+		checks++
+		if preview.DescLength > 0 && descFits {
+			passed++
+		}
+	}
+	if checks == 0 {
+		return 100
+	}
+	return int(math.Round(float64(passed) * 100 / float64(checks)))
+}
+
+func (s *OGPService) scoreTechnical(issueLists ...[]models.ValidationIssue) int {
+	score := 100
+	for _, issues := range issueLists {
+		for _, issue := range issues {
+			if completenessCodes[issue.Code] {
+				continue
+			}
+			switch issue.Severity {
+			case models.SeverityError:
+				score -= technicalErrorPenalty
+			case models.SeverityWarning:
+				score -= technicalWarningPenalty
+			}
+		}
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
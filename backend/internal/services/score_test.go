@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestOGPService_scoreImageQuality(t *testing.T) {
+	service := NewOGPService()
+	data := models.OGPData{Image: "https://example.com/image.png"}
+
+	tests := []struct {
+		name     string
+		data     models.OGPData
+		info     models.ImageMetadata
+		expected int
+	}{
+		{"no image", models.OGPData{}, models.ImageMetadata{}, 0},
+		{"fetch failed", data, models.ImageMetadata{Error: "HTTP error: 404"}, 0},
+		{"recommended", data, models.ImageMetadata{Width: 1200, Height: 630, Size: 200 << 10}, 100},
+		{"half size square", data, models.ImageMetadata{Width: 600, Height: 600, Size: 200 << 10}, 55},
+		{"small and heavy", data, models.ImageMetadata{Width: 200, Height: 100, Size: 3 << 20}, 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.scoreImageQuality(tt.data, tt.info); got != tt.expected {
+				t.Errorf("scoreImageQuality() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOGPService_scoreTechnical(t *testing.T) {
+	service := NewOGPService()
+
+	validation := []models.ValidationIssue{
+		service.newIssue("OG_TITLE_MISSING", "og:title", ""),
+		service.newIssue("OG_IMAGE_INVALID", "og:image", "http://[::1"),
+	}
+	seo := []models.ValidationIssue{service.newIssue("SEO_CANONICAL_MISSING", "canonical", "")}
+
+	// The missing title counts towards completeness only
+	if got := service.scoreTechnical(validation, seo); got != 65 {
+		t.Errorf("scoreTechnical() = %d, want 65", got)
+	}
+}
+
+func TestOGPService_scoreTextLength(t *testing.T) {
+	service := NewOGPService()
+
+	tests := []struct {
+		name     string
+		data     models.OGPData
+		expected int
+	}{
+		{"title and description", models.OGPData{Title: "Example", Description: "An example page"}, 100},
+		// iMessage shows no description, so 10 titles and 9 descriptions are checked
+		{"description missing", models.OGPData{Title: "Example"}, 53},
+		{"title and description missing", models.OGPData{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previews := service.generatePlatformPreviews(nil, tt.data, models.ImageMetadata{}, models.SEOResult{}, models.VerifyOptions{})
+			if got := service.scoreTextLength(&previews, models.VerifyOptions{}); got != tt.expected {
+				t.Errorf("scoreTextLength() = %d, want %d", got, tt.expected)
+			}
+			width := models.VerifyOptions{TruncationMode: models.TruncationModeWidth}
+			if got := service.scoreTextLength(&previews, width); got != tt.expected {
+				t.Errorf("scoreTextLength() by width = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOGPService_scoreResponse(t *testing.T) {
+	service := NewOGPService()
+	data := models.OGPData{
+		Title:       "Example",
+		Description: "An example page",
+		Image:       "https://example.com/image.png",
+		URL:         "https://example.com/",
+		Type:        "website",
+		SiteName:    "Example",
+		FBAppID:     "1234567890",
+	}
+	info := models.ImageMetadata{Width: 1200, Height: 630, Size: 200 << 10}
+	response := &models.OGPResponse{
+		OGPData:    data,
+		ImageInfo:  info,
+		Validation: service.validateOGPData(RuleInput{OGPData: data}, models.RuleConfig{}),
+		Previews:   service.generatePlatformPreviews(nil, data, info, models.SEOResult{}, models.VerifyOptions{}),
+	}
+
+	score := service.scoreResponse(response, models.VerifyOptions{MinScore: 98})
+
+	expected := map[string]int{
+		ScoreCategoryCompleteness: 90,
+		ScoreCategoryImageQuality: 100,
+		ScoreCategoryTextLength:   100,
+		ScoreCategoryTechnical:    100,
+	}
+	weights := 0
+	for _, category := range score.Categories {
+		weights += category.Weight
+		if category.Score != expected[category.Name] {
+			t.Errorf("%s score = %d, want %d", category.Name, category.Score, expected[category.Name])
+		}
+	}
+	if weights != 100 {
+		t.Errorf("category weights sum to %d, want 100", weights)
+	}
+	if score.Score != 97 {
+		t.Errorf("Score = %d, want 97", score.Score)
+	}
+	if score.Passed || score.Threshold != 98 {
+		t.Errorf("expected score below threshold 98 to fail, got %+v", score)
+	}
+}
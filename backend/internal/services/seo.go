@@ -0,0 +1,278 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// Google renders snippet titles at 20px and descriptions at 14px on desktop.
+	searchTitleFontSize  = 20.0
+	searchDescFontSize   = 14.0
+	searchMaxTitleWidth  = 600.0
+	searchMaxDescWidth   = 920.0
+	searchMaxTitleLength = 60
+	searchMaxDescLength  = 160
+)
+
+var hreflangRegex = regexp.MustCompile(`(?i)^([a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|[0-9]{3}))?|x-default)$`)
+
+// seoTags is the raw search-engine metadata found in a document, before validation.
+type seoTags struct {
+	htmlLang     string
+	titles       []string
+	descriptions []string
+	canonicals   []string
+	robots       []string
+	hreflangs    []models.HreflangLink
+}
+
+func (s *OGPService) parseSEOTags(htmlContent string) seoTags {
+	tags := seoTags{}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return tags
+	}
+
+	s.extractSEOTags(doc, &tags)
+	return tags
+}
+
+func (s *OGPService) extractSEOTags(n *html.Node, tags *seoTags) {
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "html":
+			tags.htmlLang = strings.TrimSpace(s.getAttr(n, "lang"))
+		case "title":
+			var text strings.Builder
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.TextNode {
+					text.WriteString(c.Data)
+				}
+			}
+			tags.titles = append(tags.titles, strings.Join(strings.Fields(text.String()), " "))
+		case "meta":
+			name := strings.ToLower(s.getAttr(n, "name"))
+			content := s.getAttr(n, "content")
+			switch name {
+			case "description":
+				tags.descriptions = append(tags.descriptions, strings.TrimSpace(content))
+			case "robots", "googlebot":
+				tags.robots = append(tags.robots, strings.TrimSpace(content))
+			}
+		case "link":
+			rels := strings.Fields(strings.ToLower(s.getAttr(n, "rel")))
+			href := strings.TrimSpace(s.getAttr(n, "href"))
+			for _, rel := range rels {
+				if rel == "canonical" {
+					tags.canonicals = append(tags.canonicals, href)
+				}
+				if rel == "alternate" {
+					if lang := s.getAttr(n, "hreflang"); lang != "" {
+						tags.hreflangs = append(tags.hreflangs, models.HreflangLink{Lang: strings.TrimSpace(lang), URL: href})
+					}
+				}
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.extractSEOTags(c, tags)
+	}
+}
+
+func (s *OGPService) getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func (s *OGPService) validateSEO(pageURL *url.URL, ogpData models.OGPData, tags seoTags, robotsHeader string) models.SEOResult {
+	result := models.SEOResult{
+		HTMLLang:  tags.htmlLang,
+		Hreflangs: tags.hreflangs,
+		Issues:    []models.ValidationIssue{},
+	}
+	if result.Hreflangs == nil {
+		result.Hreflangs = []models.HreflangLink{}
+	}
+
+	switch {
+	case len(tags.titles) == 0 || tags.titles[0] == "":
+		result.Issues = append(result.Issues, s.newIssue("SEO_TITLE_MISSING", "title", ""))
+	case len(tags.titles) > 1:
+		result.Issues = append(result.Issues, s.newIssue("SEO_TITLE_MULTIPLE", "title", ""))
+	}
+	if len(tags.titles) > 0 {
+		result.Title = tags.titles[0]
+	}
+
+	if len(tags.descriptions) == 0 || tags.descriptions[0] == "" {
+		result.Issues = append(result.Issues, s.newIssue("SEO_DESCRIPTION_MISSING", "description", ""))
+	} else {
+		result.Description = tags.descriptions[0]
+	}
+
+	robots := append([]string{}, tags.robots...)
+	if robotsHeader != "" {
+		robots = append(robots, robotsHeader)
+	}
+	result.Robots = strings.Join(robots, ", ")
+	for _, directive := range strings.Split(strings.ToLower(result.Robots), ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "noindex" || directive == "none" {
+			result.Noindex = true
+		}
+	}
+	if result.Noindex {
+		result.Issues = append(result.Issues, s.newIssue("SEO_NOINDEX", "robots", result.Robots))
+	}
+
+	var canonicalURL *url.URL
+	switch len(tags.canonicals) {
+	case 0:
+		result.Issues = append(result.Issues, s.newIssue("SEO_CANONICAL_MISSING", "canonical", ""))
+	default:
+		if len(tags.canonicals) > 1 {
+			result.Issues = append(result.Issues, s.newIssue("SEO_CANONICAL_MULTIPLE", "canonical", ""))
+		}
+		result.Canonical = tags.canonicals[0]
+		parsed, err := url.Parse(result.Canonical)
+		if err != nil || !parsed.IsAbs() {
+			result.Issues = append(result.Issues, s.newIssue("SEO_CANONICAL_RELATIVE", "canonical", result.Canonical))
+		}
+		if err == nil && pageURL != nil {
+			canonicalURL = pageURL.ResolveReference(parsed)
+		} else if err == nil && parsed.IsAbs() {
+			canonicalURL = parsed
+		}
+	}
+
+	// Without the page's URL, relative values cannot be compared
+	if canonicalURL != nil && ogpData.URL != "" {
+		if ogURL, err := s.resolveURL(pageURL, ogpData.URL); err == nil && ogURL.IsAbs() && s.normalizeURL(ogURL) != s.normalizeURL(canonicalURL) {
+			result.Issues = append(result.Issues, s.newIssue("OG_URL_CANONICAL_MISMATCH", "og:url", ogpData.URL, ogpData.URL, result.Canonical))
+		}
+	}
+
+	result.Issues = append(result.Issues, s.checkHreflangs(pageURL, canonicalURL, tags.hreflangs)...)
+	result.Warnings, result.Errors = s.issueMessages(result.Issues)
+
+	return result
+}
+
+func (s *OGPService) checkHreflangs(pageURL, canonicalURL *url.URL, hreflangs []models.HreflangLink) []models.ValidationIssue {
+	issues := []models.ValidationIssue{}
+	if len(hreflangs) == 0 {
+		return issues
+	}
+
+	self := pageURL
+	if canonicalURL != nil {
+		self = canonicalURL
+	}
+
+	seen := map[string]string{}
+	hasSelf := false
+	for _, link := range hreflangs {
+		if !hreflangRegex.MatchString(link.Lang) {
+			issues = append(issues, s.newIssue("HREFLANG_INVALID", "hreflang", link.URL, link.Lang))
+		}
+
+		parsed, err := url.Parse(link.URL)
+		if err != nil || !parsed.IsAbs() {
+			issues = append(issues, s.newIssue("HREFLANG_RELATIVE", "hreflang", link.URL, link.Lang))
+		}
+		if err != nil {
+			continue
+		}
+		if pageURL != nil {
+			parsed = pageURL.ResolveReference(parsed)
+		}
+		resolved := s.normalizeURL(parsed)
+
+		lang := strings.ToLower(link.Lang)
+		if previous, ok := seen[lang]; ok && previous != resolved {
+			issues = append(issues, s.newIssue("HREFLANG_CONFLICT", "hreflang", link.URL, link.Lang))
+		}
+		seen[lang] = resolved
+
+		if self != nil && resolved == s.normalizeURL(self) {
+			hasSelf = true
+		}
+	}
+
+	// The page's own URL is unknown when verifying HTML without a base URL
+	if !hasSelf && self != nil {
+		issues = append(issues, s.newIssue("HREFLANG_NO_SELF", "hreflang", ""))
+	}
+
+	return issues
+}
+
+func (s *OGPService) normalizeURL(u *url.URL) string {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = strings.ToLower(normalized.Host)
+	normalized.Fragment = ""
+	if normalized.Path == "" {
+		normalized.Path = "/"
+	}
+	if len(normalized.Path) > 1 {
+		normalized.Path = strings.TrimSuffix(normalized.Path, "/")
+	}
+	return normalized.String()
+}
+
+func (s *OGPService) generateSearchPreview(pageURL *url.URL, ogpData models.OGPData, seo models.SEOResult) models.PlatformPreview {
+	snippet := models.OGPData{Title: seo.Title, Description: seo.Description}
+	if snippet.Title == "" {
+		snippet.Title = ogpData.Title
+	}
+	if snippet.Description == "" {
+		snippet.Description = ogpData.Description
+	}
+
+	preview := s.newPlatformPreview("search", snippet, searchMaxTitleLength, searchMaxDescLength)
+	preview.Image = ""
+	preview.DisplayURL = s.searchDisplayURL(pageURL)
+
+	// Google truncates snippets by rendered width only
+	preview.Title = preview.DisplayTitle
+	preview.Description = preview.DisplayDescription
+
+	if !preview.TitleFitsWidth {
+		s.addPreviewIssue(&preview, "SEARCH_TITLE_TOO_WIDE")
+	}
+	if !preview.DescFitsWidth {
+		s.addPreviewIssue(&preview, "SEARCH_DESCRIPTION_TOO_WIDE")
+	}
+	if seo.Noindex {
+		preview.IsValid = false
+		s.addPreviewIssue(&preview, "SEARCH_NOINDEX")
+	}
+
+	return preview
+}
+
+func (s *OGPService) searchDisplayURL(pageURL *url.URL) string {
+	if pageURL == nil {
+		return ""
+	}
+
+	parts := []string{pageURL.Scheme + "://" + pageURL.Host}
+	for _, segment := range strings.Split(pageURL.Path, "/") {
+		if segment != "" {
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, " › ")
+}
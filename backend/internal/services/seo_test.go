@@ -0,0 +1,161 @@
+package services
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"ogp-verification-service/internal/models"
+)
+
+const seoTestHTML = `<html lang="en"><head>
+	<title>  Example   Article </title>
+	<meta name="description" content="An example article" />
+	<link rel="canonical" href="https://example.com/article" />
+	<link rel="alternate" hreflang="en" href="https://example.com/article" />
+	<link rel="alternate" hreflang="ja-JP" href="https://example.com/ja/article" />
+	<link rel="alternate" hreflang="x-default" href="https://example.com/article" />
+	<meta property="og:title" content="Example Article" />
+	<meta property="og:url" content="https://example.com/article/" />
+</head></html>`
+
+func TestOGPService_validateSEO(t *testing.T) {
+	service := NewOGPService()
+	pageURL, _ := url.Parse("https://example.com/article?utm_source=test")
+
+	tests := []struct {
+		name             string
+		html             string
+		robotsHeader     string
+		expectedWarnings []string
+		expectNoindex    bool
+	}{
+		{
+			name:             "complete metadata",
+			html:             seoTestHTML,
+			expectedWarnings: []string{},
+		},
+		{
+			name:         "noindex header",
+			html:         seoTestHTML,
+			robotsHeader: "noindex, nofollow",
+			expectedWarnings: []string{
+				"Page is marked noindex and will not appear in search results",
+			},
+			expectNoindex: true,
+		},
+		{
+			name: "missing metadata",
+			html: `<html><head><meta name="robots" content="none" /></head></html>`,
+			expectedWarnings: []string{
+				"Missing <title> tag",
+				"Missing meta description",
+				"Page is marked noindex and will not appear in search results",
+				"Missing canonical link",
+			},
+			expectNoindex: true,
+		},
+		{
+			name: "conflicting canonical and hreflang",
+			html: `<html><head>
+				<title>Title</title>
+				<meta name="description" content="Description" />
+				<link rel="canonical" href="/article" />
+				<link rel="canonical" href="/other" />
+				<link rel="alternate" hreflang="english" href="https://example.com/en" />
+				<link rel="alternate" hreflang="ja" href="https://example.com/ja" />
+				<link rel="alternate" hreflang="ja" href="https://example.com/jp" />
+				<meta property="og:url" content="https://example.com/elsewhere" />
+			</head></html>`,
+			expectedWarnings: []string{
+				"Multiple canonical links found",
+				"Canonical URL should be absolute",
+				"og:url (https://example.com/elsewhere) does not match canonical URL (/article)",
+				`Invalid hreflang value "english"`,
+				`hreflang "ja" points to multiple URLs`,
+				"hreflang links do not include this page",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ogpData := service.parseOGPTags(tt.html)
+			result := service.validateSEO(pageURL, ogpData, service.parseSEOTags(tt.html), tt.robotsHeader)
+
+			if strings.Join(result.Warnings, "\n") != strings.Join(tt.expectedWarnings, "\n") {
+				t.Errorf("Expected warnings %q, got %q", tt.expectedWarnings, result.Warnings)
+			}
+			if result.Noindex != tt.expectNoindex {
+				t.Errorf("Expected noindex %v, got %v", tt.expectNoindex, result.Noindex)
+			}
+		})
+	}
+}
+
+func TestOGPService_generateSearchPreview(t *testing.T) {
+	service := NewOGPService()
+	pageURL, _ := url.Parse("https://example.com/blog/post")
+
+	t.Run("falls back to og tags", func(t *testing.T) {
+		result := service.generateSearchPreview(pageURL, models.OGPData{Title: "OG Title", Description: "OG Description"}, models.SEOResult{})
+
+		if result.Title != "OG Title" || result.Description != "OG Description" {
+			t.Errorf("Expected og fallback, got %q / %q", result.Title, result.Description)
+		}
+		if result.DisplayURL != "https://example.com › blog › post" {
+			t.Errorf("Unexpected display URL %q", result.DisplayURL)
+		}
+		if len(result.Warnings) != 0 {
+			t.Errorf("Expected no warnings, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("truncates by pixel width", func(t *testing.T) {
+		title := strings.Repeat("WWWW ", 20)
+		result := service.generateSearchPreview(pageURL, models.OGPData{}, models.SEOResult{Title: title})
+
+		if !strings.HasSuffix(result.Title, "...") {
+			t.Errorf("Expected truncated title, got %q", result.Title)
+		}
+		if width := service.estimateTextWidth(result.Title, searchTitleFontSize); width > searchMaxTitleWidth {
+			t.Errorf("Expected truncated title to fit in %vpx, got %vpx", searchMaxTitleWidth, width)
+		}
+		if len(result.Warnings) != 1 || result.Warnings[0] != "Title exceeds Google limit (600px)" {
+			t.Errorf("Expected pixel width warning, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("noindex", func(t *testing.T) {
+		result := service.generateSearchPreview(pageURL, models.OGPData{Title: "Title"}, models.SEOResult{Noindex: true})
+		if result.IsValid {
+			t.Error("Expected noindex page to be invalid for search")
+		}
+	})
+}
+
+func TestOGPService_FetchOGPData_SEO(t *testing.T) {
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(seoTestHTML))
+	}))
+
+	resp, err := service.FetchOGPData("http://example.com/article")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.SEO.Title != "Example Article" {
+		t.Errorf("Expected SEO title, got %q", resp.SEO.Title)
+	}
+	if resp.SEO.Canonical != "https://example.com/article" {
+		t.Errorf("Expected canonical, got %q", resp.SEO.Canonical)
+	}
+	if len(resp.SEO.Hreflangs) != 3 {
+		t.Errorf("Expected 3 hreflang links, got %v", resp.SEO.Hreflangs)
+	}
+	if resp.Previews.Search.Platform != "search" {
+		t.Errorf("Expected search preview, got %q", resp.Previews.Search.Platform)
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// sourceTag is a metadata element as written in the HTML source, with its
+// 1-based line and column.
+type sourceTag struct {
+	// key is the meta property or name, "title", or the link relation
+	// ("canonical", "hreflang", "icon", "apple-touch-icon" or "manifest").
+	key string
+	// attr is the attribute key came from: "property", "name", "rel" or "".
+	attr       string
+	value      string
+	hasContent bool
+	line       int
+	column     int
+	inBody     bool
+}
+
+// scanSourceTags tokenizes the document without building a tree so that
+// every metadata element keeps its original position, including duplicates
+// and elements the parser would otherwise move.
+func (s *OGPService) scanSourceTags(htmlContent string) []sourceTag {
+	tags := []sourceTag{}
+	lineStarts := s.lineStarts(htmlContent)
+
+	z := html.NewTokenizer(strings.NewReader(htmlContent))
+	offset := 0
+	inBody := false
+	for {
+		tokenType := z.Next()
+		if tokenType == html.ErrorToken {
+			return tags
+		}
+		start := offset
+		offset += len(z.Raw())
+
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := z.Token()
+		line, column := s.position(htmlContent, lineStarts, start)
+		tag := sourceTag{line: line, column: column, inBody: inBody}
+
+		switch token.Data {
+		case "body":
+			inBody = true
+			continue
+		case "title":
+			tag.key = "title"
+		case "meta":
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "property":
+					tag.key, tag.attr = attr.Val, "property"
+				case "name":
+					if tag.attr != "property" {
+						tag.key, tag.attr = attr.Val, "name"
+					}
+				case "content":
+					tag.value, tag.hasContent = attr.Val, true
+				}
+			}
+		case "link":
+			rel := strings.Fields(strings.ToLower(s.tokenAttr(token, "rel")))
+			for _, r := range rel {
+				switch {
+				case r == "canonical" || r == "manifest" || r == "icon":
+					tag.key = r
+				case r == "apple-touch-icon" || r == "apple-touch-icon-precomposed":
+					tag.key = "apple-touch-icon"
+				case r == "alternate" && s.tokenAttr(token, "hreflang") != "":
+					tag.key = "hreflang"
+				}
+			}
+			tag.attr = "rel"
+			tag.value = s.tokenAttr(token, "href")
+		}
+
+		if tag.key != "" {
+			tags = append(tags, tag)
+		}
+	}
+}
+
+func (s *OGPService) tokenAttr(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func (s *OGPService) lineStarts(content string) []int {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// position converts a byte offset to a 1-based line and rune column.
+func (s *OGPService) position(content string, lineStarts []int, offset int) (int, int) {
+	line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+	column := utf8.RuneCountInString(content[lineStarts[line]:offset]) + 1
+	return line + 1, column
+}
@@ -0,0 +1,522 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const (
+	// maxWebhookAttempts is how many times a delivery is tried before it is
+	// logged as failed.
+	maxWebhookAttempts = 5
+	// webhookBackoff is the wait before the first retry; it doubles after
+	// every failed attempt.
+	webhookBackoff = 2 * time.Second
+	// maxDeliveriesPerWebhook bounds each webhook's delivery log.
+	maxDeliveriesPerWebhook = 50
+	// maxTrackedURLs bounds the pages whose validation state is remembered.
+	maxTrackedURLs = 10000
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidToken is returned when the management token of a webhook
+	// or monitor is missing or wrong.
+	ErrInvalidToken = errors.New("invalid or missing token")
+)
+
+// webhookEvents are the events a webhook can subscribe to.
+var webhookEvents = map[string]bool{
+	models.WebhookEventJobCompleted:      true,
+	models.WebhookEventValidationChanged: true,
+	models.WebhookEventMonitorRegressed:  true,
+}
+
+// WebhookManager notifies registered webhooks when jobs finish, when a
+// page's validation state changes and when a monitor finds a regression.
+// Payloads are signed with the webhook's secret and failed deliveries are
+// retried with exponential backoff.
+type WebhookManager struct {
+	service *OGPService
+	backoff time.Duration
+
+	mu         sync.Mutex
+	webhooks   map[string]*models.Webhook
+	order      []string
+	deliveries map[string][]*models.WebhookDelivery
+	states     map[string]models.ValidationState
+	closed     bool
+
+	// ctx is cancelled when Shutdown gives up waiting for deliveries.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWebhookManager(service *OGPService) *WebhookManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookManager{
+		service:    service,
+		backoff:    webhookBackoff,
+		webhooks:   map[string]*models.Webhook{},
+		deliveries: map[string][]*models.WebhookDelivery{},
+		states:     map[string]models.ValidationState{},
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// ValidateWebhook checks a webhook's URL, events and page filters.
+func (s *OGPService) ValidateWebhook(req models.WebhookRequest) error {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || !parsed.IsAbs() || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if s.isPrivateIP(parsed.Hostname()) {
+		return fmt.Errorf("private IP addresses are not allowed")
+	}
+	if len(req.Events) == 0 {
+		return fmt.Errorf("events is required")
+	}
+	for _, event := range req.Events {
+		if !webhookEvents[event] {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+	for _, prefix := range req.URLs {
+		if parsed, err := url.Parse(prefix); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("urls must be absolute http or https URLs")
+		}
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature of body sent at
+// timestamp, in Unix seconds: the hex HMAC-SHA256 of the timestamp, a dot and
+// the body, keyed with the webhook's secret. Signing the timestamp, which is
+// sent as X-Webhook-Timestamp, lets receivers reject replayed deliveries.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newToken returns a management token and the hash kept in its place.
+func newToken() (string, string, error) {
+	token, err := newID()
+	if err != nil {
+		return "", "", err
+	}
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenMatches reports whether token hashes to hash, in constant time.
+func tokenMatches(hash, token string) bool {
+	return token != "" && hmac.Equal([]byte(hash), []byte(hashToken(token)))
+}
+
+// Register adds a webhook and returns it with its secret and management
+// token, which are not returned again.
+func (m *WebhookManager) Register(req models.WebhookRequest) (models.Webhook, error) {
+	if err := m.service.ValidateWebhook(req); err != nil {
+		return models.Webhook{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = newID(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	webhook := &models.Webhook{
+		ID:        id,
+		URL:       req.URL,
+		Events:    req.Events,
+		URLs:      req.URLs,
+		Secret:    secret,
+		TokenHash: tokenHash,
+		CreatedAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.webhooks[id] = webhook
+	m.order = append(m.order, id)
+
+	created := *webhook
+	created.Token = token
+	return created, nil
+}
+
+// Authorize checks a webhook's management token.
+func (m *WebhookManager) Authorize(id, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return ErrWebhookNotFound
+	}
+	if !tokenMatches(webhook.TokenHash, token) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// Get returns a webhook without its secret.
+func (m *WebhookManager) Get(id string) (models.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	found := *webhook
+	found.Secret = ""
+	return found, nil
+}
+
+// Delete removes a webhook and its delivery log. Deliveries in progress
+// still finish.
+func (m *WebhookManager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+	delete(m.deliveries, id)
+	for i, registered := range m.order {
+		if registered == id {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Deliveries returns a webhook's delivery log, newest first.
+func (m *WebhookManager) Deliveries(id string) ([]models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.webhooks[id]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+	log := m.deliveries[id]
+	deliveries := make([]models.WebhookDelivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, *log[i])
+	}
+	return deliveries, nil
+}
+
+// Test sends a webhook.test event once, without retries, and returns the
+// logged delivery.
+func (m *WebhookManager) Test(ctx context.Context, id string) (models.WebhookDelivery, error) {
+	m.mu.Lock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		m.mu.Unlock()
+		return models.WebhookDelivery{}, ErrWebhookNotFound
+	}
+	hook := *webhook
+	delivery, body, err := m.newDelivery(hook, models.WebhookPayload{Event: models.WebhookEventTest})
+	m.mu.Unlock()
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	statusCode, err := m.post(ctx, hook, delivery, body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordAttempt(delivery, statusCode, err, true)
+	return *delivery, nil
+}
+
+// JobFinished sends job.completed for a finished job. The job's results are
+// left out of the payload; their summary is sent instead.
+func (m *WebhookManager) JobFinished(job models.Job) {
+	payload := models.WebhookPayload{Event: models.WebhookEventJobCompleted}
+	switch {
+	case job.Batch != nil:
+		payload.Summary = &job.Batch.Summary
+	case job.Crawl != nil:
+		payload.Summary = &job.Crawl.Summary
+	}
+	job.Results, job.Batch, job.Crawl = nil, nil, nil
+	payload.Job = &job
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, webhook := range m.subscribers(models.WebhookEventJobCompleted, "") {
+		m.dispatch(*webhook, payload)
+	}
+}
+
+// Verified compares a verified page with the previous verification of the
+// same URL and sends validation.changed when its validity or errors differ.
+// Only pages watched by a webhook are remembered, and the first
+// verification of a page only records its state.
+func (m *WebhookManager) Verified(response *models.OGPResponse) {
+	current := m.service.validationState(response)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhooks := m.subscribers(models.WebhookEventValidationChanged, response.URL)
+	if len(webhooks) == 0 {
+		return
+	}
+	previous, seen := m.states[response.URL]
+	if !seen && len(m.states) >= maxTrackedURLs {
+		return
+	}
+	m.states[response.URL] = current
+	if !seen || (previous.IsValid == current.IsValid && strings.Join(previous.ErrorCodes, ",") == strings.Join(current.ErrorCodes, ",")) {
+		return
+	}
+
+	change := &models.ValidationChange{
+		URL:       response.URL,
+		Previous:  previous,
+		Current:   current,
+		Regressed: previous.IsValid && !current.IsValid,
+	}
+	for _, code := range current.ErrorCodes {
+		if !slices.Contains(previous.ErrorCodes, code) {
+			change.Regressed = true
+		}
+	}
+	for _, webhook := range webhooks {
+		m.dispatch(*webhook, models.WebhookPayload{Event: models.WebhookEventValidationChanged, Change: change})
+	}
+}
+
+// MonitorRegressed sends monitor.regressed for a monitor's alert.
+func (m *WebhookManager) MonitorRegressed(alert models.MonitorAlert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, webhook := range m.subscribers(models.WebhookEventMonitorRegressed, alert.URL) {
+		m.dispatch(*webhook, models.WebhookPayload{Event: models.WebhookEventMonitorRegressed, Alert: &alert})
+	}
+}
+
+// Shutdown stops sending new events and waits for deliveries in progress,
+// including their retries. When ctx ends first the pending retries are
+// dropped and logged as failed.
+func (m *WebhookManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// validationState is what a webhook or monitor compares between two
+// verifications of a page.
+func (s *OGPService) validationState(response *models.OGPResponse) models.ValidationState {
+	state := models.ValidationState{
+		IsValid:    response.Validation.IsValid,
+		Score:      response.Score.Score,
+		ErrorCodes: []string{},
+	}
+	for _, issues := range [][]models.ValidationIssue{response.Validation.Issues, response.Validation.Accessibility.Issues} {
+		for _, issue := range issues {
+			if issue.Severity == models.SeverityError && !slices.Contains(state.ErrorCodes, issue.Code) {
+				state.ErrorCodes = append(state.ErrorCodes, issue.Code)
+			}
+		}
+	}
+	sort.Strings(state.ErrorCodes)
+	return state
+}
+
+// subscribers returns the webhooks for event, in registration order. For
+// page events only webhooks watching pageURL are returned. The caller holds
+// m.mu.
+func (m *WebhookManager) subscribers(event, pageURL string) []*models.Webhook {
+	webhooks := []*models.Webhook{}
+	for _, id := range m.order {
+		webhook := m.webhooks[id]
+		if !slices.Contains(webhook.Events, event) {
+			continue
+		}
+		if pageURL != "" && len(webhook.URLs) > 0 {
+			watched := false
+			for _, prefix := range webhook.URLs {
+				watched = watched || strings.HasPrefix(pageURL, prefix)
+			}
+			if !watched {
+				continue
+			}
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// dispatch logs a delivery of payload to webhook and sends it in the
+// background. The caller holds m.mu.
+func (m *WebhookManager) dispatch(webhook models.Webhook, payload models.WebhookPayload) {
+	if m.closed {
+		return
+	}
+	delivery, body, err := m.newDelivery(webhook, payload)
+	if err != nil {
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for attempt := 1; ; attempt++ {
+			statusCode, err := m.post(m.ctx, webhook, delivery, body)
+
+			m.mu.Lock()
+			final := err == nil || attempt == maxWebhookAttempts || m.ctx.Err() != nil
+			m.recordAttempt(delivery, statusCode, err, final)
+			m.mu.Unlock()
+			if final {
+				return
+			}
+
+			select {
+			case <-time.After(m.backoff << (attempt - 1)):
+			case <-m.ctx.Done():
+				m.mu.Lock()
+				delivery.Status = models.DeliveryStatusFailed
+				delivery.NextAttemptAt = nil
+				delivery.Error = "shut down before retrying: " + delivery.Error
+				m.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// newDelivery adds a pending delivery to the webhook's log and returns it
+// with the payload body. The caller holds m.mu.
+func (m *WebhookManager) newDelivery(webhook models.Webhook, payload models.WebhookPayload) (*models.WebhookDelivery, []byte, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, nil, err
+	}
+	payload.ID = id
+	payload.Timestamp = time.Now()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		ID:        id,
+		WebhookID: webhook.ID,
+		Event:     payload.Event,
+		Status:    models.DeliveryStatusPending,
+		CreatedAt: payload.Timestamp,
+	}
+	log := append(m.deliveries[webhook.ID], delivery)
+	if len(log) > maxDeliveriesPerWebhook {
+		log = log[len(log)-maxDeliveriesPerWebhook:]
+	}
+	m.deliveries[webhook.ID] = log
+	return delivery, body, nil
+}
+
+// recordAttempt updates a delivery after an attempt. A non-final failure
+// schedules the next attempt. The caller holds m.mu.
+func (m *WebhookManager) recordAttempt(delivery *models.WebhookDelivery, statusCode int, err error, final bool) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.StatusCode = statusCode
+	delivery.NextAttemptAt = nil
+	delivery.Error = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryStatusSucceeded
+	case final:
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		next := now.Add(m.backoff << (delivery.Attempts - 1))
+		delivery.NextAttemptAt = &next
+	}
+}
+
+// post sends one attempt of a delivery and returns the response status.
+func (m *WebhookManager) post(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OGP-Verification-Service/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	timestamp := time.Now().Unix()
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := m.service.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deliver: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
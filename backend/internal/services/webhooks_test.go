@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+// newWebhookReceiver answers deliveries with statuses in turn, then 200.
+func newWebhookReceiver(t *testing.T, statuses ...int) (*WebhookManager, *webhookReceiver) {
+	t.Helper()
+
+	receiver := &webhookReceiver{statuses: statuses}
+	service := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		if len(receiver.statuses) > 0 {
+			w.WriteHeader(receiver.statuses[0])
+			receiver.statuses = receiver.statuses[1:]
+		}
+	}))
+
+	webhooks := NewWebhookManager(service)
+	webhooks.backoff = time.Millisecond
+	return webhooks, receiver
+}
+
+func mustRegister(t *testing.T, webhooks *WebhookManager, req models.WebhookRequest) models.Webhook {
+	t.Helper()
+
+	webhook, err := webhooks.Register(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return webhook
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"webhook.test"}`)
+
+	signature := SignWebhookPayload("s3cret", 1700000000, body)
+	if signature != "sha256=18cc98b830c6f46feb8693442d4b0969431b9d566f1bac29782dc9b044b432f2" {
+		t.Errorf("Unexpected signature %q", signature)
+	}
+	if SignWebhookPayload("s3cret", 1700000001, body) == signature {
+		t.Error("Expected the signature to cover the timestamp")
+	}
+}
+
+func TestWebhookManager_JobFinished(t *testing.T) {
+	webhooks, receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	webhook := mustRegister(t, webhooks, models.WebhookRequest{URL: "http://hooks.example.test/ogp", Events: []string{models.WebhookEventJobCompleted}, Secret: "s3cret"})
+	mustRegister(t, webhooks, models.WebhookRequest{URL: "http://other.example.test/ogp", Events: []string{models.WebhookEventValidationChanged}})
+
+	webhooks.JobFinished(models.Job{
+		ID:      "job-1",
+		Status:  models.JobStatusSucceeded,
+		Results: []models.BatchResult{{URL: "https://example.com/"}},
+		Batch:   &models.BatchResponse{Summary: models.BatchSummary{Total: 1, Succeeded: 1}},
+	})
+	if err := webhooks.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(receiver.requests) != 3 {
+		t.Fatalf("Expected two retries after two failures, got %d requests", len(receiver.requests))
+	}
+	last, body := receiver.requests[2], receiver.bodies[2]
+	if last.Host != "hooks.example.test" || last.Header.Get("X-Webhook-Event") != models.WebhookEventJobCompleted {
+		t.Errorf("Expected job.completed posted to the subscribed webhook, got %s %s", last.Host, last.Header.Get("X-Webhook-Event"))
+	}
+	timestamp, err := strconv.ParseInt(last.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Errorf("Expected a current X-Webhook-Timestamp, got %q", last.Header.Get("X-Webhook-Timestamp"))
+	}
+	if signature := last.Header.Get("X-Webhook-Signature"); signature != SignWebhookPayload("s3cret", timestamp, body) {
+		t.Errorf("Signature %q does not match the timestamp and body", signature)
+	}
+
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+	if payload.Job == nil || payload.Job.ID != "job-1" || payload.Job.Results != nil || payload.Job.Batch != nil {
+		t.Errorf("Expected the job without its results, got %+v", payload.Job)
+	}
+	if payload.Summary == nil || payload.Summary.Succeeded != 1 {
+		t.Errorf("Expected the batch summary, got %+v", payload.Summary)
+	}
+
+	deliveries, err := webhooks.Deliveries(webhook.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected one delivery, got %d", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.ID != payload.ID || delivery.Status != models.DeliveryStatusSucceeded || delivery.Attempts != 3 || delivery.StatusCode != http.StatusOK || delivery.Error != "" {
+		t.Errorf("Expected a delivery that succeeded on the third attempt, got %+v", delivery)
+	}
+}
+
+func TestWebhookManager_DeliveryFailure(t *testing.T) {
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		statuses := []int{}
+		for i := 0; i < maxWebhookAttempts; i++ {
+			statuses = append(statuses, http.StatusServiceUnavailable)
+		}
+		webhooks, receiver := newWebhookReceiver(t, statuses...)
+		webhook := mustRegister(t, webhooks, models.WebhookRequest{URL: "http://hooks.example.test/", Events: []string{models.WebhookEventJobCompleted}})
+
+		webhooks.JobFinished(models.Job{ID: "job-1", Status: models.JobStatusFailed})
+		webhooks.Shutdown(context.Background())
+
+		deliveries, _ := webhooks.Deliveries(webhook.ID)
+		if len(receiver.requests) != maxWebhookAttempts || deliveries[0].Status != models.DeliveryStatusFailed || deliveries[0].Error != "HTTP error: 503" {
+			t.Errorf("Expected %d failed attempts, got %d requests and %+v", maxWebhookAttempts, len(receiver.requests), deliveries[0])
+		}
+	})
+
+	t.Run("drops retries at shutdown", func(t *testing.T) {
+		webhooks, receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+		webhooks.backoff = time.Hour
+		webhook := mustRegister(t, webhooks, models.WebhookRequest{URL: "http://hooks.example.test/", Events: []string{models.WebhookEventJobCompleted}})
+
+		webhooks.JobFinished(models.Job{ID: "job-1", Status: models.JobStatusSucceeded})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := webhooks.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected DeadlineExceeded, got %v", err)
+		}
+
+		deliveries, _ := webhooks.Deliveries(webhook.ID)
+		delivery := deliveries[0]
+		if len(receiver.requests) != 1 || delivery.Status != models.DeliveryStatusFailed || delivery.Attempts != 1 || delivery.NextAttemptAt != nil || !strings.HasPrefix(delivery.Error, "shut down before retrying") {
+			t.Errorf("Expected the retry to be dropped, got %d requests and %+v", len(receiver.requests), delivery)
+		}
+
+		webhooks.JobFinished(models.Job{ID: "job-2", Status: models.JobStatusSucceeded})
+		if deliveries, _ := webhooks.Deliveries(webhook.ID); len(deliveries) != 1 {
+			t.Errorf("Expected no deliveries after shutdown, got %d", len(deliveries))
+		}
+	})
+}
+
+func TestWebhookManager_Verified(t *testing.T) {
+	webhooks, receiver := newWebhookReceiver(t)
+	webhook := mustRegister(t, webhooks, models.WebhookRequest{
+		URL:    "http://hooks.example.test/",
+		Events: []string{models.WebhookEventValidationChanged},
+		URLs:   []string{"https://site.example.test/blog/"},
+	})
+
+	page := func(pageURL string, codes ...string) *models.OGPResponse {
+		response := &models.OGPResponse{URL: pageURL, Validation: models.ValidationResult{IsValid: len(codes) == 0}}
+		for _, code := range codes {
+			response.Validation.Issues = append(response.Validation.Issues, models.ValidationIssue{Code: code, Severity: models.SeverityError})
+		}
+		response.Validation.Issues = append(response.Validation.Issues, models.ValidationIssue{Code: "OG_LOCALE_MISSING", Severity: models.SeverityWarning})
+		return response
+	}
+
+	// The first verification records the state; only changes are sent
+	webhooks.Verified(page("https://site.example.test/blog/post"))
+	webhooks.Verified(page("https://site.example.test/blog/post"))
+	webhooks.Verified(page("https://site.example.test/blog/post", "OG_IMAGE_MISSING", "OG_TITLE_MISSING"))
+	webhooks.Verified(page("https://site.example.test/blog/post", "OG_TITLE_MISSING"))
+	webhooks.Verified(page("https://site.example.test/about"))
+	webhooks.Verified(page("https://site.example.test/about", "OG_TITLE_MISSING"))
+	webhooks.Shutdown(context.Background())
+
+	if len(receiver.bodies) != 2 {
+		t.Fatalf("Expected 2 changes of the watched page, got %d deliveries", len(receiver.bodies))
+	}
+	changes := []models.ValidationChange{}
+	for _, body := range receiver.bodies {
+		var payload models.WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("Failed to unmarshal payload: %v", err)
+		}
+		changes = append(changes, *payload.Change)
+	}
+	// Deliveries are concurrent, so match them by their current codes
+	if len(changes[0].Current.ErrorCodes) == 1 {
+		changes[0], changes[1] = changes[1], changes[0]
+	}
+
+	regressed := changes[0]
+	if !regressed.Regressed || !regressed.Previous.IsValid || regressed.Current.IsValid || strings.Join(regressed.Current.ErrorCodes, ",") != "OG_IMAGE_MISSING,OG_TITLE_MISSING" {
+		t.Errorf("Expected a regression to two errors, got %+v", regressed)
+	}
+	if improved := changes[1]; improved.Regressed || strings.Join(improved.Current.ErrorCodes, ",") != "OG_TITLE_MISSING" {
+		t.Errorf("Expected an improvement to one error, got %+v", improved)
+	}
+
+	if deliveries, _ := webhooks.Deliveries(webhook.ID); len(deliveries) != 2 {
+		t.Errorf("Expected 2 logged deliveries, got %d", len(deliveries))
+	}
+}
+
+func TestWebhookManager_MonitorRegressed(t *testing.T) {
+	webhooks, receiver := newWebhookReceiver(t)
+	mustRegister(t, webhooks, models.WebhookRequest{
+		URL:    "http://hooks.example.test/",
+		Events: []string{models.WebhookEventMonitorRegressed},
+		URLs:   []string{"https://site.example.test/blog/"},
+	})
+
+	regression := models.Regression{Type: models.RegressionScoreDropped, Message: "score dropped from 80 to 60"}
+	webhooks.MonitorRegressed(models.MonitorAlert{MonitorID: "monitor-1", URL: "https://site.example.test/blog/post", Current: models.MonitorCheck{Regressions: []models.Regression{regression}}})
+	webhooks.MonitorRegressed(models.MonitorAlert{MonitorID: "monitor-2", URL: "https://site.example.test/about"})
+	webhooks.Shutdown(context.Background())
+
+	if len(receiver.bodies) != 1 {
+		t.Fatalf("Expected only the watched page's alert, got %d deliveries", len(receiver.bodies))
+	}
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(receiver.bodies[0], &payload); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+	if payload.Event != models.WebhookEventMonitorRegressed || payload.Alert == nil || payload.Alert.MonitorID != "monitor-1" || len(payload.Alert.Current.Regressions) != 1 {
+		t.Errorf("Expected the alert of monitor-1, got %+v", payload)
+	}
+}
+
+func TestWebhookManager_Test(t *testing.T) {
+	webhooks, receiver := newWebhookReceiver(t, http.StatusOK, http.StatusNotFound)
+	webhook := mustRegister(t, webhooks, models.WebhookRequest{URL: "http://hooks.example.test/", Events: []string{models.WebhookEventJobCompleted}})
+
+	delivery, err := webhooks.Test(context.Background(), webhook.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if delivery.Event != models.WebhookEventTest || delivery.Status != models.DeliveryStatusSucceeded || delivery.Attempts != 1 {
+		t.Errorf("Expected a successful test delivery, got %+v", delivery)
+	}
+	if event := receiver.requests[0].Header.Get("X-Webhook-Event"); event != models.WebhookEventTest {
+		t.Errorf("Expected X-Webhook-Event %s, got %s", models.WebhookEventTest, event)
+	}
+
+	// A failed test is not retried
+	delivery, _ = webhooks.Test(context.Background(), webhook.ID)
+	if delivery.Status != models.DeliveryStatusFailed || delivery.StatusCode != http.StatusNotFound || len(receiver.requests) != 2 {
+		t.Errorf("Expected one failed attempt, got %+v after %d requests", delivery, len(receiver.requests))
+	}
+
+	if _, err := webhooks.Test(context.Background(), "unknown"); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Expected ErrWebhookNotFound, got %v", err)
+	}
+}
+
+func TestWebhookManager_Register(t *testing.T) {
+	webhooks := NewWebhookManager(NewOGPService())
+
+	tests := []struct {
+		name    string
+		req     models.WebhookRequest
+		wantErr string
+	}{
+		{"valid", models.WebhookRequest{URL: "https://hooks.example.com/ogp", Events: []string{models.WebhookEventJobCompleted, models.WebhookEventValidationChanged}}, ""},
+		{"relative URL", models.WebhookRequest{URL: "/hook", Events: []string{models.WebhookEventJobCompleted}}, "url must be an absolute http or https URL"},
+		{"private URL", models.WebhookRequest{URL: "http://192.168.1.1/hook", Events: []string{models.WebhookEventJobCompleted}}, "private IP addresses are not allowed"},
+		{"no events", models.WebhookRequest{URL: "https://hooks.example.com/"}, "events is required"},
+		{"unknown event", models.WebhookRequest{URL: "https://hooks.example.com/", Events: []string{"job.started"}}, `unknown event "job.started"`},
+		{"invalid URL filter", models.WebhookRequest{URL: "https://hooks.example.com/", Events: []string{models.WebhookEventValidationChanged}, URLs: []string{"/blog/"}}, "urls must be absolute http or https URLs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := webhooks.Register(tt.req)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	created := mustRegister(t, webhooks, models.WebhookRequest{URL: "https://hooks.example.com/second", Events: []string{models.WebhookEventJobCompleted}})
+	if len(created.Secret) != 32 || len(created.Token) != 32 {
+		t.Errorf("Expected a generated secret and token, got %+v", created)
+	}
+	if got, err := webhooks.Get(created.ID); err != nil || got.Secret != "" || got.Token != "" {
+		t.Errorf("Expected the webhook without its secret or token, got %+v (%v)", got, err)
+	}
+
+	if err := webhooks.Authorize(created.ID, created.Token); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, token := range []string{"", "wrong"} {
+		if err := webhooks.Authorize(created.ID, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for %q, got %v", token, err)
+		}
+	}
+
+	if err := webhooks.Delete(created.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := webhooks.Authorize(created.ID, created.Token); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Expected ErrWebhookNotFound after delete, got %v", err)
+	}
+}
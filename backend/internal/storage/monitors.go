@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+const monitorSchema = `
+CREATE TABLE IF NOT EXISTS monitors (
+	seq              INTEGER PRIMARY KEY AUTOINCREMENT,
+	id               TEXT    NOT NULL UNIQUE,
+	url              TEXT    NOT NULL,
+	interval_minutes INTEGER NOT NULL,
+	options          BLOB    NOT NULL,
+	token_hash       TEXT    NOT NULL,
+	created_at       INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS monitor_checks (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	monitor_id TEXT    NOT NULL,
+	checked_at INTEGER NOT NULL,
+	check_data BLOB    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS monitor_checks_monitor_id ON monitor_checks (monitor_id, seq);
+`
+
+// SQLiteMonitorStore is a services.MonitorStore backed by a SQLite
+// database file. Options and checks are stored as JSON; times are Unix
+// nanoseconds. Only the hash of a monitor's token is stored.
+type SQLiteMonitorStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteMonitorStore opens or creates the database at path.
+func OpenSQLiteMonitorStore(path string) (*SQLiteMonitorStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open monitor database: %w", err)
+	}
+	// SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(monitorSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create monitor tables: %w", err)
+	}
+	return &SQLiteMonitorStore{db: db}, nil
+}
+
+func (s *SQLiteMonitorStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteMonitorStore) Save(monitor models.Monitor) error {
+	options, err := json.Marshal(monitor.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode options: %w", err)
+	}
+
+	if _, err := s.db.Exec(
+		`INSERT INTO monitors (id, url, interval_minutes, options, token_hash, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		monitor.ID, monitor.URL, monitor.IntervalMinutes, options, monitor.TokenHash, monitor.CreatedAt.UnixNano(),
+	); err != nil {
+		return fmt.Errorf("failed to save monitor: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteMonitorStore) Delete(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM monitor_checks WHERE monitor_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete monitor checks: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM monitors WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteMonitorStore) AddCheck(id string, check models.MonitorCheck, keep int) error {
+	data, err := json.Marshal(check)
+	if err != nil {
+		return fmt.Errorf("failed to encode check: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save check: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO monitor_checks (monitor_id, checked_at, check_data) VALUES (?, ?, ?)`,
+		id, check.CheckedAt.UnixNano(), data,
+	); err != nil {
+		return fmt.Errorf("failed to save check: %w", err)
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM monitor_checks WHERE monitor_id = ? AND seq NOT IN (
+			SELECT seq FROM monitor_checks WHERE monitor_id = ? ORDER BY seq DESC LIMIT ?
+		)`,
+		id, id, keep,
+	); err != nil {
+		return fmt.Errorf("failed to trim checks: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save check: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteMonitorStore) List() ([]models.Monitor, error) {
+	rows, err := s.db.Query(`SELECT id, url, interval_minutes, options, token_hash, created_at FROM monitors ORDER BY seq`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list monitors: %w", err)
+	}
+	defer rows.Close()
+
+	monitors := []models.Monitor{}
+	for rows.Next() {
+		var monitor models.Monitor
+		var options []byte
+		var createdAt int64
+		if err := rows.Scan(&monitor.ID, &monitor.URL, &monitor.IntervalMinutes, &options, &monitor.TokenHash, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to read monitor: %w", err)
+		}
+		if err := json.Unmarshal(options, &monitor.Options); err != nil {
+			return nil, fmt.Errorf("failed to decode options: %w", err)
+		}
+		monitor.CreatedAt = time.Unix(0, createdAt)
+		monitors = append(monitors, monitor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list monitors: %w", err)
+	}
+	return monitors, nil
+}
+
+func (s *SQLiteMonitorStore) Checks(id string) ([]models.MonitorCheck, error) {
+	rows, err := s.db.Query(`SELECT check_data FROM monitor_checks WHERE monitor_id = ? ORDER BY seq`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checks: %w", err)
+	}
+	defer rows.Close()
+
+	checks := []models.MonitorCheck{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read check: %w", err)
+		}
+		var check models.MonitorCheck
+		if err := json.Unmarshal(data, &check); err != nil {
+			return nil, fmt.Errorf("failed to decode check: %w", err)
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list checks: %w", err)
+	}
+	return checks, nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+)
+
+func TestSQLiteMonitorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitors.db")
+	store, err := OpenSQLiteMonitorStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now := time.Now().Round(0)
+	options := models.VerifyOptions{Lang: "ja", MinScore: 80}
+	for _, monitor := range []models.Monitor{
+		{ID: "first", URL: "https://example.com/", IntervalMinutes: 60, Options: options, TokenHash: "hash", CreatedAt: now},
+		{ID: "deleted", URL: "https://example.com/old", IntervalMinutes: 5, CreatedAt: now},
+		{ID: "second", URL: "https://example.com/blog", IntervalMinutes: 30, CreatedAt: now},
+	} {
+		if err := store.Save(monitor); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := store.Save(models.Monitor{ID: "first"}); err == nil {
+		t.Error("Expected an error saving a duplicate ID")
+	}
+
+	for i := 0; i < 4; i++ {
+		check := models.MonitorCheck{CheckedAt: now.Add(time.Duration(i) * time.Minute), ValidationState: models.ValidationState{Score: 90 - i, ErrorCodes: []string{}}}
+		if err := store.AddCheck("first", check, 3); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := store.AddCheck("deleted", models.MonitorCheck{CheckedAt: now}, 3); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Delete("deleted"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Monitors survive reopening the database
+	store, err = OpenSQLiteMonitorStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	monitors, err := store.List()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(monitors) != 2 || monitors[0].ID != "first" || monitors[1].ID != "second" {
+		t.Fatalf("Expected the remaining monitors in registration order, got %+v", monitors)
+	}
+	if !reflect.DeepEqual(monitors[0].Options, options) || monitors[0].IntervalMinutes != 60 || monitors[0].TokenHash != "hash" || !monitors[0].CreatedAt.Equal(now) {
+		t.Errorf("Expected the stored monitor, got %+v", monitors[0])
+	}
+
+	checks, err := store.Checks("first")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(checks) != 3 || checks[0].Score != 89 || checks[2].Score != 87 || !checks[2].CheckedAt.Equal(now.Add(3*time.Minute)) {
+		t.Errorf("Expected the latest 3 checks, oldest first, got %+v", checks)
+	}
+	if checks, err := store.Checks("deleted"); err != nil || len(checks) != 0 {
+		t.Errorf("Expected the deleted monitor's checks to be gone, got %+v (%v)", checks, err)
+	}
+}
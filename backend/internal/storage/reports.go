@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/services"
+)
+
+const reportSchema = `
+CREATE TABLE IF NOT EXISTS reports (
+	id         TEXT    PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	expires_at INTEGER,
+	response   BLOB    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS reports_expires_at ON reports (expires_at);
+`
+
+// SQLiteReportStore is a services.ReportStore backed by a SQLite database
+// file. Responses are stored as JSON; times are Unix nanoseconds, and
+// expires_at is NULL for reports kept forever.
+type SQLiteReportStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteReportStore opens or creates the database at path.
+func OpenSQLiteReportStore(path string) (*SQLiteReportStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report database: %w", err)
+	}
+	// SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(reportSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create report table: %w", err)
+	}
+	return &SQLiteReportStore{db: db}, nil
+}
+
+func (s *SQLiteReportStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteReportStore) Save(report models.Report) error {
+	response, err := json.Marshal(report.Response)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	var expiresAt sql.NullInt64
+	if report.ExpiresAt != nil {
+		expiresAt = sql.NullInt64{Int64: report.ExpiresAt.UnixNano(), Valid: true}
+	}
+
+	if _, err := s.db.Exec(
+		`INSERT INTO reports (id, created_at, expires_at, response) VALUES (?, ?, ?, ?)`,
+		report.ID, report.CreatedAt.UnixNano(), expiresAt, response,
+	); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteReportStore) Get(id string) (models.Report, error) {
+	var createdAt int64
+	var expiresAt sql.NullInt64
+	var response []byte
+	err := s.db.QueryRow(`SELECT created_at, expires_at, response FROM reports WHERE id = ?`, id).Scan(&createdAt, &expiresAt, &response)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Report{}, services.ErrReportNotFound
+	}
+	if err != nil {
+		return models.Report{}, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	report := models.Report{ID: id, CreatedAt: time.Unix(0, createdAt)}
+	if expiresAt.Valid {
+		t := time.Unix(0, expiresAt.Int64)
+		report.ExpiresAt = &t
+	}
+	if err := json.Unmarshal(response, &report.Response); err != nil {
+		return models.Report{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return report, nil
+}
+
+func (s *SQLiteReportStore) DeleteExpired(t time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM reports WHERE expires_at < ?`, t.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete reports: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete reports: %w", err)
+	}
+	return int(deleted), nil
+}
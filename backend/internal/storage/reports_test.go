@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/services"
+)
+
+func TestSQLiteReportStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.db")
+	store, err := OpenSQLiteReportStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	expired, later := now.Add(-time.Minute), now.Add(time.Hour)
+	for _, report := range []models.Report{
+		{ID: "expired", CreatedAt: now, ExpiresAt: &expired},
+		{ID: "later", CreatedAt: now, ExpiresAt: &later},
+		{ID: "forever", CreatedAt: now},
+	} {
+		report.Response = &models.OGPResponse{URL: "https://example.com/", OGPData: models.OGPData{Title: report.ID}}
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := store.Save(models.Report{ID: "later", Response: &models.OGPResponse{}}); err == nil {
+		t.Error("Expected an error saving a duplicate ID")
+	}
+
+	if deleted, err := store.DeleteExpired(now); err != nil || deleted != 1 {
+		t.Errorf("Expected one expired report deleted, got %d (%v)", deleted, err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Reports survive reopening the database
+	store, err = OpenSQLiteReportStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	report, err := store.Get("later")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Response == nil || report.Response.OGPData.Title != "later" || !report.CreatedAt.Equal(now) || !report.ExpiresAt.Equal(later) {
+		t.Errorf("Expected the stored report, got %+v", report)
+	}
+	if report, err := store.Get("forever"); err != nil || report.ExpiresAt != nil {
+		t.Errorf("Expected a report without expiry, got %+v (%v)", report, err)
+	}
+	if _, err := store.Get("expired"); !errors.Is(err, services.ErrReportNotFound) {
+		t.Errorf("Expected ErrReportNotFound for a deleted report, got %v", err)
+	}
+}
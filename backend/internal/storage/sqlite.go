@@ -0,0 +1,158 @@
+// Package storage keeps service data in SQLite so that it survives
+// restarts.
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/services"
+)
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	url         TEXT    NOT NULL,
+	created_at  INTEGER NOT NULL,
+	is_valid    INTEGER NOT NULL,
+	score       INTEGER NOT NULL,
+	error_codes TEXT    NOT NULL,
+	response    BLOB    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_url ON history (url, created_at);
+CREATE INDEX IF NOT EXISTS history_created_at ON history (created_at);
+`
+
+// SQLiteHistoryStore is a services.HistoryStore backed by a SQLite
+// database file. Responses are stored as JSON; created_at is Unix
+// nanoseconds.
+type SQLiteHistoryStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteHistoryStore opens or creates the database at path.
+func OpenSQLiteHistoryStore(path string) (*SQLiteHistoryStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	// SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history table: %w", err)
+	}
+	return &SQLiteHistoryStore{db: db}, nil
+}
+
+func (s *SQLiteHistoryStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteHistoryStore) Save(entry models.HistoryEntry) (models.HistoryEntry, error) {
+	response, err := json.Marshal(entry.Response)
+	if err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to encode response: %w", err)
+	}
+	errorCodes, err := json.Marshal(entry.ErrorCodes)
+	if err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to encode error codes: %w", err)
+	}
+
+	result, err := s.db.Exec(
+		`INSERT INTO history (url, created_at, is_valid, score, error_codes, response) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.URL, entry.CreatedAt.UnixNano(), entry.IsValid, entry.Score, string(errorCodes), response,
+	)
+	if err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to save history: %w", err)
+	}
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to save history: %w", err)
+	}
+	return entry, nil
+}
+
+func (s *SQLiteHistoryStore) List(url string, limit int) ([]models.HistoryEntry, error) {
+	rows, err := s.db.Query(
+		`SELECT id, url, created_at, is_valid, score, error_codes FROM history WHERE url = ? ORDER BY created_at DESC, id DESC LIMIT ?`,
+		url, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.HistoryEntry{}
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows, nil)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *SQLiteHistoryStore) Get(id int64) (models.HistoryEntry, error) {
+	row := s.db.QueryRow(`SELECT id, url, created_at, is_valid, score, error_codes, response FROM history WHERE id = ?`, id)
+
+	var response []byte
+	entry, err := scanHistoryEntry(row, &response)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.HistoryEntry{}, services.ErrHistoryNotFound
+	}
+	if err != nil {
+		return models.HistoryEntry{}, err
+	}
+	if err := json.Unmarshal(response, &entry.Response); err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return entry, nil
+}
+
+func (s *SQLiteHistoryStore) DeleteBefore(t time.Time) (int, error) {
+	result, err := s.db.Exec(`DELETE FROM history WHERE created_at < ?`, t.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete history: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete history: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// rowScanner is a *sql.Row or *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanHistoryEntry reads the summary columns of a history row, followed by
+// the response column when response is not nil.
+func scanHistoryEntry(row rowScanner, response *[]byte) (models.HistoryEntry, error) {
+	var entry models.HistoryEntry
+	var createdAt int64
+	var errorCodes string
+	dest := []interface{}{&entry.ID, &entry.URL, &createdAt, &entry.IsValid, &entry.Score, &errorCodes}
+	if response != nil {
+		dest = append(dest, response)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to read history: %w", err)
+	}
+	entry.CreatedAt = time.Unix(0, createdAt)
+	if err := json.Unmarshal([]byte(errorCodes), &entry.ErrorCodes); err != nil {
+		return models.HistoryEntry{}, fmt.Errorf("failed to decode error codes: %w", err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ogp-verification-service/internal/models"
+	"ogp-verification-service/internal/services"
+)
+
+func TestSQLiteHistoryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := OpenSQLiteHistoryStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i, entry := range []models.HistoryEntry{
+		{URL: "https://example.com/", CreatedAt: start, ValidationState: models.ValidationState{IsValid: true, Score: 90, ErrorCodes: []string{}}},
+		{URL: "https://example.com/about", CreatedAt: start.Add(time.Minute), ValidationState: models.ValidationState{Score: 50, ErrorCodes: []string{"OG_IMAGE_INVALID"}}},
+		{URL: "https://example.com/", CreatedAt: start.Add(2 * time.Minute), ValidationState: models.ValidationState{Score: 40, ErrorCodes: []string{"OG_IMAGE_INVALID"}}},
+	} {
+		entry.Response = &models.OGPResponse{URL: entry.URL, OGPData: models.OGPData{Title: "Entry"}}
+		saved, err := store.Save(entry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if saved.ID != int64(i+1) {
+			t.Errorf("Expected ID %d, got %d", i+1, saved.ID)
+		}
+	}
+
+	entries, err := store.List("https://example.com/", 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != 3 || entries[1].ID != 1 {
+		t.Fatalf("Expected the page's entries newest first, got %+v", entries)
+	}
+	if entries[0].Score != 40 || entries[0].IsValid || entries[0].ErrorCodes[0] != "OG_IMAGE_INVALID" || entries[0].Response != nil {
+		t.Errorf("Expected the summary without the response, got %+v", entries[0])
+	}
+	if !entries[1].CreatedAt.Equal(start) {
+		t.Errorf("Expected created_at %v, got %v", start, entries[1].CreatedAt)
+	}
+	if entries, _ := store.List("https://example.com/", 1); len(entries) != 1 || entries[0].ID != 3 {
+		t.Errorf("Expected the limit to keep the newest entry, got %+v", entries)
+	}
+
+	if deleted, err := store.DeleteBefore(start.Add(30 * time.Second)); err != nil || deleted != 1 {
+		t.Errorf("Expected one expired entry deleted, got %d (%v)", deleted, err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Entries survive reopening the database
+	store, err = OpenSQLiteHistoryStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	entry, err := store.Get(3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry.Response == nil || entry.Response.OGPData.Title != "Entry" {
+		t.Errorf("Expected the stored response, got %+v", entry.Response)
+	}
+	if _, err := store.Get(1); !errors.Is(err, services.ErrHistoryNotFound) {
+		t.Errorf("Expected ErrHistoryNotFound for a deleted entry, got %v", err)
+	}
+}
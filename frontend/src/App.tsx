@@ -1,10 +1,11 @@
 import { URLInput } from '@/components/URLInput';
 import { OGPResult } from '@/components/OGPResult';
 import { ErrorMessage } from '@/components/ErrorMessage';
+import { VerifyProgress } from '@/components/VerifyProgress';
 import { useOGP } from '@/hooks/useOGP';
 
 function App() {
-  const { data, loading, error, verifyOGP, reset } = useOGP();
+  const { data, progress, loading, error, verifyOGP, reset } = useOGP({ stream: true });
 
   const handleSubmit = async (url: string) => {
     await verifyOGP({ url });
@@ -32,12 +33,7 @@ function App() {
             />
           )}
 
-          {loading && (
-            <div className="text-center py-8">
-              <div className="inline-block animate-spin rounded-full h-8 w-8 border-b-2 border-primary-600"></div>
-              <p className="mt-2 text-gray-600">Analyzing OGP data...</p>
-            </div>
-          )}
+          {loading && <VerifyProgress progress={progress} />}
 
           {data && !loading && !error && (
             <OGPResult data={data} />
@@ -0,0 +1,56 @@
+import type { VerifyProgress as Progress } from '@/hooks/useOGP';
+import type { VerifyStage } from '@/types/ogp';
+
+interface VerifyProgressProps {
+  progress: Progress | null;
+}
+
+const STEPS: { stage: VerifyStage; label: string }[] = [
+  { stage: 'fetched', label: 'Fetching page' },
+  { stage: 'parsed', label: 'Reading OGP tags' },
+  { stage: 'image_probed', label: 'Checking image' },
+  { stage: 'validated', label: 'Validating' },
+  { stage: 'icons', label: 'Checking icons' },
+  { stage: 'preview', label: 'Building previews' },
+];
+
+const PLATFORM_COUNT = 10;
+
+export const VerifyProgress: React.FC<VerifyProgressProps> = ({ progress }) => {
+  const stages = progress?.stages ?? [];
+  const previews = Object.values(progress?.previews ?? {});
+  const issues = progress?.validation?.issues.length;
+
+  return (
+    <div className="w-full max-w-2xl mx-auto bg-white rounded-lg shadow-md p-6">
+      <div className="flex items-center mb-4">
+        <div className="inline-block animate-spin rounded-full h-5 w-5 border-b-2 border-primary-600"></div>
+        <p className="ml-3 text-gray-600">Analyzing OGP data...</p>
+      </div>
+
+      <ul className="space-y-1 text-sm">
+        {STEPS.map(({ stage, label }) => (
+          <li key={stage} className={stages.includes(stage) ? 'text-green-700' : 'text-gray-400'}>
+            {stages.includes(stage) ? '✓' : '○'} {label}
+            {stage === 'preview' && previews.length > 0 && ` (${previews.length}/${PLATFORM_COUNT})`}
+          </li>
+        ))}
+      </ul>
+
+      {progress?.ogp_data && (
+        <div className="mt-4 border-t pt-4 text-sm">
+          <p className="font-medium text-gray-800">{progress.ogp_data.title || 'No og:title'}</p>
+          <p className="text-gray-600">{progress.ogp_data.description || 'No og:description'}</p>
+          {progress.image_info?.fetched && (
+            <p className="text-gray-500 mt-1">
+              Image {progress.image_info.width}x{progress.image_info.height} {progress.image_info.format}
+            </p>
+          )}
+          {issues !== undefined && (
+            <p className="text-gray-500 mt-1">{issues} issue{issues === 1 ? '' : 's'} found</p>
+          )}
+        </div>
+      )}
+    </div>
+  );
+};
@@ -145,6 +145,34 @@ describe('useOGP', () => {
     expect(result.current.loading).toBe(true);
   });
 
+  it('should collect progress from streamed events', async () => {
+    const mockResponse = { url: 'https://example.com' } as OGPResponse;
+    const mockVerifyOGPStream = jest.fn().mockImplementation(async (_request, onEvent) => {
+      onEvent({ stage: 'fetched', url: 'https://example.com/' });
+      onEvent({ stage: 'parsed', ogp_data: { title: 'Streamed' } });
+      onEvent({ stage: 'preview', preview: { platform: 'twitter', title: 'Streamed' } });
+      onEvent({ stage: 'preview', preview: { platform: 'discord', title: 'Streamed' } });
+      onEvent({ stage: 'done', response: mockResponse });
+      return mockResponse;
+    });
+    mockOGPService.getInstance.mockReturnValue({
+      verifyOGP: jest.fn(),
+      verifyOGPStream: mockVerifyOGPStream,
+      healthCheck: jest.fn(),
+    } as any);
+
+    const { result } = renderHook(() => useOGP({ stream: true }));
+
+    await act(async () => {
+      await result.current.verifyOGP({ url: 'https://example.com' });
+    });
+
+    expect(result.current.data).toEqual(mockResponse);
+    expect(result.current.progress?.stages).toEqual(['fetched', 'parsed', 'preview', 'done']);
+    expect(result.current.progress?.ogp_data?.title).toBe('Streamed');
+    expect(Object.keys(result.current.progress?.previews ?? {})).toEqual(['twitter', 'discord']);
+  });
+
   it('should reset state', () => {
     const { result } = renderHook(() => useOGP());
 
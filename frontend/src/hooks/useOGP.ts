@@ -1,17 +1,65 @@
 import { useState, useCallback } from 'react';
 import { OGPService } from '@/services/ogp';
-import type { OGPRequest, OGPResponse } from '@/types/ogp';
+import type {
+  ImageMetadata,
+  OGPData,
+  OGPRequest,
+  OGPResponse,
+  PlatformPreviews,
+  ValidationResult,
+  VerifyEvent,
+  VerifyStage,
+} from '@/types/ogp';
+
+// VerifyProgress is what a streamed verification has produced so far.
+export interface VerifyProgress {
+  stages: VerifyStage[];
+  ogp_data?: OGPData;
+  image_info?: ImageMetadata;
+  validation?: ValidationResult;
+  previews: Partial<PlatformPreviews>;
+}
+
+export interface UseOGPOptions {
+  // stream verifies through the streaming endpoint and fills progress as
+  // each stage completes.
+  stream?: boolean;
+}
 
 export interface UseOGPReturn {
   data: OGPResponse | null;
+  progress: VerifyProgress | null;
   loading: boolean;
   error: string | null;
   verifyOGP: (request: OGPRequest) => Promise<void>;
   reset: () => void;
 }
 
-export const useOGP = (): UseOGPReturn => {
+const applyEvent = (progress: VerifyProgress | null, event: VerifyEvent): VerifyProgress => {
+  const next: VerifyProgress = {
+    ...(progress ?? { stages: [], previews: {} }),
+  };
+  if (!next.stages.includes(event.stage)) {
+    next.stages = [...next.stages, event.stage];
+  }
+  if (event.ogp_data) {
+    next.ogp_data = event.ogp_data;
+  }
+  if (event.image_info) {
+    next.image_info = event.image_info;
+  }
+  if (event.validation) {
+    next.validation = event.validation;
+  }
+  if (event.preview) {
+    next.previews = { ...next.previews, [event.preview.platform as keyof PlatformPreviews]: event.preview };
+  }
+  return next;
+};
+
+export const useOGP = ({ stream = false }: UseOGPOptions = {}): UseOGPReturn => {
   const [data, setData] = useState<OGPResponse | null>(null);
+  const [progress, setProgress] = useState<VerifyProgress | null>(null);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
 
@@ -20,28 +68,33 @@ export const useOGP = (): UseOGPReturn => {
   const verifyOGP = useCallback(async (request: OGPRequest) => {
     setLoading(true);
     setError(null);
+    setProgress(null);
 
     try {
-      const response = await ogpService.verifyOGP(request);
+      const response = stream
+        ? await ogpService.verifyOGPStream(request, (event) => setProgress((current) => applyEvent(current, event)))
+        : await ogpService.verifyOGP(request);
       setData(response);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'An unknown error occurred');
     } finally {
       setLoading(false);
     }
-  }, [ogpService]);
+  }, [ogpService, stream]);
 
   const reset = useCallback(() => {
     setData(null);
+    setProgress(null);
     setError(null);
     setLoading(false);
   }, []);
 
   return {
     data,
+    progress,
     loading,
     error,
     verifyOGP,
     reset,
   };
-};
\ No newline at end of file
+};
@@ -1,4 +1,4 @@
-import type { OGPRequest, OGPResponse } from '@/types/ogp';
+import type { OGPRequest, OGPResponse, VerifyEvent } from '@/types/ogp';
 
 const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080';
 
@@ -31,6 +31,65 @@ export class OGPService {
     return response.json();
   }
 
+  // verifyOGPStream calls onEvent as each stage of the verification completes
+  // and resolves with the response from the final done event.
+  public async verifyOGPStream(request: OGPRequest, onEvent: (event: VerifyEvent) => void): Promise<OGPResponse> {
+    const response = await fetch(`${API_BASE_URL}/api/v1/ogp/verify/stream`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        'Accept': 'text/event-stream',
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response.ok) {
+      const errorText = await response.text();
+      throw new Error(`HTTP ${response.status}: ${errorText}`);
+    }
+    if (!response.body) {
+      throw new Error('Streaming responses are not supported');
+    }
+
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+
+    for (;;) {
+      const { done, value } = await reader.read();
+      buffer += decoder.decode(value, { stream: !done });
+
+      // Events are separated by a blank line; only data lines carry JSON
+      let boundary = buffer.indexOf('\n\n');
+      while (boundary !== -1) {
+        const data = buffer
+          .slice(0, boundary)
+          .split('\n')
+          .filter((line) => line.startsWith('data:'))
+          .map((line) => line.slice(5).trimStart())
+          .join('\n');
+        buffer = buffer.slice(boundary + 2);
+        boundary = buffer.indexOf('\n\n');
+
+        if (!data) {
+          continue;
+        }
+        const event: VerifyEvent = JSON.parse(data);
+        onEvent(event);
+        if (event.stage === 'error') {
+          throw new Error(event.error || 'Verification failed');
+        }
+        if (event.stage === 'done' && event.response) {
+          return event.response;
+        }
+      }
+
+      if (done) {
+        throw new Error('The stream ended before the verification finished');
+      }
+    }
+  }
+
   public async healthCheck(): Promise<{ status: string; timestamp: string }> {
     const response = await fetch(`${API_BASE_URL}/health`);
     
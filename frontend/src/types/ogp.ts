@@ -1,5 +1,248 @@
 export interface OGPRequest {
   url: string;
+  truncation_mode?: 'chars' | 'width';
+  rules?: RuleConfig;
+  min_score?: number;
+  lang?: 'en' | 'ja';
+  check_alternates?: boolean;
+}
+
+export interface BatchRequest extends Omit<OGPRequest, 'url' | 'check_alternates'> {
+  urls: string[];
+}
+
+export interface BatchResponse {
+  results: BatchResult[];
+  summary: BatchSummary;
+  timestamp: string;
+}
+
+export interface BatchResult {
+  url: string;
+  response?: OGPResponse;
+  error?: string;
+}
+
+export interface BatchSummary {
+  total: number;
+  succeeded: number;
+  failed: number;
+  valid: number;
+  passed: number;
+  average_score: number;
+  issue_counts: Record<string, number>;
+}
+
+export interface CrawlRequest extends Omit<OGPRequest, 'url' | 'check_alternates'> {
+  sitemap_url?: string;
+  start_url?: string;
+  max_depth?: number;
+  max_pages?: number;
+}
+
+export interface CrawlReport {
+  source: 'sitemap' | 'links';
+  pages: CrawlPage[];
+  summary: BatchSummary;
+  worst_pages: CrawlPage[];
+  duplicate_titles: DuplicateValue[];
+  duplicate_images: DuplicateValue[];
+  truncated: boolean;
+  errors: string[];
+  timestamp: string;
+}
+
+export interface CrawlPage {
+  url: string;
+  depth: number;
+  title?: string;
+  image?: string;
+  score: number;
+  is_valid: boolean;
+  issue_codes: string[];
+  error?: string;
+}
+
+export interface DuplicateValue {
+  value: string;
+  urls: string[];
+}
+
+export type JobType = 'batch' | 'crawl';
+
+export type JobStatus = 'queued' | 'running' | 'succeeded' | 'failed' | 'cancelled';
+
+export interface JobRequest {
+  type: JobType;
+  batch?: BatchRequest;
+  crawl?: CrawlRequest;
+}
+
+export interface Job {
+  id: string;
+  type: JobType;
+  status: JobStatus;
+  progress: JobProgress;
+  results?: BatchResult[];
+  batch?: BatchResponse;
+  crawl?: CrawlReport;
+  error?: string;
+  created_at: string;
+  started_at?: string;
+  finished_at?: string;
+}
+
+export interface JobProgress {
+  completed: number;
+  total: number;
+}
+
+export type WebhookEvent = 'job.completed' | 'validation.changed' | 'monitor.regressed' | 'webhook.test';
+
+export interface WebhookRequest {
+  url: string;
+  events: Exclude<WebhookEvent, 'webhook.test'>[];
+  urls?: string[];
+  secret?: string;
+}
+
+export interface Webhook {
+  id: string;
+  url: string;
+  events: WebhookEvent[];
+  urls?: string[];
+  secret?: string;
+  token?: string;
+  created_at: string;
+}
+
+export interface WebhookPayload {
+  id: string;
+  event: WebhookEvent;
+  timestamp: string;
+  job?: Job;
+  summary?: BatchSummary;
+  change?: ValidationChange;
+  alert?: MonitorAlert;
+}
+
+export interface ValidationChange {
+  url: string;
+  previous: ValidationState;
+  current: ValidationState;
+  regressed: boolean;
+}
+
+export interface ValidationState {
+  is_valid: boolean;
+  score: number;
+  error_codes: string[];
+}
+
+export interface WebhookDelivery {
+  id: string;
+  webhook_id: string;
+  event: WebhookEvent;
+  status: 'pending' | 'succeeded' | 'failed';
+  attempts: number;
+  status_code?: number;
+  error?: string;
+  created_at: string;
+  last_attempt_at?: string;
+  next_attempt_at?: string;
+}
+
+export interface MonitorRequest extends Omit<OGPRequest, 'url' | 'check_alternates'> {
+  url: string;
+  interval_minutes?: number;
+}
+
+export interface Monitor {
+  id: string;
+  url: string;
+  interval_minutes: number;
+  options: Omit<OGPRequest, 'url'>;
+  token?: string;
+  created_at: string;
+  last_check?: MonitorCheck;
+  next_check_at?: string;
+}
+
+export interface MonitorCheck extends ValidationState {
+  checked_at: string;
+  image?: string;
+  image_ok: boolean;
+  error?: string;
+  regressions?: Regression[];
+}
+
+export type RegressionType = 'fetch_failed' | 'score_dropped' | 'errors_added' | 'image_changed' | 'image_broken';
+
+export interface Regression {
+  type: RegressionType;
+  message: string;
+}
+
+export interface MonitorAlert {
+  monitor_id: string;
+  url: string;
+  previous: MonitorCheck;
+  current: MonitorCheck;
+}
+
+export interface HistoryEntry extends ValidationState {
+  id: number;
+  url: string;
+  created_at: string;
+  response?: OGPResponse;
+}
+
+export interface DiffRequest extends Omit<OGPRequest, 'url' | 'check_alternates'> {
+  before: DiffTarget;
+  after: DiffTarget;
+}
+
+// Exactly one of history_id and url is set.
+export interface DiffTarget {
+  history_id?: number;
+  url?: string;
+}
+
+export interface VerificationDiff {
+  before: DiffSide;
+  after: DiffSide;
+  score_delta: number;
+  changes: FieldChange[];
+  issues_added: ValidationIssue[];
+  issues_removed: ValidationIssue[];
+}
+
+export interface DiffSide {
+  url: string;
+  history_id?: number;
+  verified_at: string;
+  is_valid: boolean;
+  score: number;
+  permalink?: string;
+}
+
+export interface FieldChange {
+  field: string;
+  before: unknown;
+  after: unknown;
+}
+
+export interface Report {
+  id: string;
+  created_at: string;
+  expires_at?: string;
+  response: OGPResponse;
+}
+
+export interface VerifyHTMLRequest extends Omit<OGPRequest, 'url'> {
+  html: string;
+  base_url?: string;
+  probe_images?: boolean;
 }
 
 export interface OGPResponse {
@@ -7,7 +250,94 @@ export interface OGPResponse {
   ogp_data: OGPData;
   validation: ValidationResult;
   previews: PlatformPreviews;
+  image_info: ImageMetadata;
+  seo: SEOResult;
+  score: QualityScore;
+  lang: string;
+  alternates?: AlternateLocale[];
+  icons: SiteIcons;
   timestamp: string;
+  permalink?: string;
+}
+
+export type VerifyStage =
+  | 'fetched'
+  | 'parsed'
+  | 'image_probed'
+  | 'validated'
+  | 'icons'
+  | 'preview'
+  | 'done'
+  | 'error';
+
+// One Server-Sent Event from /api/v1/ogp/verify/stream. Only the field
+// belonging to the stage is set.
+export interface VerifyEvent {
+  stage: VerifyStage;
+  url?: string;
+  ogp_data?: OGPData;
+  image_info?: ImageMetadata;
+  validation?: ValidationResult;
+  seo?: SEOResult;
+  icons?: SiteIcons;
+  preview?: PlatformPreview;
+  response?: OGPResponse;
+  error?: string;
+}
+
+export interface SiteIcons {
+  icons: IconMetadata[];
+  favicon: string;
+  apple_touch_icon: string;
+  manifest?: {
+    url: string;
+    fetched: boolean;
+    name?: string;
+    short_name?: string;
+    theme_color?: string;
+    error?: string;
+  };
+  theme_color: string;
+  issues: ValidationIssue[];
+}
+
+export interface IconMetadata {
+  url: string;
+  rel: 'icon' | 'apple-touch-icon';
+  source: 'link' | 'manifest' | 'default';
+  sizes?: string;
+  type?: string;
+  fetched: boolean;
+  format?: string;
+  width: number;
+  height: number;
+  size: number;
+  error?: string;
+}
+
+export interface AlternateLocale {
+  fb_app_id: string;
+  locale: string;
+  url?: string;
+  fetched: boolean;
+  ogp_locale?: string;
+  locale_matches: boolean;
+  title?: string;
+  is_valid: boolean;
+  score: number;
+  issues?: ValidationIssue[];
+  error?: string;
+}
+
+export interface QualityScore {
+  score: number;
+  categories: {
+    name: 'completeness' | 'image_quality' | 'text_length' | 'technical';
+    score: number;
+    weight: number;
+  }[];
+  threshold?: number;
+  passed: boolean;
 }
 
 export interface OGPData {
@@ -20,13 +350,71 @@ export interface OGPData {
   image_width: string;
   image_height: string;
   image_alt: string;
+  twitter_image_alt: string;
+  locale: string;
+  locale_alternates?: string[];
+  fediverse_creator: string;
+  telegram_channel: string;
+  apple_touch_icon: string;
+  twitter_card: string;
+  author: string;
+}
+
+export interface ImageMetadata {
+  url: string;
+  fetched: boolean;
+  content_type: string;
+  format: string;
+  size: number;
+  width: number;
+  height: number;
+  error?: string;
+  analysis?: ImageAnalysis;
+}
+
+export interface ImageAnalysis {
+  contrast: number;
+  edge_density: number;
+  likely_text: boolean;
 }
 
 export interface ValidationResult {
   is_valid: boolean;
+  issues: ValidationIssue[];
+  conflicts: TagConflict[];
   warnings: string[];
   errors: string[];
   checks: ValidationChecks;
+  accessibility: AccessibilityResult;
+}
+
+export interface AccessibilityResult {
+  issues: ValidationIssue[];
+  has_image_alt: boolean;
+  image_alt_length: number;
+}
+
+export interface RuleConfig {
+  enabled?: string[];
+  disabled?: string[];
+  severity?: Record<string, 'error' | 'warning' | 'info'>;
+}
+
+export interface TagConflict {
+  property: string;
+  values: string[];
+}
+
+export interface ValidationIssue {
+  code: string;
+  severity: 'error' | 'warning' | 'info';
+  message: string;
+  property?: string;
+  value?: string;
+  params?: string[];
+  line?: number;
+  column?: number;
+  docs_url?: string;
 }
 
 export interface ValidationChecks {
@@ -41,6 +429,13 @@ export interface PlatformPreviews {
   twitter: PlatformPreview;
   facebook: PlatformPreview;
   discord: PlatformPreview;
+  mastodon: PlatformPreview;
+  misskey: PlatformPreview;
+  bluesky: PlatformPreview;
+  telegram: PlatformPreview;
+  whatsapp: PlatformPreview;
+  imessage: PlatformPreview;
+  search: PlatformPreview;
 }
 
 export interface PlatformPreview {
@@ -54,4 +449,39 @@ export interface PlatformPreview {
   desc_length: number;
   max_title_len: number;
   max_desc_len: number;
+  author?: string;
+  image_source?: string;
+  hints?: string[];
+  display_url?: string;
+  icon?: string;
+  theme_color?: string;
+  layout?: 'large_image' | 'thumbnail' | 'none';
+  site_name?: string;
+  debugger_warnings?: string[];
+  issues?: ValidationIssue[];
+  title_pixel_width?: number;
+  max_title_pixel_width?: number;
+  desc_pixel_width?: number;
+  max_desc_pixel_width?: number;
+  title_within_char_limit: boolean;
+  desc_within_char_limit: boolean;
+  title_fits_width: boolean;
+  desc_fits_width: boolean;
+  title_truncate_at?: number;
+  desc_truncate_at?: number;
+  display_title: string;
+  display_description: string;
+}
+
+export interface SEOResult {
+  html_lang: string;
+  title: string;
+  description: string;
+  canonical: string;
+  robots: string;
+  noindex: boolean;
+  hreflangs: { lang: string; url: string }[];
+  issues: ValidationIssue[];
+  warnings: string[];
+  errors: string[];
 }
\ No newline at end of file